@@ -0,0 +1,217 @@
+// Command assetgen batch-generates images from a prompts file and writes them to disk.
+//
+// Usage:
+//
+//	THETA_API_KEY=... go run ./cmd/assetgen -prompts icons.txt -out ./assets -style "flat icon" -concurrency 4
+//
+// The prompts file is either one prompt per line (blank lines and # comments are skipped)
+// or a JSON array of strings / objects: [{"name":"sword","prompt":"...","style":"...","width":256,"height":256}].
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emergent-world-engine/backend/pkg/framework"
+	"github.com/emergent-world-engine/backend/pkg/textutil"
+)
+
+// promptSpec is a single asset to generate; zero fields fall back to the command-line flags
+type promptSpec struct {
+	Name   string `json:"name,omitempty"`
+	Prompt string `json:"prompt"`
+	Style  string `json:"style,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+func main() {
+	promptsPath := flag.String("prompts", "", "prompts file (one per line, or JSON array)")
+	outDir := flag.String("out", "assets_out", "output directory")
+	model := flag.String("model", framework.ModelImageDefault, "image model")
+	width := flag.Int("width", 512, "default image width")
+	height := flag.Int("height", 512, "default image height")
+	style := flag.String("style", "", "default art style")
+	format := flag.String("format", "png", "output image format")
+	quality := flag.String("quality", "standard", "generation quality: draft, standard, high")
+	concurrency := flag.Int("concurrency", 4, "number of assets generated in parallel")
+	timeout := flag.Duration("timeout", 2*time.Minute, "per-asset timeout")
+	dryRun := flag.Bool("dry-run", false, "print what would be generated without calling the API")
+	flag.Parse()
+
+	if *promptsPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	specs, err := loadPrompts(*promptsPath)
+	if err != nil {
+		log.Fatalf("Failed to load prompts: %v", err)
+	}
+	if len(specs) == 0 {
+		log.Fatalf("No prompts found in %s", *promptsPath)
+	}
+	applyDefaults(specs, *style, *width, *height)
+
+	if *dryRun {
+		for i, s := range specs {
+			fmt.Printf("[%d/%d] %dx%d style=%q model=%s format=%s -> %s\n  %s\n",
+				i+1, len(specs), s.Width, s.Height, s.Style, *model, *format, outputName(s, *format), s.Prompt)
+		}
+		return
+	}
+
+	engine, err := framework.NewEngine(&framework.Config{
+		ThetaAPIKey:   os.Getenv("THETA_API_KEY"),
+		ThetaEndpoint: os.Getenv("THETA_BASE_URL"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize engine: %v", err)
+	}
+	defer engine.Close()
+
+	assetGen := engine.NewAssetGenerator(
+		framework.WithImageModel(*model),
+		framework.WithQuality(*quality),
+		framework.WithOutputFormat(*format),
+	)
+
+	if *concurrency < 1 {
+		*concurrency = 1
+	}
+	sem := make(chan struct{}, *concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := 0
+	for i, s := range specs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, s promptSpec) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			path, err := generateOne(assetGen, s, *outDir, *format, *timeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				log.Printf("[%d/%d] ❌ %s: %v", i+1, len(specs), textutil.Snippet(s.Prompt, 60), err)
+				return
+			}
+			log.Printf("[%d/%d] ✅ %s", i+1, len(specs), path)
+		}(i, s)
+	}
+	wg.Wait()
+
+	log.Printf("Generated %d/%d assets in %s", len(specs)-failed, len(specs), *outDir)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func generateOne(assetGen *framework.AssetGenerator, s promptSpec, outDir, format string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	asset, err := assetGen.GenerateImage(ctx, &framework.ImageRequest{
+		Prompt: s.Prompt,
+		Style:  s.Style,
+		Width:  s.Width,
+		Height: s.Height,
+	})
+	if err != nil {
+		return "", err
+	}
+	path, err := asset.SaveToDir(ctx, outDir)
+	if err != nil {
+		return "", err
+	}
+	if s.Name == "" {
+		return path, nil
+	}
+	named := filepath.Join(outDir, outputName(s, format))
+	if err := os.Rename(path, named); err != nil {
+		return "", fmt.Errorf("failed to rename %s: %w", path, err)
+	}
+	return named, nil
+}
+
+// loadPrompts reads a JSON array (of strings or promptSpec objects) or a plain one-prompt-per-line file
+func loadPrompts(path string) ([]promptSpec, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(raw)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("invalid JSON prompts: %w", err)
+		}
+		specs := make([]promptSpec, 0, len(items))
+		seen := make(map[string]int)
+		for i, item := range items {
+			var s promptSpec
+			var text string
+			if json.Unmarshal(item, &text) == nil {
+				s.Prompt = text
+			} else if err := json.Unmarshal(item, &s); err != nil {
+				return nil, fmt.Errorf("invalid prompt at index %d: %w", i, err)
+			}
+			if strings.TrimSpace(s.Prompt) == "" {
+				continue
+			}
+			if s.Name != "" {
+				// Named assets are written to <name>.<format>, so a repeated name would overwrite an earlier asset
+				name := filepath.Base(s.Name)
+				if first, ok := seen[name]; ok {
+					return nil, fmt.Errorf("duplicate prompt name %q at indexes %d and %d", name, first, i)
+				}
+				seen[name] = i
+			}
+			specs = append(specs, s)
+		}
+		return specs, nil
+	}
+
+	var specs []promptSpec
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		specs = append(specs, promptSpec{Prompt: line})
+	}
+	return specs, scanner.Err()
+}
+
+// applyDefaults fills the style and size each spec leaves unset from the command-line flags
+func applyDefaults(specs []promptSpec, style string, width, height int) {
+	for i := range specs {
+		if specs[i].Style == "" {
+			specs[i].Style = style
+		}
+		if specs[i].Width == 0 {
+			specs[i].Width = width
+		}
+		if specs[i].Height == 0 {
+			specs[i].Height = height
+		}
+	}
+}
+
+func outputName(s promptSpec, format string) string {
+	if s.Name == "" {
+		return fmt.Sprintf("<asset id>.%s", format)
+	}
+	return fmt.Sprintf("%s.%s", filepath.Base(s.Name), format)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePrompts(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prompts")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write prompts: %v", err)
+	}
+	return path
+}
+
+func TestLoadPrompts(t *testing.T) {
+	specs, err := loadPrompts(writePrompts(t, "# icons\nsword\n\n  shield  \n"))
+	if err != nil || len(specs) != 2 || specs[0].Prompt != "sword" || specs[1].Prompt != "shield" {
+		t.Errorf("Expected two line prompts, got %+v (%v)", specs, err)
+	}
+
+	specs, err = loadPrompts(writePrompts(t, `["potion", {"name": "axe", "prompt": "a battle axe", "width": 128}, {"prompt": " "}]`))
+	if err != nil || len(specs) != 2 {
+		t.Fatalf("Expected two JSON prompts, got %+v (%v)", specs, err)
+	}
+	if specs[0].Prompt != "potion" || specs[1].Name != "axe" || specs[1].Prompt != "a battle axe" || specs[1].Width != 128 {
+		t.Errorf("Expected the string and object prompts, got %+v", specs)
+	}
+
+	if _, err := loadPrompts(writePrompts(t, `[{"name": "axe", "prompt": "a"}, {"name": "icons/axe", "prompt": "b"}]`)); err == nil || !strings.Contains(err.Error(), `duplicate prompt name "axe"`) {
+		t.Errorf("Expected a duplicate name error, got %v", err)
+	}
+	if _, err := loadPrompts(writePrompts(t, `[{"prompt": 1}]`)); err == nil {
+		t.Error("Expected an error for a malformed prompt")
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	specs := []promptSpec{
+		{Prompt: "sword"},
+		{Prompt: "axe", Style: "pixel art", Width: 128, Height: 64},
+	}
+	applyDefaults(specs, "flat icon", 512, 256)
+	if s := specs[0]; s.Style != "flat icon" || s.Width != 512 || s.Height != 256 {
+		t.Errorf("Expected the flag defaults, got %+v", s)
+	}
+	if s := specs[1]; s.Style != "pixel art" || s.Width != 128 || s.Height != 64 {
+		t.Errorf("Expected the prompt's own settings to override the flags, got %+v", s)
+	}
+}
@@ -31,7 +31,7 @@ func main() {
 	// Test framework health
 	fmt.Println("\n🔍 Testing framework health...")
 	if err := engine.Health(ctx); err != nil {
-		fmt.Printf("⚠️  Health check failed (expected with placeholder API key): %v\n", err)
+		fmt.Printf("⚠️  Health check failed (check THETA_API_KEY and network access): %v\n", err)
 	} else {
 		fmt.Println("✅ Framework is healthy!")
 	}
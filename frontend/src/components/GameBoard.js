@@ -412,6 +412,7 @@ function GameBoard() {
                   title={item.title}
                   titleColor={item.titleColor}
                   profilePicture={item.profilePicture}
+                  conviction={item.conviction}
                 />
               );
             } else {
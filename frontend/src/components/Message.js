@@ -52,6 +52,15 @@ const Username = styled.span`
   font-size: 14px;
 `;
 
+const Conviction = styled.span`
+  margin-left: auto;
+  font-size: 11px;
+  color: rgba(255, 255, 255, 0.6);
+  border: 1px solid rgba(255, 255, 255, 0.2);
+  border-radius: 8px;
+  padding: 1px 6px;
+`;
+
 const Timestamp = styled.div`
   font-size: 12px;
   color: rgba(255, 255, 255, 0.5);
@@ -249,7 +258,7 @@ function renderMessageBody(raw) {
   return <ReactMarkdown>{raw}</ReactMarkdown>;
 }
 
-const Message = ({ message, isSystem = false, isPlayer: isPlayerProp, time, name, title, titleColor, profilePicture }) => {
+const Message = ({ message, isSystem = false, isPlayer: isPlayerProp, time, name, title, titleColor, profilePicture, conviction }) => {
   const timestamp = time || Date.now();
   const isPlayer = typeof isPlayerProp === 'boolean' ? isPlayerProp : !isSystem;
 
@@ -275,6 +284,11 @@ const Message = ({ message, isSystem = false, isPlayer: isPlayerProp, time, name
                 {displayTitle}
               </span>
             )}
+            {typeof conviction === 'number' && (
+              <Conviction title="How strongly this advisor stands behind the advice">
+                Conviction {conviction}/10
+              </Conviction>
+            )}
           </MessageHeader>
         )}
         <MessageText>
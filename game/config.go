@@ -2,11 +2,16 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
+
+	fw "github.com/emergent-world-engine/backend/pkg/framework"
 )
 
 // GameConfig holds tunable settings loaded from env / defaults
@@ -16,18 +21,97 @@ type GameConfig struct {
 	MetricMax          int
 	UseNarrativeEvents bool
 	UseDirectorEvents  bool
+	HistoryWindow      int // recent turns the Director sees when evaluating a decision
+	MaxRerollsPerTurn  int // times the player may discard and regenerate the current event
+	Seed               int64 // PRES_SIM_SEED; 0 picks a time-based seed
+	ScoreWeights       WorldMetricsWeights // per-metric final score weights (PRES_SIM_SCORE_WEIGHTS)
+	ShutdownGrace      time.Duration // PRES_SIM_SHUTDOWN_GRACE; how long in-flight requests may finish on shutdown
+	ModelCosts         map[string]fw.ModelCost // PRES_SIM_MODEL_COSTS; USD per million prompt/completion tokens by model
+	AdvisorsPerTurn    int // PRES_SIM_ADVISORS_PER_TURN; advisors consulted each turn, capped at the roster size
+	LLMNewspaper       bool // PRES_SIM_LLM_NEWSPAPER; have the model write the endgame newspaper instead of the fixed template
+	MaxDeltaPerTurn    float64 // PRES_SIM_MAX_DELTA_PER_TURN; largest move of any metric in one turn, 0 for no cap
+	MaxOpinionSentences int // PRES_SIM_MAX_OPINION_SENTENCES; sentences kept from each advisor opinion, 0 keeps the full text
+	MaxBodyBytes       int64 // PRES_SIM_MAX_BODY_BYTES; largest JSON request body accepted, larger ones get 413
+	MaxReasoningChars  int // PRES_SIM_MAX_REASONING_CHARS; longest player reasoning sent to the Director, longer gets 400
+	PhotoPrompt        *template.Template // PRES_SIM_PHOTO_PROMPT(_FILE); news photo prompt template, nil uses the BBC/AP prompt
 }
 
 func loadGameConfig() *GameConfig {
-	cfg := &GameConfig{MaxTurns: 5, MetricMin: 40, MetricMax: 70, UseNarrativeEvents: true, UseDirectorEvents: true}
+	cfg := &GameConfig{MaxTurns: 5, MetricMin: 40, MetricMax: 70, UseNarrativeEvents: true, UseDirectorEvents: true, HistoryWindow: 3, MaxRerollsPerTurn: 1, ScoreWeights: equalWeights(), ShutdownGrace: 40 * time.Second, AdvisorsPerTurn: defaultAdvisorsPerTurn, LLMNewspaper: true, MaxDeltaPerTurn: defaultMaxDeltaPerTurn, MaxOpinionSentences: defaultMaxOpinionSentences, MaxBodyBytes: defaultMaxBodyBytes, MaxReasoningChars: defaultMaxReasoningChars}
 	if v := os.Getenv("PRES_SIM_MAX_TURNS"); v != "" { if i,err:=strconv.Atoi(v); err==nil && i>0 { cfg.MaxTurns = i } }
 	if v := os.Getenv("PRES_SIM_METRIC_MIN"); v != "" { if i,err:=strconv.Atoi(v); err==nil { cfg.MetricMin = i } }
 	if v := os.Getenv("PRES_SIM_METRIC_MAX"); v != "" { if i,err:=strconv.Atoi(v); err==nil { cfg.MetricMax = i } }
 	if v := os.Getenv("PRES_SIM_USE_NARRATIVE"); v != "" { vv := strings.ToLower(v); cfg.UseNarrativeEvents = vv=="1" || vv=="true" || vv=="yes" }
 	if v := os.Getenv("PRES_SIM_USE_DIRECTOR"); v != "" { vv := strings.ToLower(v); cfg.UseDirectorEvents = vv=="1" || vv=="true" || vv=="yes" }
+	if v := os.Getenv("PRES_SIM_HISTORY_WINDOW"); v != "" { if i,err:=strconv.Atoi(v); err==nil && i>=0 { cfg.HistoryWindow = i } }
+	if v := os.Getenv("PRES_SIM_MAX_REROLLS"); v != "" { if i,err:=strconv.Atoi(v); err==nil && i>=0 { cfg.MaxRerollsPerTurn = i } }
+	if v := os.Getenv("PRES_SIM_SEED"); v != "" { if i,err:=strconv.ParseInt(v, 10, 64); err==nil { cfg.Seed = i } }
+	if v := os.Getenv("PRES_SIM_SCORE_WEIGHTS"); v != "" { cfg.ScoreWeights = parseScoreWeights(v, cfg.ScoreWeights) }
+	if v := os.Getenv("PRES_SIM_SHUTDOWN_GRACE"); v != "" { if d,err:=time.ParseDuration(v); err==nil && d>=0 { cfg.ShutdownGrace = d } }
+	if v := os.Getenv("PRES_SIM_MODEL_COSTS"); v != "" { cfg.ModelCosts = parseModelCosts(v) }
+	if v := os.Getenv("PRES_SIM_MAX_DELTA_PER_TURN"); v != "" { if f,err:=strconv.ParseFloat(v, 64); err==nil && f>=0 { cfg.MaxDeltaPerTurn = f } }
+	if v := os.Getenv("PRES_SIM_MAX_OPINION_SENTENCES"); v != "" { if n,err:=strconv.Atoi(v); err==nil && n>=0 { cfg.MaxOpinionSentences = n } }
+	if v := os.Getenv("PRES_SIM_MAX_BODY_BYTES"); v != "" { if n,err:=strconv.ParseInt(v, 10, 64); err==nil && n>0 { cfg.MaxBodyBytes = n } }
+	if v := os.Getenv("PRES_SIM_MAX_REASONING_CHARS"); v != "" { if n,err:=strconv.Atoi(v); err==nil && n>0 { cfg.MaxReasoningChars = n } }
+	if v := os.Getenv("PRES_SIM_LLM_NEWSPAPER"); v != "" { vv := strings.ToLower(v); cfg.LLMNewspaper = vv=="1" || vv=="true" || vv=="yes" }
+	if v := os.Getenv("PRES_SIM_ADVISORS_PER_TURN"); v != "" { if i,err:=strconv.Atoi(v); err==nil && i>0 { cfg.AdvisorsPerTurn = i } }
+	cfg.PhotoPrompt = loadPhotoPrompt()
 	return cfg
 }
 
+// loadPhotoPrompt reads the news photo prompt template from the file named by
+// PRES_SIM_PHOTO_PROMPT_FILE, or inline from PRES_SIM_PHOTO_PROMPT. It returns nil (the BBC/AP
+// prompt) when neither is set or the template is invalid.
+func loadPhotoPrompt() *template.Template {
+	src, from := os.Getenv("PRES_SIM_PHOTO_PROMPT"), "PRES_SIM_PHOTO_PROMPT"
+	if path := os.Getenv("PRES_SIM_PHOTO_PROMPT_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("[CONFIG] ignoring PRES_SIM_PHOTO_PROMPT_FILE=%s: %v\n", path, err)
+			return nil
+		}
+		src, from = string(data), "PRES_SIM_PHOTO_PROMPT_FILE="+path
+	}
+	if strings.TrimSpace(src) == "" { return nil }
+	tmpl, err := parsePhotoPrompt(src)
+	if err != nil {
+		fmt.Printf("[CONFIG] ignoring %s: %v (using the default photo prompt)\n", from, err)
+		return nil
+	}
+	return tmpl
+}
+
+// parseScoreWeights reads "economy=2,approval=1.5" style overrides on top of base;
+// unknown metrics and non-numeric or negative values are ignored
+func parseScoreWeights(v string, base WorldMetricsWeights) WorldMetricsWeights {
+	fields := map[string]*float64{"economy": &base.Economy, "security": &base.Security, "diplomacy": &base.Diplomacy, "environment": &base.Environment, "approval": &base.Approval, "stability": &base.Stability}
+	for _, part := range strings.Split(v, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 { continue }
+		target, ok := fields[strings.ToLower(strings.TrimSpace(kv[0]))]
+		if !ok { continue }
+		if f, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil && f >= 0 { *target = f }
+	}
+	return base
+}
+
+// parseModelCosts reads "deepseek_r1=0.55/2.19,llama_3_1_70b=0.9" style prices in USD per
+// million prompt/completion tokens; a single price applies to both. Malformed entries are ignored.
+func parseModelCosts(v string) map[string]fw.ModelCost {
+	costs := map[string]fw.ModelCost{}
+	for _, part := range strings.Split(v, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" { continue }
+		prompt, completion, found := strings.Cut(kv[1], "/")
+		if !found { completion = prompt }
+		p, perr := strconv.ParseFloat(strings.TrimSpace(prompt), 64)
+		c, cerr := strconv.ParseFloat(strings.TrimSpace(completion), 64)
+		if perr != nil || cerr != nil || p < 0 || c < 0 { continue }
+		costs[strings.TrimSpace(kv[0])] = fw.ModelCost{PromptPerMillion: p, CompletionPerMillion: c}
+	}
+	return costs
+}
+
 // loadDotEnv loads key=value pairs from .env into environment
 func loadDotEnv() {
 	paths := []string{".env", "../.env", "../../.env", "game/.env"}
@@ -59,3 +143,64 @@ func loadDotEnv() {
 		fmt.Println("No THETA_API_KEY found after scanning paths:", paths)
 	}
 }
+
+// minAdvisors is the smallest roster accepted from PRES_SIM_ADVISORS
+const minAdvisors = 3
+
+// defaultMaxDeltaPerTurn caps a turn's metric swing just above the "high" impact range (30-50),
+// so an "extreme" impact still stands out without snapping a metric to its bound
+const defaultMaxDeltaPerTurn = 60
+
+// defaultMaxOpinionSentences keeps advisor chat bubbles short unless PRES_SIM_MAX_OPINION_SENTENCES says otherwise
+const defaultMaxOpinionSentences = 3
+
+// Request limits: a choice body is a few short fields, and reasoning past a couple of thousand
+// characters only eats into the Director's token budget
+const (
+	defaultMaxBodyBytes      = 64 << 10
+	defaultMaxReasoningChars = 2000
+)
+
+// defaultAdvisorsPerTurn is how many advisors weigh in on each event unless PRES_SIM_ADVISORS_PER_TURN says otherwise
+const defaultAdvisorsPerTurn = 3
+
+// defaultAdvisors is the built-in cabinet used when PRES_SIM_ADVISORS is unset or invalid
+var defaultAdvisors = []Advisor{
+	{ID: "sec_state", Name: "Sarah Mitchell", Title: "Secretary of State", Personality: "Diplomatic, measured, internationally focused", Specialty: "diplomacy"},
+	{ID: "sec_defense", Name: "General Marcus Torres", Title: "Secretary of Defense", Personality: "Decisive, security-focused, strategic", Specialty: "security"},
+	{ID: "sec_treasury", Name: "Dr. Rachel Chen", Title: "Secretary of Treasury", Personality: "Analytical, data-driven, economically minded", Specialty: "economy"},
+	{ID: "chief_staff", Name: "David Rodriguez", Title: "Chief of Staff", Personality: "Pragmatic, political, big-picture thinker", Specialty: "domestic"},
+	{ID: "epa_admin", Name: "Dr. Amanda Green", Title: "EPA Administrator", Personality: "Passionate, science-based, future-oriented", Specialty: "environment"},
+	{ID: "nsc_advisor", Name: "Colonel James Wright", Title: "National Security Advisor", Personality: "Intelligence-focused, cautious, thorough", Specialty: "military"},
+	{ID: "domestic_policy", Name: "Maria Santos", Title: "Domestic Policy Advisor", Personality: "People-focused, empathetic, reform-minded", Specialty: "social"},
+	{ID: "tech_advisor", Name: "Dr. Alex Kim", Title: "Technology Advisor", Personality: "Innovation-focused, forward-thinking, disruptive", Specialty: "tech"},
+}
+
+// loadAdvisors reads the advisor roster from the JSON file named by PRES_SIM_ADVISORS
+// (an array of Advisor objects), falling back to defaultAdvisors when unset or invalid
+func loadAdvisors() []Advisor {
+	path := os.Getenv("PRES_SIM_ADVISORS")
+	if path == "" { return append([]Advisor(nil), defaultAdvisors...) }
+	advisors, err := readAdvisorsFile(path)
+	if err != nil {
+		fmt.Printf("[CONFIG] ignoring PRES_SIM_ADVISORS=%s: %v (using default advisors)\n", path, err)
+		return append([]Advisor(nil), defaultAdvisors...)
+	}
+	fmt.Printf("[CONFIG] loaded %d advisors from %s\n", len(advisors), path)
+	return advisors
+}
+
+func readAdvisorsFile(path string) ([]Advisor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil { return nil, err }
+	var advisors []Advisor
+	if err := json.Unmarshal(data, &advisors); err != nil { return nil, fmt.Errorf("invalid advisors JSON: %w", err) }
+	if len(advisors) < minAdvisors { return nil, fmt.Errorf("need at least %d advisors, got %d", minAdvisors, len(advisors)) }
+	seen := make(map[string]bool, len(advisors))
+	for i, a := range advisors {
+		if strings.TrimSpace(a.ID) == "" || strings.TrimSpace(a.Name) == "" { return nil, fmt.Errorf("advisor %d is missing an id or name", i) }
+		if seen[a.ID] { return nil, fmt.Errorf("duplicate advisor id %q", a.ID) }
+		seen[a.ID] = true
+	}
+	return advisors, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+// writeAdvisorsFile writes body to a temp file and points PRES_SIM_ADVISORS at it
+func writeAdvisorsFile(t *testing.T, body string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "advisors.json")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("Failed to write advisors file: %v", err)
+	}
+	t.Setenv("PRES_SIM_ADVISORS", path)
+}
+
+func TestLoadAdvisors(t *testing.T) {
+	writeAdvisorsFile(t, `[
+		{"id": "a", "name": "Ada", "title": "Chancellor", "specialty": "economy"},
+		{"id": "b", "name": "Bo", "title": "Marshal", "specialty": "security"},
+		{"id": "c", "name": "Cy", "title": "Envoy", "specialty": "diplomacy"}
+	]`)
+	advisors := loadAdvisors()
+	if len(advisors) != 3 || advisors[0].ID != "a" || advisors[2].Specialty != "diplomacy" {
+		t.Errorf("Expected the 3 advisors from the file, got %+v", advisors)
+	}
+}
+
+func TestLoadAdvisorsFallback(t *testing.T) {
+	cases := map[string]string{
+		"invalid json": `[{"id": "a",`,
+		"too few":      `[{"id": "a", "name": "Ada"}, {"id": "b", "name": "Bo"}]`,
+		"missing name": `[{"id": "a", "name": "Ada"}, {"id": "b", "name": "Bo"}, {"id": "c"}]`,
+		"duplicate id": `[{"id": "a", "name": "Ada"}, {"id": "b", "name": "Bo"}, {"id": "a", "name": "Al"}]`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			writeAdvisorsFile(t, body)
+			if advisors := loadAdvisors(); len(advisors) != len(defaultAdvisors) || advisors[0].ID != defaultAdvisors[0].ID {
+				t.Errorf("Expected the default advisors, got %d advisors", len(advisors))
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv("PRES_SIM_ADVISORS", filepath.Join(t.TempDir(), "nope.json"))
+		if advisors := loadAdvisors(); len(advisors) != len(defaultAdvisors) {
+			t.Errorf("Expected the default advisors, got %d advisors", len(advisors))
+		}
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		t.Setenv("PRES_SIM_ADVISORS", "")
+		advisors := loadAdvisors()
+		advisors[0].Name = "changed"
+		if defaultAdvisors[0].Name == "changed" {
+			t.Error("Expected loadAdvisors to return a copy of the defaults")
+		}
+	})
+}
+
+func TestAdvisorsPerTurnConfig(t *testing.T) {
+	cases := map[string]int{"": defaultAdvisorsPerTurn, "1": 1, "5": 5, "0": defaultAdvisorsPerTurn, "-2": defaultAdvisorsPerTurn, "many": defaultAdvisorsPerTurn}
+	for v, want := range cases {
+		t.Setenv("PRES_SIM_ADVISORS_PER_TURN", v)
+		if got := loadGameConfig().AdvisorsPerTurn; got != want {
+			t.Errorf("PRES_SIM_ADVISORS_PER_TURN=%q: expected %d, got %d", v, want, got)
+		}
+	}
+}
+
+func TestPhotoPromptTemplate(t *testing.T) {
+	evt := &GameEvent{Title: "Port Strike", Category: "economy", Severity: 7, Description: "Dockworkers walk out."}
+	sim := &PresidentSim{config: &GameConfig{}}
+	if got := sim.photoPrompt(evt); got != buildBBCPhotoPrompt(evt) {
+		t.Errorf("Expected the default prompt without a template, got %q", got)
+	}
+
+	tmpl, err := parsePhotoPrompt("Oil painting of {{.Title}} ({{.Category}}, {{.Severity}}/10): {{.Description}}")
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	sim.config.PhotoPrompt = tmpl
+	if got, want := sim.photoPrompt(evt), "Oil painting of Port Strike (economy, 7/10): Dockworkers walk out."; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	for _, src := range []string{"{{.Title", "{{.Headline}}", "{{index .Title 40}}"} {
+		if _, err := parsePhotoPrompt(src); err == nil {
+			t.Errorf("Expected parsePhotoPrompt(%q) to fail", src)
+		}
+	}
+}
+
+func TestLoadPhotoPrompt(t *testing.T) {
+	evt := &GameEvent{Title: "Port Strike"}
+	render := func(tmpl *template.Template) string {
+		var b strings.Builder
+		tmpl.Execute(&b, photoPromptData{Title: evt.Title})
+		return b.String()
+	}
+
+	t.Setenv("PRES_SIM_PHOTO_PROMPT", "Inline {{.Title}}")
+	t.Setenv("PRES_SIM_PHOTO_PROMPT_FILE", "")
+	if tmpl := loadPhotoPrompt(); tmpl == nil || render(tmpl) != "Inline Port Strike" {
+		t.Error("Expected the inline template from PRES_SIM_PHOTO_PROMPT")
+	}
+
+	path := filepath.Join(t.TempDir(), "photo.tmpl")
+	if err := os.WriteFile(path, []byte("File {{.Title}}"), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	t.Setenv("PRES_SIM_PHOTO_PROMPT_FILE", path)
+	if tmpl := loadPhotoPrompt(); tmpl == nil || render(tmpl) != "File Port Strike" {
+		t.Error("Expected the file template to take precedence")
+	}
+
+	t.Setenv("PRES_SIM_PHOTO_PROMPT_FILE", filepath.Join(t.TempDir(), "missing.tmpl"))
+	if loadPhotoPrompt() != nil {
+		t.Error("Expected a missing template file to fall back to the default prompt")
+	}
+	t.Setenv("PRES_SIM_PHOTO_PROMPT_FILE", "")
+	t.Setenv("PRES_SIM_PHOTO_PROMPT", "{{.Nope}}")
+	if loadPhotoPrompt() != nil {
+		t.Error("Expected an invalid template to fall back to the default prompt")
+	}
+	t.Setenv("PRES_SIM_PHOTO_PROMPT", "")
+	if loadPhotoPrompt() != nil {
+		t.Error("Expected no template when neither variable is set")
+	}
+}
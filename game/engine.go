@@ -2,13 +2,22 @@ package main
 
 import (
 	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	fw "github.com/emergent-world-engine/backend/pkg/framework"
+	gemini "presidential-simulator/internal/gemini_client"
 	imgc "presidential-simulator/internal/ondemand_image_client"
 )
 
@@ -18,7 +27,24 @@ type PresidentSim struct {
 	director  *fw.Director
 	narrative *fw.Narrative
 	state     *GameState
+	// stateMu guards state: every write holds it, and reads that can overlap a write (handlers,
+	// background streams) hold it or go through snapshotState. A published CurrentTurn is never
+	// changed in place; writers install an updated copy.
+	stateMu   sync.RWMutex
 	advisors  map[string]*fw.NPC
+	config    *GameConfig
+	rng       *rand.Rand // all gameplay randomness; seeded from PRES_SIM_SEED for reproducible runs
+
+	savesMu   sync.Mutex
+	saves     map[string][]byte // in-memory save slots when Redis is not configured
+	saveOrder []string          // save tokens oldest first, for evicting beyond maxMemorySaves
+
+	imagesMu   sync.Mutex
+	images     map[string]string // imageKey -> generated image URL (mirrored to Redis when enabled)
+	imageOrder []string          // image keys oldest first, for evicting beyond maxMemoryImages
+	imageGen imageGenerator    // nil uses the on-demand image client
+
+	usageBase fw.TokenUsage // engine token usage when the current session started
 }
 
 func NewPresidentSim(apiKey string) (*PresidentSim, error) {
@@ -26,14 +52,21 @@ func NewPresidentSim(apiKey string) (*PresidentSim, error) {
 	if apiKey == "" {
 		apiKey = getenvFirst([]string{"THETA_API_KEY", "THETA_KEY"})
 	}
-	eng, err := fw.NewEngine(&fw.Config{ThetaAPIKey: apiKey, EnableLogging: true, ThetaEndpoint: getenv("THETA_BASE_URL")})
+	redisURL := getenv("REDIS_URL")
+	cfg := loadGameConfig()
+	// Gemini backs up Theta for every engine call when GOOGLE_AI_API_KEY is set
+	var providers []fw.LLMProvider
+	if c := gemini.New(); c.APIKey != "" {
+		providers = append(providers, &geminiProvider{client: c})
+	}
+	eng, err := fw.NewEngine(&fw.Config{ThetaAPIKey: apiKey, EnableLogging: true, LogLevel: getenv("LOG_LEVEL"), LogFormat: getenv("LOG_FORMAT"), ThetaEndpoint: getenv("THETA_BASE_URL"), ModelEndpoints: fw.ParseModelEndpoints(getenv("THETA_MODEL_ENDPOINTS")), RedisURL: redisURL, EnableRedis: redisURL != "", ModelCosts: cfg.ModelCosts, Providers: providers})
 	if err != nil {
 		return nil, err
 	}
-	cfg := loadGameConfig()
+	rng := newSimRand(cfg.Seed)
 	// randomize initial metrics within configured range
 	minV, maxV := cfg.MetricMin, cfg.MetricMax
-	randVal := func() float64 { if maxV > minV { return float64(minV + rand.Intn(maxV-minV+1)) }; return float64(minV) }
+	randVal := func() float64 { if maxV > minV { return float64(minV + rng.Intn(maxV-minV+1)) }; return float64(minV) }
 	gameState := &GameState{
 		Turn:     1,
 		MaxTurns: cfg.MaxTurns,
@@ -50,18 +83,8 @@ func NewPresidentSim(apiKey string) (*PresidentSim, error) {
 		Stats:       AIUsageStats{},
 	}
 
-	// Initialize the 8 advisors
-	advisorDefinitions := []Advisor{
-		{ID: "sec_state", Name: "Sarah Mitchell", Title: "Secretary of State", Personality: "Diplomatic, measured, internationally focused", Specialty: "diplomacy"},
-		{ID: "sec_defense", Name: "General Marcus Torres", Title: "Secretary of Defense", Personality: "Decisive, security-focused, strategic", Specialty: "security"},
-		{ID: "sec_treasury", Name: "Dr. Rachel Chen", Title: "Secretary of Treasury", Personality: "Analytical, data-driven, economically minded", Specialty: "economy"},
-		{ID: "chief_staff", Name: "David Rodriguez", Title: "Chief of Staff", Personality: "Pragmatic, political, big-picture thinker", Specialty: "domestic"},
-		{ID: "epa_admin", Name: "Dr. Amanda Green", Title: "EPA Administrator", Personality: "Passionate, science-based, future-oriented", Specialty: "environment"},
-		{ID: "nsc_advisor", Name: "Colonel James Wright", Title: "National Security Advisor", Personality: "Intelligence-focused, cautious, thorough", Specialty: "military"},
-		{ID: "domestic_policy", Name: "Maria Santos", Title: "Domestic Policy Advisor", Personality: "People-focused, empathetic, reform-minded", Specialty: "social"},
-		{ID: "tech_advisor", Name: "Dr. Alex Kim", Title: "Technology Advisor", Personality: "Innovation-focused, forward-thinking, disruptive", Specialty: "tech"},
-	}
-
+	// Advisor roster: PRES_SIM_ADVISORS file or the built-in 8
+	advisorDefinitions := loadAdvisors()
 	gameState.Advisors = advisorDefinitions
 
 	// Create NPC instances for all advisors
@@ -79,14 +102,195 @@ func NewPresidentSim(apiKey string) (*PresidentSim, error) {
 		engine:   eng,
 		state:    gameState,
 		advisors: advisorNPCs,
+		config:   cfg,
+		rng:      rng,
 	}
 
-	ps.director = eng.NewDirector(fw.WithStrategicFocus("balance"))
+	ps.director = eng.NewDirector(fw.WithStrategicFocus("balance"), fw.WithEventHistoryWindow(cfg.HistoryWindow))
 	ps.narrative = eng.NewNarrative(fw.WithGenre("political"), fw.WithTone("tense"), fw.WithPlayerChoice(true))
 
 	return ps, nil
 }
 
+// geminiProvider is the Gemini client as an fw.LLMProvider, tried after Theta in the engine's chain
+type geminiProvider struct {
+	client *gemini.Client
+}
+
+// geminiTraceKey carries the *atomic.Bool geminiProvider sets when it answers a call
+type geminiTraceKey struct{}
+
+// withGeminiTrace returns ctx with a flag that is set if geminiProvider answers a call made with it
+func withGeminiTrace(ctx context.Context) (context.Context, *atomic.Bool) {
+	used := new(atomic.Bool)
+	return context.WithValue(ctx, geminiTraceKey{}, used), used
+}
+
+func (p *geminiProvider) GenerateWithLLM(ctx context.Context, req *fw.LLMRequest) (*fw.LLMResponse, error) {
+	prompt := req.Prompt
+	if req.SystemPrompt != "" {
+		prompt = req.SystemPrompt + "\n\n" + prompt
+	}
+	text, err := p.client.GenerateText(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("gemini: %w", err)
+	}
+	if used, ok := ctx.Value(geminiTraceKey{}).(*atomic.Bool); ok {
+		used.Store(true)
+	}
+	return &fw.LLMResponse{Choices: []fw.LLMChoice{{Text: text}}}, nil
+}
+
+// GenerateWithLLMStream sends the whole Gemini completion as a single token
+func (p *geminiProvider) GenerateWithLLMStream(ctx context.Context, req *fw.LLMRequest) (<-chan string, <-chan error) {
+	out, errCh := make(chan string, 1), make(chan error, 1)
+	go func() {
+		defer close(out)
+		defer close(errCh)
+		resp, err := p.GenerateWithLLM(ctx, req)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		out <- resp.Choices[0].Text
+	}()
+	return out, errCh
+}
+
+// usageStats returns the session's AI usage stats with the Theta tokens spent since it started
+func (ps *PresidentSim) usageStats() AIUsageStats {
+	ps.stateMu.RLock()
+	stats := ps.state.Stats
+	ps.stateMu.RUnlock()
+	now := ps.engine.Metrics().TokenUsage
+	stats.PromptTokens = now.PromptTokens - ps.usageBase.PromptTokens
+	stats.CompletionTokens = now.CompletionTokens - ps.usageBase.CompletionTokens
+	stats.TotalTokens = now.TotalTokens - ps.usageBase.TotalTokens
+	stats.EstimatedCostUSD = now.EstimatedCostUSD - ps.usageBase.EstimatedCostUSD
+	return stats
+}
+
+// countUsage applies inc to the session's AI usage stats; advisor goroutines call it concurrently
+func (ps *PresidentSim) countUsage(inc func(*AIUsageStats)) {
+	ps.stateMu.Lock()
+	defer ps.stateMu.Unlock()
+	inc(&ps.state.Stats)
+}
+
+// lockedSource makes a rand.Source safe for the orchestrator's concurrent goroutines
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 { s.mu.Lock(); defer s.mu.Unlock(); return s.src.Int63() }
+func (s *lockedSource) Seed(seed int64) { s.mu.Lock(); defer s.mu.Unlock(); s.src.Seed(seed) }
+
+// resolveSeed returns seed, or a time-based one when it is 0, and logs it so any
+// playthrough can be replayed with PRES_SIM_SEED
+func resolveSeed(seed int64) int64 {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	fmt.Printf("[SIM] random seed %d (set PRES_SIM_SEED to replay)\n", seed)
+	return seed
+}
+
+func newSimRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(resolveSeed(seed))})
+}
+
+// saveTTL bounds how long a save token stays loadable in Redis
+const saveTTL = 7 * 24 * time.Hour
+
+// maxMemorySaves bounds the in-memory save slots; the oldest save is dropped to make room
+const maxMemorySaves = 64
+
+var errSaveNotFound = errors.New("save not found")
+
+func saveKey(token string) string { return "pres_sim:save:" + token }
+
+// SaveState serializes the full game state (turn, metrics, history, advisors, stats) and returns
+// a token for LoadState. Saves go to Redis when the engine has it, otherwise to memory.
+func (p *PresidentSim) SaveState(ctx context.Context) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := crand.Read(buf); err != nil { return "", fmt.Errorf("failed to create save token: %w", err) }
+	token := hex.EncodeToString(buf)
+	state := p.snapshotState()
+	data, err := json.Marshal(&state)
+	if err != nil { return "", fmt.Errorf("failed to serialize game state: %w", err) }
+	if p.engine.IsRedisEnabled() {
+		if err := p.engine.Redis().SetString(ctx, saveKey(token), string(data), saveTTL); err != nil {
+			return "", fmt.Errorf("failed to store save: %w", err)
+		}
+		return token, nil
+	}
+	p.savesMu.Lock()
+	defer p.savesMu.Unlock()
+	if p.saves == nil { p.saves = make(map[string][]byte) }
+	for len(p.saveOrder) >= maxMemorySaves {
+		delete(p.saves, p.saveOrder[0])
+		p.saveOrder = p.saveOrder[1:]
+	}
+	p.saves[token] = data
+	p.saveOrder = append(p.saveOrder, token)
+	return token, nil
+}
+
+// LoadState restores the game state saved under token; unknown tokens return errSaveNotFound
+func (p *PresidentSim) LoadState(ctx context.Context, token string) error {
+	var data []byte
+	if p.engine.IsRedisEnabled() {
+		raw, err := p.engine.Redis().GetString(ctx, saveKey(token))
+		if fw.IsRedisNotFound(err) { return errSaveNotFound }
+		if err != nil { return fmt.Errorf("failed to read save: %w", err) }
+		data = []byte(raw)
+	} else {
+		p.savesMu.Lock()
+		saved, ok := p.saves[token]
+		p.savesMu.Unlock()
+		if !ok { return errSaveNotFound }
+		data = saved
+	}
+	var state GameState
+	if err := json.Unmarshal(data, &state); err != nil { return fmt.Errorf("failed to decode save: %w", err) }
+	if state.History == nil { state.History = []TurnResult{} }
+	p.stateMu.Lock()
+	*p.state = state
+	p.stateMu.Unlock()
+	return nil
+}
+
+// currentTurn returns the published current turn, or nil; callers must not modify it
+func (p *PresidentSim) currentTurn() *TurnResult {
+	p.stateMu.RLock()
+	defer p.stateMu.RUnlock()
+	return p.state.CurrentTurn
+}
+
+// setEventImage publishes url as the current turn's event image if eventID is still current
+func (p *PresidentSim) setEventImage(eventID, url string) {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	if p.state == nil || p.state.CurrentTurn == nil || p.state.CurrentTurn.Event.ID != eventID { return }
+	updated := *p.state.CurrentTurn
+	updated.Event.ImageURL = url
+	p.state.CurrentTurn = &updated
+}
+
+// snapshotState copies the game state, including its history and current turn, for work that outlives the request
+func (p *PresidentSim) snapshotState() GameState {
+	p.stateMu.RLock()
+	defer p.stateMu.RUnlock()
+	s := *p.state
+	s.History = append([]TurnResult(nil), p.state.History...)
+	if p.state.CurrentTurn != nil {
+		current := *p.state.CurrentTurn
+		s.CurrentTurn = &current
+	}
+	return s
+}
+
 func (p *PresidentSim) Close() {
 	p.engine.Close()
 }
@@ -124,7 +328,7 @@ var usStates = []string{"Texas","California","Florida","Ohio","Arizona","Michiga
 var countries = []string{"Poland","Turkey","Japan","Germany","France","Canada","Mexico","Brazil","India","South Korea"}
 var companies = []string{"NorthStar Energy","Orion Analytics","Pioneer Biotech","Apex Dynamics","BlueRidge Systems","Summit Aerospace","Cobalt Rail"}
 
-func pickOne(list []string) string { return list[rand.Intn(len(list))] }
+func pickOne(rng *rand.Rand, list []string) string { return list[rng.Intn(len(list))] }
 
 func textContainsAny(text string, names []string) bool {
 	lower := strings.ToLower(text)
@@ -137,7 +341,7 @@ func textContainsAny(text string, names []string) bool {
 // injectSingleNamedEntity adds exactly one named entity (state, company, or foreign country)
 // into the event, preferring to annotate the title with "in/at NAME".
 // If the text already contains any of our known names, it will not add another.
-func injectSingleNamedEntity(topic, title, desc string) (string, string) {
+func injectSingleNamedEntity(rng *rand.Rand, topic, title, desc string) (string, string) {
 	combined := title + " " + desc
 	allNames := append(append([]string{}, usStates...), append(countries, companies...)...)
 	if textContainsAny(combined, allNames) {
@@ -147,13 +351,13 @@ func injectSingleNamedEntity(topic, title, desc string) (string, string) {
 	var name, prep string
 	switch topic {
 	case "geopolitics", "military_intervention", "security":
-		name, prep = pickOne(countries), "in"
+		name, prep = pickOne(rng, countries), "in"
 	case "economy", "technology":
-		name, prep = pickOne(companies), "at"
+		name, prep = pickOne(rng, companies), "at"
 	case "environment", "public_health":
-		name, prep = pickOne(usStates), "in"
+		name, prep = pickOne(rng, usStates), "in"
 	default:
-		name, prep = pickOne(usStates), "in"
+		name, prep = pickOne(rng, usStates), "in"
 	}
 
 	// Only add to title to keep a single mention overall
@@ -184,47 +388,192 @@ func (p *PresidentSim) GenerateTurnEvent(ctx context.Context) (*GameEvent, error
 
 	var seed struct{ Topic, Title, Desc string; Options []string }
 	if len(remaining) > 0 {
-		seed = remaining[rand.Intn(len(remaining))]
+		seed = remaining[p.rng.Intn(len(remaining))]
 	} else {
 		// All topics exhausted (e.g., MaxTurns > unique topics); fallback to any
-		seed = historicalTopicSeeds[rand.Intn(len(historicalTopicSeeds))]
+		seed = historicalTopicSeeds[p.rng.Intn(len(historicalTopicSeeds))]
 	}
 
 	id := fmt.Sprintf("evt_%s_%d", seed.Topic, time.Now().UnixNano())
-	sev := 5 + rand.Intn(5)
+	sev := 5 + p.rng.Intn(5)
 	// Slight variation injection
-	variant := []string{"People are unsure what happens next.", "News reports disagree on what's going on.", "An internal note says we should move quickly but carefully.", "Advisors say we should act soon, but not rush."}[rand.Intn(4)]
+	variant := []string{"People are unsure what happens next.", "News reports disagree on what's going on.", "An internal note says we should move quickly but carefully.", "Advisors say we should act soon, but not rush."}[p.rng.Intn(4)]
 	desc := fmt.Sprintf("%s %s", seed.Desc, variant)
 
 	// Add exactly one named entity suited to the topic
 	title := seed.Title
-	title, desc = injectSingleNamedEntity(seed.Topic, title, desc)
+	title, desc = injectSingleNamedEntity(p.rng, seed.Topic, title, desc)
 
 	// Use free-form seed title and description (no templated BREAKING format)
+	// Image generation is kicked off by the orchestrator so it can be cancelled on reroll
 	evt := &GameEvent{ID: id, Title: title, Description: desc, Category: seed.Topic, Severity: sev, Options: seed.Options}
+	return evt, nil
+}
 
-	// Kick off async image generation
-	go p.enqueueEventImage(context.Background(), evt)
+func (p *PresidentSim) maxRerollsPerTurn() int {
+	if p.config == nil { return 0 }
+	return p.config.MaxRerollsPerTurn
+}
 
-	return evt, nil
+// advisorsPerTurn is the configured advisor count, capped at the roster size
+func (p *PresidentSim) advisorsPerTurn() int {
+	n := defaultAdvisorsPerTurn
+	if p.config != nil && p.config.AdvisorsPerTurn > 0 { n = p.config.AdvisorsPerTurn }
+	return min(n, len(p.state.Advisors))
+}
+
+func (p *PresidentSim) maxDeltaPerTurn() float64 {
+	if p.config == nil { return defaultMaxDeltaPerTurn }
+	return p.config.MaxDeltaPerTurn
+}
+
+func (p *PresidentSim) maxOpinionSentences() int {
+	if p.config == nil { return defaultMaxOpinionSentences }
+	return p.config.MaxOpinionSentences
+}
+
+func (p *PresidentSim) maxBodyBytes() int64 {
+	if p.config == nil || p.config.MaxBodyBytes <= 0 { return defaultMaxBodyBytes }
+	return p.config.MaxBodyBytes
+}
+
+func (p *PresidentSim) maxReasoningChars() int {
+	if p.config == nil || p.config.MaxReasoningChars <= 0 { return defaultMaxReasoningChars }
+	return p.config.MaxReasoningChars
+}
+
+func (p *PresidentSim) scoreWeights() WorldMetricsWeights {
+	if p.config == nil { return equalWeights() }
+	return p.config.ScoreWeights
 }
 
 func severityLabel(s int) string { switch { case s>=8: return "high"; case s>=6: return "moderate"; default: return "low" } }
 
-// enqueueEventImage builds a news-photo style prompt and requests an image; stores URL on the event when available
-func (p *PresidentSim) enqueueEventImage(ctx context.Context, evt *GameEvent) {
+// imageTTL bounds how long a generated event image URL stays cached in Redis
+const imageTTL = 24 * time.Hour
+
+// turnImageWidth and turnImageHeight size the image generated for each new turn's event
+const (
+	turnImageWidth  = 800
+	turnImageHeight = 450
+)
+
+// imageKey names an event image at one size, so a thumbnail never stands in for the full image
+func imageKey(eventID string, width, height int) string {
+	return fmt.Sprintf("pres_sim:image:%s:%dx%d", eventID, width, height)
+}
+
+// imageGenerator renders a prompt to an image URL; the on-demand client in production, a stub in tests
+type imageGenerator interface {
+	Generate(ctx context.Context, prompt string, width, height int) (string, error)
+}
+
+func (p *PresidentSim) imageGenerator() imageGenerator {
+	if p.imageGen == nil { return imgc.New() }
+	return p.imageGen
+}
+
+// cachedEventImage returns the image URL already generated under key, checking memory then Redis
+func (p *PresidentSim) cachedEventImage(ctx context.Context, key string) (string, bool) {
+	p.imagesMu.Lock()
+	url, ok := p.images[key]
+	p.imagesMu.Unlock()
+	if ok { return url, true }
+	if p.engine == nil || !p.engine.IsRedisEnabled() { return "", false }
+	url, err := p.engine.Redis().GetString(ctx, key)
+	if err != nil {
+		if !fw.IsRedisNotFound(err) { fmt.Printf("[IMAGE] failed to read cached image %s: %v\n", key, err) }
+		return "", false
+	}
+	if strings.TrimSpace(url) == "" { return "", false }
+	p.rememberImage(key, url)
+	return url, true
+}
+
+// maxMemoryImages bounds the in-memory image cache; the oldest image is dropped to make room
+const maxMemoryImages = 256
+
+// rememberImage records url under key in memory, evicting the oldest images beyond maxMemoryImages
+func (p *PresidentSim) rememberImage(key, url string) {
+	p.imagesMu.Lock()
+	defer p.imagesMu.Unlock()
+	if p.images == nil { p.images = make(map[string]string) }
+	if _, ok := p.images[key]; !ok {
+		for len(p.imageOrder) >= maxMemoryImages {
+			delete(p.images, p.imageOrder[0])
+			p.imageOrder = p.imageOrder[1:]
+		}
+		p.imageOrder = append(p.imageOrder, key)
+	}
+	p.images[key] = url
+}
+
+func (p *PresidentSim) storeEventImage(ctx context.Context, key, url string) {
+	p.rememberImage(key, url)
+	if p.engine != nil && p.engine.IsRedisEnabled() {
+		if err := p.engine.Redis().SetString(ctx, key, url, imageTTL); err != nil {
+			fmt.Printf("[IMAGE] failed to cache image %s: %v\n", key, err)
+		}
+	}
+}
+
+// eventImage returns the cached image for evt at this size or generates (and caches) a new one
+func (p *PresidentSim) eventImage(ctx context.Context, evt *GameEvent, width, height int) (string, error) {
+	key := imageKey(evt.ID, width, height)
+	if url, ok := p.cachedEventImage(ctx, key); ok { return url, nil }
+	url, err := p.imageGenerator().Generate(ctx, p.photoPrompt(evt), width, height)
+	if err != nil { return "", err }
+	if strings.TrimSpace(url) != "" { p.storeEventImage(ctx, key, url) }
+	return url, nil
+}
+
+// enqueueEventImage builds a news-photo style prompt and requests an image; the URL is cached and,
+// if evt is still the current turn's event, stored on it. evt is a copy so the caller's event is not written.
+func (p *PresidentSim) enqueueEventImage(ctx context.Context, evt GameEvent) {
 	defer func(){ recover() }()
-	prompt := buildBBCPhotoPrompt(evt)
-	client := imgc.New()
-	url, err := client.Generate(ctx, prompt, 800, 450)
+	url, err := p.eventImage(ctx, &evt, turnImageWidth, turnImageHeight)
 	if err != nil { fmt.Println("[IMAGE] generation error:", err); return }
-	evt.ImageURL = url
-	if p.state != nil && p.state.CurrentTurn != nil && p.state.CurrentTurn.Event.ID == evt.ID {
-		p.state.CurrentTurn.Event.ImageURL = url
-	}
+	p.setEventImage(evt.ID, url)
 	fmt.Println("[IMAGE] generated URL:", url)
 }
 
+// turnImage returns the turn image already generated for eventID, if any
+func (p *PresidentSim) turnImage(eventID string) string {
+	p.imagesMu.Lock()
+	defer p.imagesMu.Unlock()
+	return p.images[imageKey(eventID, turnImageWidth, turnImageHeight)]
+}
+
+// photoPromptData is what a PRES_SIM_PHOTO_PROMPT template is rendered against
+type photoPromptData struct {
+	Title       string
+	Category    string
+	Severity    int
+	Description string
+}
+
+// parsePhotoPrompt parses a news photo prompt template and test-renders it, so syntax errors and
+// references to unknown fields are reported when the config loads
+func parsePhotoPrompt(src string) (*template.Template, error) {
+	tmpl, err := template.New("photo").Parse(src)
+	if err != nil { return nil, err }
+	if err := tmpl.Execute(io.Discard, photoPromptData{}); err != nil { return nil, err }
+	return tmpl, nil
+}
+
+// photoPrompt renders the configured photo prompt template for evt, falling back to the BBC/AP
+// prompt when none is configured or rendering fails
+func (p *PresidentSim) photoPrompt(evt *GameEvent) string {
+	if p.config == nil || p.config.PhotoPrompt == nil { return buildBBCPhotoPrompt(evt) }
+	var b strings.Builder
+	data := photoPromptData{Title: evt.Title, Category: evt.Category, Severity: evt.Severity, Description: evt.Description}
+	if err := p.config.PhotoPrompt.Execute(&b, data); err != nil || strings.TrimSpace(b.String()) == "" {
+		fmt.Printf("[IMAGE] photo prompt template failed (%v); using the default prompt\n", err)
+		return buildBBCPhotoPrompt(evt)
+	}
+	return b.String()
+}
+
 // buildBBCPhotoPrompt creates the requested BBC/AP style prompt with the event details
 func buildBBCPhotoPrompt(evt *GameEvent) string {
 	return fmt.Sprintf("Create a realistic news photo of this event. Keep it neutral and grounded.\n\nTitle: %s\nCategory: %s (Severity %d/10)\nDetails: %s\n\nStyle:\n- Photojournalism look (BBC/AP).\n- Realistic lighting.\n- Show the place and context (signs, buildings, equipment).\n- Medium-wide shot. Avoid close-ups of faces.\n- Professional camera look (35–50mm).", evt.Title, evt.Category, evt.Severity, evt.Description)
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	fw "github.com/emergent-world-engine/backend/pkg/framework"
+)
+
+// newTestSim builds a simulator with the default config and advisors on an engine whose Theta
+// endpoint refuses connections on the first attempt, so calls fall through to any providers opts
+// add (e.g. fw.WithProviders)
+func newTestSim(t *testing.T, opts ...fw.EngineOption) *PresidentSim {
+	t.Helper()
+	eng, err := fw.NewEngine(&fw.Config{ThetaAPIKey: "test_key", ThetaEndpoint: "http://127.0.0.1:1", RetryAttempts: 1}, opts...)
+	if err != nil {
+		t.Fatalf("Failed to initialize engine: %v", err)
+	}
+	t.Cleanup(func() { eng.Close() })
+	cfg := loadGameConfig()
+	state := &GameState{
+		Turn:     1,
+		MaxTurns: cfg.MaxTurns,
+		Metrics:  WorldMetrics{Economy: 50, Security: 50, Diplomacy: 50, Environment: 50, Approval: 50, Stability: 50},
+		History:  []TurnResult{},
+		Advisors: append([]Advisor(nil), defaultAdvisors...),
+	}
+	p := &PresidentSim{engine: eng, state: state, config: cfg, rng: newSimRand(1)}
+	p.director = eng.NewDirector(fw.WithStrategicFocus("balance"))
+	return p
+}
+
+// eventSequence plays turns events on a fresh simulator seeded with seed, recording each event
+func eventSequence(t *testing.T, seed int64, turns int) []string {
+	t.Helper()
+	p := &PresidentSim{state: &GameState{History: []TurnResult{}}, rng: newSimRand(seed)}
+	var events []string
+	for i := 1; i <= turns; i++ {
+		evt, err := p.GenerateTurnEvent(context.Background())
+		if err != nil {
+			t.Fatalf("GenerateTurnEvent failed: %v", err)
+		}
+		events = append(events, fmt.Sprintf("%s|%s|%d|%s", evt.Category, evt.Title, evt.Severity, evt.Description))
+		p.state.History = append(p.state.History, TurnResult{Turn: i, Event: *evt})
+	}
+	return events
+}
+
+func TestSeededEventSequence(t *testing.T) {
+	first, second := eventSequence(t, 42, 5), eventSequence(t, 42, 5)
+	if strings.Join(first, "\n") != strings.Join(second, "\n") {
+		t.Errorf("Expected the same seed to replay the same events:\n%s\nvs\n%s", strings.Join(first, "\n"), strings.Join(second, "\n"))
+	}
+	if other := eventSequence(t, 7, 5); strings.Join(other, "\n") == strings.Join(first, "\n") {
+		t.Error("Expected a different seed to produce a different sequence")
+	}
+}
+
+// stubLLM is an LLM provider answering every request with text (or reply's answer), or failing with err
+type stubLLM struct {
+	mu    sync.Mutex
+	text  string
+	reply func(prompt string) string
+	err   error
+	calls int
+}
+
+func (s *stubLLM) GenerateWithLLM(ctx context.Context, req *fw.LLMRequest) (*fw.LLMResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.reply != nil {
+		return &fw.LLMResponse{Choices: []fw.LLMChoice{{Text: s.reply(req.Prompt)}}}, nil
+	}
+	return &fw.LLMResponse{Choices: []fw.LLMChoice{{Text: s.text}}}, nil
+}
+
+func (s *stubLLM) GenerateWithLLMStream(ctx context.Context, req *fw.LLMRequest) (<-chan string, <-chan error) {
+	out, errCh := make(chan string, 1), make(chan error, 1)
+	resp, err := s.GenerateWithLLM(ctx, req)
+	if err != nil {
+		errCh <- err
+	} else {
+		out <- resp.Choices[0].Text
+	}
+	close(out)
+	close(errCh)
+	return out, errCh
+}
+
+func (s *stubLLM) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// stubImageGen returns a URL naming the requested size and counts its calls
+type stubImageGen struct{ calls atomic.Int32 }
+
+func (s *stubImageGen) Generate(ctx context.Context, prompt string, width, height int) (string, error) {
+	s.calls.Add(1)
+	return fmt.Sprintf("https://img.test/%dx%d.png", width, height), nil
+}
+
+func TestMemoryImagesAreCapped(t *testing.T) {
+	sim := newTestSim(t)
+	sim.imageGen = &stubImageGen{}
+	ctx := context.Background()
+	for i := 0; i <= maxMemoryImages; i++ {
+		if _, err := sim.eventImage(ctx, &GameEvent{ID: fmt.Sprintf("evt_%d", i)}, 800, 450); err != nil {
+			t.Fatalf("eventImage failed: %v", err)
+		}
+	}
+	if len(sim.images) != maxMemoryImages || len(sim.imageOrder) != maxMemoryImages {
+		t.Errorf("Expected %d cached images, got %d (%d ordered)", maxMemoryImages, len(sim.images), len(sim.imageOrder))
+	}
+	if sim.turnImage("evt_0") != "" {
+		t.Errorf("Expected the oldest image to be evicted")
+	}
+	if sim.turnImage(fmt.Sprintf("evt_%d", maxMemoryImages)) == "" {
+		t.Errorf("Expected the newest image to stay cached")
+	}
+}
+
+func TestMemorySavesAreCapped(t *testing.T) {
+	sim := newTestSim(t)
+	ctx := context.Background()
+	var tokens []string
+	for i := 0; i <= maxMemorySaves; i++ {
+		token, err := sim.SaveState(ctx)
+		if err != nil {
+			t.Fatalf("SaveState failed: %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if len(sim.saves) != maxMemorySaves {
+		t.Errorf("Expected %d save slots, got %d", maxMemorySaves, len(sim.saves))
+	}
+	if err := sim.LoadState(ctx, tokens[0]); err != errSaveNotFound {
+		t.Errorf("Expected the oldest save to be evicted, got %v", err)
+	}
+	if err := sim.LoadState(ctx, tokens[len(tokens)-1]); err != nil {
+		t.Errorf("Expected the newest save to load, got %v", err)
+	}
+}
+
+// TestCurrentTurnCopyOnWrite tests that image updates install a new current turn rather than
+// writing into one a reader may hold, and that state writers and readers can overlap (run with -race)
+func TestCurrentTurnCopyOnWrite(t *testing.T) {
+	sim := newTestSim(t)
+	orchestrator := NewGameOrchestrator(sim)
+	held := &TurnResult{Turn: 1, Event: GameEvent{ID: "evt_1", Title: "Port strike"}}
+	sim.state.CurrentTurn = held
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			sim.setEventImage("evt_1", fmt.Sprintf("https://img.test/%d.png", i))
+		}(i)
+		go func() {
+			defer wg.Done()
+			sim.countUsage(func(s *AIUsageStats) { s.AdvisorTheta++ })
+		}()
+		go func() {
+			defer wg.Done()
+			st := sim.snapshotState()
+			_ = fmt.Sprint(st.CurrentTurn.Event.ImageURL, orchestrator.IsGameComplete(), sim.usageStats())
+		}()
+	}
+	wg.Wait()
+
+	if held.Event.ImageURL != "" {
+		t.Errorf("Expected the held turn to stay unchanged, got image %q", held.Event.ImageURL)
+	}
+	if got := sim.currentTurn(); got == held || !strings.HasPrefix(got.Event.ImageURL, "https://img.test/") {
+		t.Errorf("Expected a new current turn carrying the image, got %+v", got.Event)
+	}
+	sim.setEventImage("evt_other", "https://img.test/stale.png")
+	if got := sim.currentTurn().Event.ImageURL; got == "https://img.test/stale.png" {
+		t.Error("Expected an image for another event to be ignored")
+	}
+	if got := sim.usageStats().AdvisorTheta; got != 4 {
+		t.Errorf("Expected 4 counted advisor calls, got %d", got)
+	}
+}
@@ -53,10 +53,19 @@ type CompleteResp struct {
 	Choices []struct{ Text string `json:"text"` } `json:"choices"`
 }
 
+// DefaultSystemPrompt is the system message Complete sends
+const DefaultSystemPrompt = "You are a helpful assistant"
+
 func (c *Client) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.CompleteWithSystem(ctx, "", prompt)
+}
+
+// CompleteWithSystem is Complete with a caller-chosen system message; an empty one uses DefaultSystemPrompt
+func (c *Client) CompleteWithSystem(ctx context.Context, systemPrompt, prompt string) (string, error) {
+	if systemPrompt == "" { systemPrompt = DefaultSystemPrompt }
 	payload := CompleteReq{Input: LlamaInput{
 		MaxTokens:   500,
-		Messages:    []LlamaMessage{{Role: "system", Content: "You are a helpful assistant"}, {Role: "user", Content: prompt}},
+		Messages:    []LlamaMessage{{Role: "system", Content: systemPrompt}, {Role: "user", Content: prompt}},
 		Stream:      false,
 		Temperature: 0.5,
 		TopP:        0.7,
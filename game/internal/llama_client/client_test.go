@@ -0,0 +1,69 @@
+package llama_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newChatServer decodes each request into got and answers with a fixed completion
+func newChatServer(t *testing.T, got *CompleteReq) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"text":"Hold the line."}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCompleteWithSystem(t *testing.T) {
+	cases := []struct {
+		name   string
+		system string
+		want   string
+	}{
+		{"custom", "You are the chief of staff", "You are the chief of staff"},
+		{"empty", "", DefaultSystemPrompt},
+	}
+	for _, tc := range cases {
+		var got CompleteReq
+		srv := newChatServer(t, &got)
+		c := &Client{BaseURL: srv.URL, HTTP: &http.Client{Timeout: 5 * time.Second}, APIKey: "test_key"}
+		text, err := c.CompleteWithSystem(context.Background(), tc.system, "Should we raise tariffs?")
+		if err != nil {
+			t.Fatalf("%s: CompleteWithSystem failed: %v", tc.name, err)
+		}
+		if text != "Hold the line." {
+			t.Errorf("%s: Expected the completion text, got %q", tc.name, text)
+		}
+		msgs := got.Input.Messages
+		if len(msgs) != 2 || msgs[0].Role != "system" || msgs[1].Role != "user" {
+			t.Fatalf("%s: Expected a system and a user message, got %+v", tc.name, msgs)
+		}
+		if msgs[0].Content != tc.want {
+			t.Errorf("%s: Expected system message %q, got %q", tc.name, tc.want, msgs[0].Content)
+		}
+		if msgs[1].Content != "Should we raise tariffs?" {
+			t.Errorf("%s: Expected the prompt as the user message, got %q", tc.name, msgs[1].Content)
+		}
+	}
+}
+
+func TestCompleteUsesDefaultSystemPrompt(t *testing.T) {
+	var got CompleteReq
+	srv := newChatServer(t, &got)
+	c := &Client{BaseURL: srv.URL, HTTP: &http.Client{Timeout: 5 * time.Second}}
+	if _, err := c.Complete(context.Background(), "Brief me"); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if msgs := got.Input.Messages; len(msgs) == 0 || msgs[0].Content != DefaultSystemPrompt {
+		t.Errorf("Expected Complete to send DefaultSystemPrompt, got %+v", msgs)
+	}
+}
@@ -8,25 +8,41 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/color"
 	_ "image/jpeg"
 	_ "image/png"
 	"io"
 	"math/rand"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/chai2010/webp"
+	"github.com/emergent-world-engine/backend/pkg/textutil"
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/option"
 )
 
 type Client struct {
-	BaseURL string
-	HTTP    *http.Client
-	APIKey  string
+	BaseURL  string
+	HTTP     *http.Client
+	APIKey   string
+	Attempts int           // Flux attempts before falling back to Gemini (at least 1)
+	Backoff  time.Duration // wait before the second attempt, doubling after each retry
+	MaxImageBytes int      // cap on inline (data URL) image payloads; larger images are re-encoded smaller
 }
 
+const (
+	defaultTimeout  = 40 * time.Second
+	defaultAttempts = 3
+	defaultBackoff  = 500 * time.Millisecond
+	defaultMaxImageBytes = 1 << 20
+)
+
+// New builds a client from the environment. ON_DEMAND_IMAGE_TIMEOUT sets the per-attempt HTTP
+// timeout (a Go duration such as "90s", or whole seconds) and ON_DEMAND_IMAGE_RETRIES the number
+// of Flux attempts. ON_DEMAND_IMAGE_MAX_BYTES caps inline data-URL images (default 1 MiB).
 func New() *Client {
 	base := os.Getenv("ON_DEMAND_FLUX_URL")
 	if base == "" {
@@ -36,15 +52,31 @@ func New() *Client {
 	if key == "" {
 		key = os.Getenv("THETA_API_KEY")
 	}
-	return &Client{BaseURL: base, HTTP: &http.Client{Timeout: 40 * time.Second}, APIKey: key}
+	timeout := defaultTimeout
+	if v := os.Getenv("ON_DEMAND_IMAGE_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			timeout = d
+		} else if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			timeout = time.Duration(secs) * time.Second
+		}
+	}
+	attempts := defaultAttempts
+	if v := os.Getenv("ON_DEMAND_IMAGE_RETRIES"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil && i > 0 {
+			attempts = i
+		}
+	}
+	maxBytes := defaultMaxImageBytes
+	if v := os.Getenv("ON_DEMAND_IMAGE_MAX_BYTES"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil && i > 0 {
+			maxBytes = i
+		}
+	}
+	return &Client{BaseURL: base, HTTP: &http.Client{Timeout: timeout}, APIKey: key, Attempts: attempts, Backoff: defaultBackoff, MaxImageBytes: maxBytes}
 }
 
 // debug helpers
 func imgDebug() bool { return true }
-func snip(b []byte, n int) string {
-	if len(b) <= n { return string(b) }
-	return string(b[:n]) + "..."
-}
 
 type fluxInput struct {
 	Guidance            float64 `json:"guidance,omitempty"`
@@ -173,19 +205,7 @@ func (c *Client) googleGeminiImageGenerateClient(ctx context.Context, prompt str
 		switch p := part.(type) {
 		case *genai.Blob:
 			if imgDebug() { fmt.Printf("[GEMINI-IMG] blob mime=%s bytes=%d\n", p.MIMEType, len(p.Data)) }
-			// Try to convert to WebP
-			img, _, decErr := image.Decode(bytes.NewReader(p.Data))
-			if decErr == nil {
-				buf := new(bytes.Buffer)
-				if err := webp.Encode(buf, img, &webp.Options{Quality: 80}); err == nil {
-					if imgDebug() { fmt.Println("[GEMINI-IMG] converted to webp") }
-					return "data:image/webp;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
-				}
-			}
-			// Fallback to original bytes as data URL
-			mime := p.MIMEType
-			if mime == "" { mime = "image/png" }
-			return fmt.Sprintf("data:%s;base64,%s", mime, base64.StdEncoding.EncodeToString(p.Data)), nil
+			return c.imageDataURL(p.Data, p.MIMEType)
 		default:
 			// ignore non-blob parts
 		}
@@ -237,7 +257,7 @@ func (c *Client) googleGeminiImageGenerate(ctx context.Context, prompt string) (
 	defer resp.Body.Close()
 	data, _ := io.ReadAll(io.LimitReader(resp.Body, 10<<20)) // up to ~10MB
 	if imgDebug() {
-		fmt.Printf("[GEMINI-IMG] http %d, body: %s\n", resp.StatusCode, snip(data, 1200))
+		fmt.Printf("[GEMINI-IMG] http %d, body: %s\n", resp.StatusCode, textutil.Snippet(string(data), 1200))
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return "", fmt.Errorf("gemini image http %d: %s", resp.StatusCode, string(data))
@@ -245,17 +265,17 @@ func (c *Client) googleGeminiImageGenerate(ctx context.Context, prompt string) (
 	// Parse generateContent inline_data shape
 	var gr genContentResp
 	if json.Unmarshal(data, &gr) == nil {
-		for _, c := range gr.Candidates {
-			for _, p := range c.Content.Parts {
+		for _, cand := range gr.Candidates {
+			for _, p := range cand.Content.Parts {
 				if p.InlineData.Data != "" {
-					mime := p.InlineData.Mime
-					if mime == "" {
-						mime = "image/png"
-					}
 					if imgDebug() {
 						fmt.Println("[GEMINI-IMG] parsed inline_data bytes")
 					}
-					return fmt.Sprintf("data:%s;base64,%s", mime, p.InlineData.Data), nil
+					raw, err := base64.StdEncoding.DecodeString(p.InlineData.Data)
+					if err != nil {
+						return "", fmt.Errorf("gemini image inline data: %w", err)
+					}
+					return c.imageDataURL(raw, p.InlineData.Mime)
 				}
 			}
 		}
@@ -279,17 +299,9 @@ func (c *Client) Generate(ctx context.Context, prompt string, width, height int)
 	seed := fmt.Sprintf("%d", rand.Int63())
 	payload := fluxReq{Input: fluxInput{Prompt: prompt, Width: width, Height: height, Guidance: 3.5, NumSteps: 4, Seed: seed}, Wait: 6}
 	b, _ := json.Marshal(payload)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL, bytes.NewReader(b))
-	if err != nil { return "", err }
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTP.Do(req)
-	if err != nil { return "", err }
-	defer resp.Body.Close()
-	data, _ := io.ReadAll(io.LimitReader(resp.Body, 65536))
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		if imgDebug() { fmt.Printf("[FLUX] http %d: %s\n", resp.StatusCode, snip(data, 600)) }
+	data, fluxErr := c.fluxWithRetry(ctx, b)
+	if fluxErr != nil {
+		if ctx.Err() != nil { return "", ctx.Err() }
 		// Flux error -> try Google Gemini fallback (Go client)
 		if url, err2 := c.googleGeminiImageGenerateClient(ctx, prompt); err2 == nil {
 			if imgDebug() { fmt.Println("[IMAGE] Fallback to Gemini succeeded") }
@@ -297,7 +309,7 @@ func (c *Client) Generate(ctx context.Context, prompt string, width, height int)
 		} else {
 			if imgDebug() { fmt.Printf("[IMAGE] Gemini fallback failed: %v\n", err2) }
 		}
-		return "", fmt.Errorf("flux http %d: %s", resp.StatusCode, string(data))
+		return "", fluxErr
 	}
 	if url := extractImageURL(data); url != "" {
 		if imgDebug() { fmt.Println("[FLUX] parsed image url from response") }
@@ -312,3 +324,109 @@ func (c *Client) Generate(ctx context.Context, prompt string, width, height int)
 	}
 	return "", fmt.Errorf("flux response has no image url: %s", string(data))
 }
+
+// fluxWithRetry posts the Flux request, retrying network errors, 429s and 5xx responses with
+// exponential backoff. The context is checked before every attempt and during each wait.
+func (c *Client) fluxWithRetry(ctx context.Context, body []byte) ([]byte, error) {
+	attempts := max(c.Attempts, 1)
+	backoff := c.Backoff
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			backoff *= 2
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		status, data, err := c.fluxAttempt(ctx, body)
+		switch {
+		case err != nil:
+			lastErr = err
+		case status >= 200 && status < 300:
+			return data, nil
+		default:
+			if imgDebug() { fmt.Printf("[FLUX] http %d: %s\n", status, textutil.Snippet(string(data), 600)) }
+			lastErr = fmt.Errorf("flux http %d: %s", status, string(data))
+			if status != http.StatusTooManyRequests && status < 500 {
+				return nil, lastErr
+			}
+		}
+		if imgDebug() && i < attempts-1 { fmt.Printf("[FLUX] attempt %d/%d failed, retrying: %v\n", i+1, attempts, lastErr) }
+	}
+	return nil, lastErr
+}
+
+func (c *Client) fluxAttempt(ctx context.Context, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL, bytes.NewReader(body))
+	if err != nil { return 0, nil, err }
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
+	resp, err := c.HTTP.Do(req)
+	if err != nil { return 0, nil, err }
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 65536))
+	return resp.StatusCode, data, nil
+}
+
+// imageDataURL encodes raw image bytes as a data URL no larger than MaxImageBytes (decoded).
+// Decodable images are converted to WebP, lowering quality and then halving the dimensions
+// until they fit; undecodable payloads are passed through only if already within the limit.
+func (c *Client) imageDataURL(data []byte, mime string) (string, error) {
+	maxBytes := c.MaxImageBytes
+	if maxBytes <= 0 { maxBytes = defaultMaxImageBytes }
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		if len(data) > maxBytes {
+			return "", fmt.Errorf("image is %d bytes, over the %d byte limit, and could not be decoded to shrink it: %w", len(data), maxBytes, err)
+		}
+		if mime == "" { mime = "image/png" }
+		return fmt.Sprintf("data:%s;base64,%s", mime, base64.StdEncoding.EncodeToString(data)), nil
+	}
+	encoded, err := fitWebP(img, maxBytes)
+	if err != nil { return "", err }
+	if imgDebug() { fmt.Printf("[GEMINI-IMG] converted to webp (%d bytes)\n", len(encoded)) }
+	return "data:image/webp;base64," + base64.StdEncoding.EncodeToString(encoded), nil
+}
+
+// fitWebP encodes img as WebP within maxBytes, trying lower qualities before downscaling
+func fitWebP(img image.Image, maxBytes int) ([]byte, error) {
+	for {
+		for _, quality := range []float32{80, 60, 40} {
+			buf := new(bytes.Buffer)
+			if err := webp.Encode(buf, img, &webp.Options{Quality: quality}); err != nil {
+				return nil, fmt.Errorf("webp encode: %w", err)
+			}
+			if buf.Len() <= maxBytes {
+				return buf.Bytes(), nil
+			}
+		}
+		b := img.Bounds()
+		if b.Dx() <= 16 || b.Dy() <= 16 {
+			return nil, fmt.Errorf("image cannot be reduced below %d bytes", maxBytes)
+		}
+		img = downscaleHalf(img)
+	}
+}
+
+// downscaleHalf halves both dimensions by averaging each 2x2 block
+func downscaleHalf(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx()/2, b.Dy()/2))
+	for y := 0; y < dst.Bounds().Dy(); y++ {
+		for x := 0; x < dst.Bounds().Dx(); x++ {
+			var r, g, bl, a uint32
+			for _, d := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				cr, cg, cb, ca := src.At(b.Min.X+2*x+d[0], b.Min.Y+2*y+d[1]).RGBA()
+				r, g, bl, a = r+cr, g+cg, bl+cb, a+ca
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / 4), G: uint16(g / 4), B: uint16(bl / 4), A: uint16(a / 4)})
+		}
+	}
+	return dst
+}
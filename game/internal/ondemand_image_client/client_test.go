@@ -0,0 +1,118 @@
+package ondemand_image_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFluxServer answers with 500 for the first failures requests and an image URL afterwards
+func newFluxServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			http.Error(w, "flux unavailable", status)
+			return
+		}
+		w.Write([]byte(`{"status":"success","body":{"infer_requests":[{"output":{"image_url":"https://img.test/flux.png"}}]}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func newTestClient(url string) *Client {
+	return &Client{BaseURL: url, HTTP: &http.Client{Timeout: 5 * time.Second}, APIKey: "test_key", Attempts: 3, Backoff: time.Millisecond}
+}
+
+func TestFluxRetriesServerErrors(t *testing.T) {
+	srv, calls := newFluxServer(t, 2, http.StatusInternalServerError)
+	url, err := newTestClient(srv.URL).Generate(context.Background(), "a press briefing", 512, 512)
+	if err != nil {
+		t.Fatalf("Expected the third attempt to succeed, got %v", err)
+	}
+	if url != "https://img.test/flux.png" {
+		t.Errorf("Expected the Flux image URL, got %q", url)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Expected 3 Flux calls, got %d", got)
+	}
+}
+
+func TestFluxRetryLimits(t *testing.T) {
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+
+	srv, calls := newFluxServer(t, 3, http.StatusServiceUnavailable)
+	if _, err := newTestClient(srv.URL).Generate(context.Background(), "a press briefing", 512, 512); err == nil {
+		t.Error("Expected an error once every attempt failed")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Expected Attempts to bound the calls at 3, got %d", got)
+	}
+
+	srv, calls = newFluxServer(t, 1, http.StatusBadRequest)
+	if _, err := newTestClient(srv.URL).Generate(context.Background(), "a press briefing", 512, 512); err == nil {
+		t.Error("Expected a 400 to fail")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected a 400 not to be retried, got %d calls", got)
+	}
+}
+
+func TestFluxRetryStopsOnCancel(t *testing.T) {
+	srv, calls := newFluxServer(t, 3, http.StatusInternalServerError)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := newTestClient(srv.URL).fluxWithRetry(ctx, []byte(`{}`)); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if got := calls.Load(); got != 0 {
+		t.Errorf("Expected no Flux calls after cancellation, got %d", got)
+	}
+}
+
+func TestImageDataURLShrinksOversizedImages(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	rng := rand.New(rand.NewSource(1))
+	for i := range img.Pix {
+		img.Pix[i] = byte(rng.Intn(256))
+	}
+	var raw bytes.Buffer
+	if err := png.Encode(&raw, img); err != nil {
+		t.Fatalf("Failed to encode test PNG: %v", err)
+	}
+	c := &Client{MaxImageBytes: 8 << 10}
+	if raw.Len() <= c.MaxImageBytes {
+		t.Fatalf("Expected the noisy PNG to exceed the limit, got %d bytes", raw.Len())
+	}
+
+	url, err := c.imageDataURL(raw.Bytes(), "image/png")
+	if err != nil {
+		t.Fatalf("Expected the image to be re-encoded, got %v", err)
+	}
+	const prefix = "data:image/webp;base64,"
+	if !strings.HasPrefix(url, prefix) {
+		t.Fatalf("Expected a WebP data URL, got %.40q", url)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(url, prefix))
+	if err != nil {
+		t.Fatalf("Failed to decode data URL: %v", err)
+	}
+	if len(data) == 0 || len(data) > c.MaxImageBytes {
+		t.Errorf("Expected 1-%d bytes after re-encoding, got %d", c.MaxImageBytes, len(data))
+	}
+
+	if _, err := c.imageDataURL(bytes.Repeat([]byte("x"), c.MaxImageBytes+1), "image/png"); err == nil {
+		t.Error("Expected an oversized undecodable payload to be rejected")
+	}
+}
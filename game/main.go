@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 )
 
 func main() {
@@ -20,7 +22,12 @@ func main() {
 	orchestrator := NewGameOrchestrator(sim)
 	if len(os.Args) > 1 && os.Args[1] == "web" {
 		port := "8080"; if len(os.Args) > 2 { port = os.Args[2] }
-		server := NewWebServer(orchestrator, port); log.Fatal(server.Start()); return }
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		// Returning (rather than log.Fatal) lets the deferred sim.Close run after requests drain
+		server := NewWebServer(orchestrator, port)
+		if err := server.Run(ctx); err != nil { log.Printf("server error: %v", err) }
+		return }
 	runTerminalMode(orchestrator)
 }
 
@@ -38,7 +45,7 @@ func runTerminalMode(orchestrator *GameOrchestrator) {
 	for !orchestrator.IsGameComplete() {
 		ctx := context.Background()
 		
-		fmt.Printf("\n" + strings.Repeat("=", 60))
+		fmt.Print("\n" + strings.Repeat("=", 60))
 		fmt.Printf("\n🏛️  TURN %d of %d", orchestrator.sim.state.Turn, orchestrator.sim.state.MaxTurns)
 		fmt.Printf("\n%s\n", strings.Repeat("=", 60)) // ensure a newline after the separator
 		
@@ -84,8 +91,9 @@ func runTerminalMode(orchestrator *GameOrchestrator) {
 	fmt.Println("🏁 PRESIDENCY COMPLETED!")
 	fmt.Println(strings.Repeat("=", 60))
 	
-	finalScore := calculateFinalScore(orchestrator.sim.state.Metrics)
-	fmt.Printf("📊 Final Score: %.1f/100\n", finalScore)
+	weights := orchestrator.sim.scoreWeights()
+	finalScore := calculateFinalScore(orchestrator.sim.state.Metrics, weights)
+	fmt.Printf("📊 Final Score: %.1f/100 (%s)\n", finalScore, formatScoreWeights(weights))
 	
 	if finalScore > 70 {
 		fmt.Println("🎉 Excellent presidency! You've led the nation with distinction.")
@@ -117,7 +125,16 @@ func displayImpact(impact WorldMetrics) {
 	fmt.Printf("   ⚖️  Stability: %+.1f\n", impact.Stability)
 }
 
-func calculateFinalScore(metrics WorldMetrics) float64 {
-	return (metrics.Economy + metrics.Security + metrics.Diplomacy + 
-		   metrics.Environment + metrics.Approval + metrics.Stability) / 6.0
+// calculateFinalScore is the weighted average of the six metrics (weights are normalized)
+func calculateFinalScore(metrics WorldMetrics, weights WorldMetricsWeights) float64 {
+	w := weights.Normalized()
+	return metrics.Economy*w.Economy + metrics.Security*w.Security + metrics.Diplomacy*w.Diplomacy +
+		metrics.Environment*w.Environment + metrics.Approval*w.Approval + metrics.Stability*w.Stability
+}
+
+// formatScoreWeights describes the normalized weights, e.g. "weights: Economy 25% | Security 15% | ..."
+func formatScoreWeights(weights WorldMetricsWeights) string {
+	w := weights.Normalized()
+	return fmt.Sprintf("weights: Economy %.0f%% | Security %.0f%% | Diplomacy %.0f%% | Environment %.0f%% | Approval %.0f%% | Stability %.0f%%",
+		w.Economy*100, w.Security*100, w.Diplomacy*100, w.Environment*100, w.Approval*100, w.Stability*100)
 }
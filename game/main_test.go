@@ -0,0 +1,26 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateFinalScore(t *testing.T) {
+	m := WorldMetrics{Economy: 80, Security: 20, Diplomacy: 55, Environment: 35, Approval: 90, Stability: 10}
+	mean := (m.Economy + m.Security + m.Diplomacy + m.Environment + m.Approval + m.Stability) / 6
+
+	if got := calculateFinalScore(m, equalWeights()); math.Abs(got-mean) > 1e-9 {
+		t.Errorf("Expected equal weights to give the plain mean %.4f, got %.4f", mean, got)
+	}
+	if got := calculateFinalScore(m, (&PresidentSim{}).scoreWeights()); math.Abs(got-mean) > 1e-9 {
+		t.Errorf("Expected a simulator without config to score the plain mean %.4f, got %.4f", mean, got)
+	}
+	if got := calculateFinalScore(m, WorldMetricsWeights{}); math.Abs(got-mean) > 1e-9 {
+		t.Errorf("Expected all-zero weights to fall back to the plain mean %.4f, got %.4f", mean, got)
+	}
+
+	weighted := parseScoreWeights("economy=3,security=0,diplomacy=0,environment=0,approval=1,stability=0", equalWeights())
+	if got, want := calculateFinalScore(m, weighted), (3*m.Economy+m.Approval)/4; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Expected weighted score %.4f, got %.4f", want, got)
+	}
+}
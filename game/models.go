@@ -29,7 +29,7 @@ type AdvisorResponse struct {
 	Name           string `json:"name,omitempty"`
 	Title          string `json:"title,omitempty"`
 	Advice         string `json:"advice"`
-	Recommendation int    `json:"recommendation"` // Which option they recommend (0-based index)
+	Conviction     int    `json:"conviction"` // how strongly the advisor stands behind the advice, 0-10
 }
 
 // PlayerChoice represents the player's decision
@@ -50,6 +50,34 @@ type WorldMetrics struct {
 	Stability   float64 `json:"stability"`  // -100 to 100
 }
 
+// WorldMetricsWeights sets how much each metric counts toward the final score
+type WorldMetricsWeights struct {
+	Economy     float64 `json:"economy"`
+	Security    float64 `json:"security"`
+	Diplomacy   float64 `json:"diplomacy"`
+	Environment float64 `json:"environment"`
+	Approval    float64 `json:"approval"`
+	Stability   float64 `json:"stability"`
+}
+
+// equalWeights counts every metric the same
+func equalWeights() WorldMetricsWeights {
+	return WorldMetricsWeights{Economy: 1, Security: 1, Diplomacy: 1, Environment: 1, Approval: 1, Stability: 1}
+}
+
+// Normalized scales the weights to sum to 1; negative weights count as 0 and an all-zero set falls back to equal weights
+func (w WorldMetricsWeights) Normalized() WorldMetricsWeights {
+	vals := []*float64{&w.Economy, &w.Security, &w.Diplomacy, &w.Environment, &w.Approval, &w.Stability}
+	total := 0.0
+	for _, v := range vals {
+		if *v < 0 { *v = 0 }
+		total += *v
+	}
+	if total <= 0 { return equalWeights().Normalized() }
+	for _, v := range vals { *v /= total }
+	return w
+}
+
 // TurnResult represents the outcome of a turn
 type TurnResult struct {
 	Turn       int           `json:"turn"`
@@ -67,6 +95,12 @@ type AIUsageStats struct {
 	DirectorTheta  int `json:"directorTheta"`
 	DirectorGemini int `json:"directorGemini"`
 	RewriteGemini  int `json:"rewriteGemini"`
+
+	// Theta LLM tokens spent this session and their estimated cost (PRES_SIM_MODEL_COSTS)
+	PromptTokens     int64   `json:"promptTokens"`
+	CompletionTokens int64   `json:"completionTokens"`
+	TotalTokens      int64   `json:"totalTokens"`
+	EstimatedCostUSD float64 `json:"estimatedCostUsd"`
 }
 
 // GameState holds the current game state
@@ -77,6 +111,8 @@ type GameState struct {
 	History     []TurnResult `json:"history"`
 	Advisors    []Advisor    `json:"advisors"`
 	CurrentTurn *TurnResult  `json:"currentTurn,omitempty"`
+	Rerolls     int          `json:"rerolls"` // rerolls used on the current turn
 	LastUpdated time.Time    `json:"lastUpdated"`
 	Stats       AIUsageStats `json:"stats"`
+	Newspaper   string       `json:"newspaper,omitempty"` // endgame newspaper, cached once generated
 }
@@ -6,28 +6,81 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"math/rand"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	fw "github.com/emergent-world-engine/backend/pkg/framework"
-	gemini "presidential-simulator/internal/gemini_client"
+	"github.com/emergent-world-engine/backend/pkg/jsonextract"
+	"github.com/emergent-world-engine/backend/pkg/textutil"
 	llama "presidential-simulator/internal/llama_client"
 )
 
 // GameOrchestrator manages the 5-turn chat game flow
 type GameOrchestrator struct {
 	sim *PresidentSim
+
+	mu         sync.Mutex
+	cancelTurn context.CancelFunc // cancels advisor/image work still running for the current event
+
+	newspaperMu sync.Mutex // serializes endgame newspaper generation so it runs once per game
 }
 
+var (
+	errNoActiveTurn = errors.New("no active turn")
+	errRerollLimit  = errors.New("reroll limit reached for this turn")
+	errSuperseded   = errors.New("turn superseded while generating")
+)
+
 func NewGameOrchestrator(sim *PresidentSim) *GameOrchestrator { return &GameOrchestrator{sim: sim} }
 
+// beginTurnWork cancels background work belonging to the previous event and returns a fresh
+// context that outlives the request, for goroutines (e.g. image generation) tied to the new event.
+func (g *GameOrchestrator) beginTurnWork() context.Context {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cancelTurn != nil { g.cancelTurn() }
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancelTurn = cancel
+	return ctx
+}
+
+// TurnUpdate is one step of a turn as it is generated: the event first, then each advisor's
+// response as soon as it resolves. Exactly one field is set.
+type TurnUpdate struct {
+	Event   *GameEvent
+	Advisor *AdvisorResponse
+}
+
 // StartNewTurn begins a new turn in the game
 func (g *GameOrchestrator) StartNewTurn(ctx context.Context) (*TurnResult, error) {
-	if g.sim.state.Turn > g.sim.state.MaxTurns {
-		return nil, fmt.Errorf("game completed after %d turns", g.sim.state.MaxTurns)
+	return g.StartNewTurnStream(ctx, nil)
+}
+
+// StartNewTurnStream is StartNewTurn that also sends each step of the turn on updates as it
+// completes. updates may be nil; otherwise the caller must keep receiving until this returns.
+func (g *GameOrchestrator) StartNewTurnStream(ctx context.Context, updates chan<- TurnUpdate) (*TurnResult, error) {
+	if g.IsGameComplete() {
+		return nil, fmt.Errorf("game completed after %d turns", g.sim.snapshotState().MaxTurns)
+	}
+
+	// Advisor calls stop when either the request ends or this event is discarded (reroll)
+	turnCtx := g.beginTurnWork()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(turnCtx, cancel)()
+	emit := func(u TurnUpdate) {
+		if updates == nil { return }
+		select {
+		case updates <- u:
+		case <-ctx.Done():
+		}
 	}
 
 	// Generate random event (later could integrate Narrative quests or Director generated events)
@@ -39,8 +92,13 @@ func (g *GameOrchestrator) StartNewTurn(ctx context.Context) (*TurnResult, error
 	event.Title = sanitizeEventText(event.Title)
 	event.Description = sanitizeEventText(event.Description)
 
-	// Select 3 random advisors
-	selectedAdvisors := g.selectRandomAdvisors(3)
+	// Kick off async image generation on its own copy of the event
+	evt := *event
+	go g.sim.enqueueEventImage(turnCtx, evt)
+	emit(TurnUpdate{Event: &evt})
+
+	// Select the configured number of random advisors
+	selectedAdvisors := g.selectRandomAdvisors(g.sim.advisorsPerTurn())
 
 	// Get advice from each selected advisor in parallel
 	advisorResponses := make([]AdvisorResponse, 0, len(selectedAdvisors))
@@ -58,54 +116,95 @@ func (g *GameOrchestrator) StartNewTurn(ctx context.Context) (*TurnResult, error
 			if err != nil {
 				log.Printf("[ADVISOR] %s error: %v (using fallback)", ad.Name, err)
 				fb := synthFallbackAdvice(ad)
-				resp = AdvisorResponse{AdvisorID: ad.ID, AdvisorName: ad.Name, Title: ad.Title, Advice: fb, Recommendation: 0}
+				resp = AdvisorResponse{AdvisorID: ad.ID, AdvisorName: ad.Name, Title: ad.Title, Advice: fb, Conviction: neutralConviction}
 			}
 			mu.Lock(); advisorResponses = append(advisorResponses, resp); mu.Unlock()
+			emit(TurnUpdate{Advisor: &resp})
 		}()
 	}
 	wg.Wait()
 
 	turnResult := &TurnResult{
-		Turn:     g.sim.state.Turn,
 		Event:    *event,
 		Advisors: advisorResponses,
 	}
-	g.sim.state.CurrentTurn = turnResult
+	if turnCtx.Err() != nil {
+		return nil, errSuperseded
+	}
+	if url := g.sim.turnImage(event.ID); url != "" { turnResult.Event.ImageURL = url } // image finished before the advisors
+	// Publish a copy, so the caller may keep modifying the returned turn
+	g.sim.stateMu.Lock()
+	turnResult.Turn = g.sim.state.Turn
+	published := *turnResult
+	g.sim.state.CurrentTurn = &published
+	g.sim.stateMu.Unlock()
 	return turnResult, nil
 }
 
-// ProcessPlayerChoice handles the player's decision and evaluates the outcome
+// RerollEvent discards the current turn's event and advisor opinions and generates a fresh one
+// without advancing the turn counter. In-flight advisor/image work for the discarded event is cancelled.
+// At most GameConfig.MaxRerollsPerTurn rerolls are allowed per turn.
+func (g *GameOrchestrator) RerollEvent(ctx context.Context) error {
+	st := g.sim.snapshotState()
+	if st.Turn > st.MaxTurns {
+		return fmt.Errorf("game completed after %d turns", st.MaxTurns)
+	}
+	if st.CurrentTurn == nil {
+		return errNoActiveTurn
+	}
+	if st.Rerolls >= g.sim.maxRerollsPerTurn() {
+		return errRerollLimit
+	}
+	// CurrentTurn stays set while generating so the discarded topic is excluded from the new pick
+	if _, err := g.StartNewTurn(ctx); err != nil {
+		return fmt.Errorf("failed to reroll event: %w", err)
+	}
+	g.sim.stateMu.Lock()
+	g.sim.state.Rerolls++
+	g.sim.state.LastUpdated = time.Now()
+	g.sim.stateMu.Unlock()
+	return nil
+}
+
+// ProcessPlayerChoice handles the player's decision and evaluates the outcome. turnResult is
+// filled in with the choice, evaluation and impact once the turn is recorded; pass a copy of the
+// published current turn, never the turn itself.
 func (g *GameOrchestrator) ProcessPlayerChoice(ctx context.Context, turnResult *TurnResult, choiceIndex int, reasoning string) error {
+	result := *turnResult
 	// Ignore numeric choice; treat reasoning as the action narrative
-	turnResult.Choice = PlayerChoice{EventID: turnResult.Event.ID, OptionIndex: -1, Option: "policy_response", Reasoning: reasoning}
+	result.Choice = PlayerChoice{EventID: result.Event.ID, OptionIndex: -1, Option: "policy_response", Reasoning: reasoning}
 
 	// Evaluate via Director using reasoning text
-	turnResult.Event.Options = nil // remove options for downstream display
+	result.Event.Options = nil // remove options for downstream display
 
-	evaluation, impact, err := g.evaluateChoice(ctx, turnResult)
+	evaluation, impact, err := g.evaluateChoice(ctx, &result)
 	if err != nil {
 		return fmt.Errorf("failed to evaluate reasoning: %w", err)
 	}
 
-	turnResult.Evaluation = evaluation
-	turnResult.Impact = impact
+	impact = capImpact(impact, g.sim.maxDeltaPerTurn())
+	result.Evaluation = evaluation
+	result.Impact = impact
 
-	// Update world metrics
-	g.updateWorldMetrics(impact)
-
-	// Check defeat condition: any metric at or below zero -> game over
-	if metricTriggersGameOver(g.sim.state.Metrics) {
+	// Update world metrics and check the defeat condition
+	g.sim.stateMu.Lock()
+	defer g.sim.stateMu.Unlock()
+	*turnResult = result
+	next, gameOver := ApplyImpact(g.sim.state.Metrics, impact)
+	g.sim.state.Metrics = next
+	if gameOver {
 		// Mark game complete by advancing beyond MaxTurns
 		g.sim.state.Turn = g.sim.state.MaxTurns + 1
 	}
 
 	// Add to history and advance turn if not already completed
-	g.sim.state.History = append(g.sim.state.History, *turnResult)
-	if !g.IsGameComplete() {
+	g.sim.state.History = append(g.sim.state.History, result)
+	if g.sim.state.Turn <= g.sim.state.MaxTurns {
 		g.sim.state.Turn++
 	}
 	g.sim.state.LastUpdated = time.Now()
 	g.sim.state.CurrentTurn = nil
+	g.sim.state.Rerolls = 0
 	return nil
 }
 
@@ -113,13 +212,15 @@ func metricTriggersGameOver(m WorldMetrics) bool {
 	return m.Economy <= 0 || m.Security <= 0 || m.Diplomacy <= 0 || m.Environment <= 0 || m.Approval <= 0 || m.Stability <= 0
 }
 
-// selectRandomAdvisors picks 3 random advisors from the 8 available
+// selectRandomAdvisors picks count random advisors from the roster (all of them if count exceeds it)
 func (g *GameOrchestrator) selectRandomAdvisors(count int) []Advisor {
+	g.sim.stateMu.RLock()
 	advisors := make([]Advisor, len(g.sim.state.Advisors))
 	copy(advisors, g.sim.state.Advisors)
+	g.sim.stateMu.RUnlock()
 
 	// Shuffle advisors
-	rand.Shuffle(len(advisors), func(i, j int) {
+	g.sim.rng.Shuffle(len(advisors), func(i, j int) {
 		advisors[i], advisors[j] = advisors[j], advisors[i]
 	})
 
@@ -138,8 +239,31 @@ var (
 	advisorsHdrRE   = regexp.MustCompile(`(?m)^\s*💼\s*Your advisors weigh in:\s*$`)
 )
 
+// Advisor conviction scale: how strongly an advisor stands behind their advice
+const (
+	maxConviction     = 10
+	neutralConviction = 5 // used when the model gives no usable score
+)
+
+var convictionRE = regexp.MustCompile(`(?i)"conviction"\s*:\s*"?(-?\d+(?:\.\d+)?)`)
+
+// extractAdvisorConviction reads the 0-10 conviction score from the advisor's JSON, rounding
+// fractional scores and clamping to the scale; it is neutralConviction when absent or unparsable
+func extractAdvisorConviction(raw string) int {
+	m := convictionRE.FindAllStringSubmatch(raw, -1)
+	if len(m) == 0 { return neutralConviction }
+	v, err := strconv.ParseFloat(m[len(m)-1][1], 64)
+	if err != nil { return neutralConviction }
+	return clampConviction(v)
+}
+
+// clampConviction rounds a conviction score onto the 0-maxConviction scale
+func clampConviction(v float64) int {
+	return min(max(int(math.Round(v)), 0), maxConviction)
+}
+
 // extractAdvisorOpinion attempts layered extraction strategies
-func extractAdvisorOpinion(raw string) string {
+func extractAdvisorOpinion(raw string, maxSentences int) string {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
 		return ""
@@ -152,13 +276,13 @@ func extractAdvisorOpinion(raw string) string {
 			var m map[string]any
 			if json.Unmarshal([]byte(frag), &m) == nil {
 				if v, ok := m["advisor_opinion"].(string); ok {
-					return sanitizeOpinion(v)
+					return sanitizeOpinion(v, maxSentences)
 				}
 			}
 		}
 		// If advisor_opinion key is present but JSON is malformed, try regex extraction then fall through
 		if mm := regexp.MustCompile(`(?i)\"advisor_opinion\"\s*:\s*\"([^\"]+)\"`).FindStringSubmatch(raw); len(mm) > 1 {
-			return sanitizeOpinion(mm[1])
+			return sanitizeOpinion(mm[1], maxSentences)
 		}
 		// else continue to non-JSON strategies below
 	}
@@ -167,7 +291,7 @@ func extractAdvisorOpinion(raw string) string {
 	for _, ln := range lines {
 		low := strings.ToLower(ln)
 		if strings.Contains(low, "advisor opinion") || strings.Contains(low, "final advisory") {
-			return sanitizeOpinion(afterColon(ln))
+			return sanitizeOpinion(afterColon(ln), maxSentences)
 		}
 	}
 	// 3) Take first non-meta paragraph
@@ -180,7 +304,7 @@ func extractAdvisorOpinion(raw string) string {
 		if looksMeta(p2) {
 			continue
 		}
-		return sanitizeOpinion(p2)
+		return sanitizeOpinion(p2, maxSentences)
 	}
 	return ""
 }
@@ -208,7 +332,9 @@ func looksMeta(s string) bool {
 
 func splitParas(s string) []string { return strings.Split(s, "\n\n") }
 
-func sanitizeOpinion(s string) string {
+// sanitizeOpinion strips wrapping quotes and backticks, collapses whitespace and keeps the first
+// maxSentences sentences (all of them when maxSentences is 0)
+func sanitizeOpinion(s string, maxSentences int) string {
 	// Remove leading quotes/backticks
 	s = strings.TrimSpace(s)
 	s = strings.Trim(s, "`")
@@ -219,15 +345,70 @@ func sanitizeOpinion(s string) string {
 		return ""
 	}
 	out := strings.Join(fs, " ")
-	// Limit to ~3 sentences
-	sents := regexp.MustCompile(`([^.?!]*[.?!])`).FindAllString(out, -1)
-	if len(sents) > 0 {
-		if len(sents) > 3 {
-			sents = sents[:3]
+	if maxSentences <= 0 {
+		return out
+	}
+	sents := splitSentences(out)
+	if len(sents) > maxSentences {
+		sents = sents[:maxSentences]
+	}
+	return strings.Join(sents, " ")
+}
+
+// sentenceAbbrevs are words whose trailing period does not end a sentence (compared lowercased, without the period)
+var sentenceAbbrevs = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true, "st": true,
+	"gen": true, "sen": true, "rep": true, "gov": true, "pres": true, "amb": true, "lt": true,
+	"vs": true, "etc": true, "approx": true, "inc": true, "corp": true, "ltd": true, "dept": true,
+	"jan": true, "feb": true, "aug": true, "sept": true, "oct": true, "nov": true,
+}
+
+// titleAbbrevs are abbreviations only when title-cased ("Sec.", "Col.", "Dec."); in lowercase they
+// are more often ordinary words ending a sentence
+var titleAbbrevs = map[string]bool{"Sec": true, "Col": true, "Dec": true}
+
+// initialismRE matches dotted initialisms of two or more letters such as U.S., U.N. and e.g.
+var initialismRE = regexp.MustCompile(`^[A-Za-z]\.(?:[A-Za-z]\.)*[A-Za-z]\.?$`)
+
+// splitSentences splits whitespace-collapsed text into sentences. A sentence ends at '.', '?' or
+// '!' (with any closing quotes or brackets) followed by a space, unless the period belongs to an
+// abbreviation or initialism such as "Dr." or "U.S.", or the next word starts in lowercase.
+// Unterminated trailing text is kept as the last sentence.
+func splitSentences(s string) []string {
+	words := strings.Split(s, " ")
+	var sents []string
+	start := 0
+	for i, w := range words {
+		if i == len(words)-1 || !endsSentence(w, words[i+1]) {
+			continue
 		}
-		return strings.TrimSpace(strings.Join(sents, " "))
+		sents = append(sents, strings.Join(words[start:i+1], " "))
+		start = i + 1
 	}
-	return out
+	if start < len(words) {
+		sents = append(sents, strings.Join(words[start:], " "))
+	}
+	return sents
+}
+
+func endsSentence(word, next string) bool {
+	core := strings.TrimRight(word, "\"'”’)]")
+	if core == "" {
+		return false
+	}
+	switch core[len(core)-1] {
+	case '?', '!':
+	case '.':
+		bare := strings.TrimLeft(core, "\"'“‘([")
+		stem := strings.TrimSuffix(bare, ".")
+		if sentenceAbbrevs[strings.ToLower(stem)] || titleAbbrevs[stem] || initialismRE.MatchString(bare) {
+			return false
+		}
+	default:
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(strings.TrimLeft(next, "\"'“‘(["))
+	return !unicode.IsLower(first)
 }
 
 func sanitizeEventText(s string) string {
@@ -267,7 +448,8 @@ Description: %s
 Task: Provide one concise, actionable advisory opinion (policy recommendation or strategic action).
 Style and Voice: Address the President directly using second-person ("you", "your"). Use simple, everyday language (about 8th-grade reading level). Avoid jargon and buzzwords.
 Constraints: 1-2 short sentences. No internal reasoning, no preamble, no self-reference (avoid "I", "we").
-Output ONLY valid JSON: {"advisor_opinion":"<your concise advisory>"}
+Conviction: rate from 0 (tentative) to 10 (certain) how strongly you stand behind the advice.
+Output ONLY valid JSON: {"advisor_opinion":"<your concise advisory>","conviction":<0-10>}
 If unsure, still give best judgment.`,
 			persona, event.Title, event.Category, event.Severity, event.Description)
 	}
@@ -280,39 +462,42 @@ If unsure, still give best judgment.`,
 	defer cancel()
 	out, err := llama.New().Complete(cctx, prompt)
 	if err != nil {
-		log.Printf("[ADVISOR] %s llama endpoint error: %v; trying Gemini fallback", advisor.Name, err)
-		if adv, gerr := g.advisorOpinionViaGemini(ctx, advisor, event); gerr == nil && adv != "" {
-			g.sim.state.Stats.AdvisorGemini++
-			return AdvisorResponse{AdvisorID: advisor.ID, AdvisorName: advisor.Name, Title: advisor.Title, Advice: adv, Recommendation: 0}, nil
+		log.Printf("[ADVISOR] %s llama endpoint error: %v; trying the engine's providers", advisor.Name, err)
+		if adv, conviction, viaGemini, gerr := g.advisorOpinionViaEngine(ctx, advisor, event); gerr == nil && adv != "" {
+			g.sim.countUsage(func(s *AIUsageStats) {
+				if viaGemini { s.AdvisorGemini++ } else { s.AdvisorTheta++ }
+			})
+			return AdvisorResponse{AdvisorID: advisor.ID, AdvisorName: advisor.Name, Title: advisor.Title, Advice: adv, Conviction: conviction}, nil
 		}
 		fb := synthFallbackAdvice(advisor)
-		return AdvisorResponse{AdvisorID: advisor.ID, AdvisorName: advisor.Name, Title: advisor.Title, Advice: fb, Recommendation: 0}, nil
+		return AdvisorResponse{AdvisorID: advisor.ID, AdvisorName: advisor.Name, Title: advisor.Title, Advice: fb, Conviction: neutralConviction}, nil
 	}
 	raw := strings.TrimSpace(out)
 	usedTheta = true
 
-	final := extractAdvisorOpinion(raw)
+	final := extractAdvisorOpinion(raw, g.sim.maxOpinionSentences())
+	conviction := extractAdvisorConviction(raw)
 	if looksMetaLike(final) {
-		log.Printf("[ADVISOR] %s meta-like advisory rejected: %q", advisor.Name, snippet(final, 120))
+		log.Printf("[ADVISOR] %s meta-like advisory rejected: %q", advisor.Name, textutil.Snippet(final, 120))
 		final = ""
 	}
 	if final == "" {
-		if adv, gerr := g.advisorOpinionViaGemini(ctx, advisor, event); gerr == nil && adv != "" {
-			g.sim.state.Stats.AdvisorGemini++
-			log.Printf("[ADVISOR] %s using Gemini fallback", advisor.Name)
-			final = adv
-			usedTheta = false
+		if adv, gconv, viaGemini, gerr := g.advisorOpinionViaEngine(ctx, advisor, event); gerr == nil && adv != "" {
+			if viaGemini { g.sim.countUsage(func(s *AIUsageStats) { s.AdvisorGemini++ }) }
+			log.Printf("[ADVISOR] %s using the engine fallback (gemini=%v)", advisor.Name, viaGemini)
+			final, conviction = adv, gconv
+			usedTheta = !viaGemini
 		} else if gerr != nil {
-			log.Printf("[ADVISOR] %s Gemini fallback failed detail: %v", advisor.Name, gerr)
+			log.Printf("[ADVISOR] %s engine fallback failed detail: %v", advisor.Name, gerr)
 		}
 	}
 	if final == "" {
-		final = synthFallbackAdvice(advisor)
+		final, conviction = synthFallbackAdvice(advisor), neutralConviction
 		log.Printf("[ADVISOR] %s using hardcoded fallback advisory", advisor.Name)
 		if final == "" { return AdvisorResponse{}, errors.New("unable to derive advisor opinion") }
 	}
-	if usedTheta && final != "" { g.sim.state.Stats.AdvisorTheta++ }
-	return AdvisorResponse{AdvisorID: advisor.ID, AdvisorName: advisor.Name, Title: advisor.Title, Advice: final, Recommendation: 0}, nil
+	if usedTheta && final != "" { g.sim.countUsage(func(s *AIUsageStats) { s.AdvisorTheta++ }) }
+	return AdvisorResponse{AdvisorID: advisor.ID, AdvisorName: advisor.Name, Title: advisor.Title, Advice: final, Conviction: conviction}, nil
 }
 
 var badCharsRE = regexp.MustCompile(`[{}\[\]<>()]`)
@@ -329,17 +514,17 @@ func (g *GameOrchestrator) randomEval(t *TurnResult) string {
 }
 func (g *GameOrchestrator) randomImpact() WorldMetrics {
 	return WorldMetrics{
-		Economy:     (rand.Float64() - 0.5) * 20,
-		Security:    (rand.Float64() - 0.5) * 20,
-		Diplomacy:   (rand.Float64() - 0.5) * 20,
-		Environment: (rand.Float64() - 0.5) * 20,
-		Approval:    (rand.Float64() - 0.5) * 10,
-		Stability:   (rand.Float64() - 0.5) * 10,
+		Economy:     (g.sim.rng.Float64() - 0.5) * 20,
+		Security:    (g.sim.rng.Float64() - 0.5) * 20,
+		Diplomacy:   (g.sim.rng.Float64() - 0.5) * 20,
+		Environment: (g.sim.rng.Float64() - 0.5) * 20,
+		Approval:    (g.sim.rng.Float64() - 0.5) * 10,
+		Stability:   (g.sim.rng.Float64() - 0.5) * 10,
 	}
 }
 
 func mapDirectorDecisionToMetrics(decision *fw.DirectorDecision, category string) WorldMetrics {
-	text := decision.Reasoning
+	text := decision.Raw
 	// Find last JSON object (metrics expected at end)
 	start := strings.LastIndex(text, "{")
 	end := strings.LastIndex(text, "}")
@@ -389,13 +574,38 @@ func mapDirectorDecisionToMetrics(decision *fw.DirectorDecision, category string
 	return WorldMetrics{}
 }
 
-func (g *GameOrchestrator) updateWorldMetrics(impact WorldMetrics) {
-	g.sim.state.Metrics.Economy = clamp(g.sim.state.Metrics.Economy+impact.Economy, -100, 100)
-	g.sim.state.Metrics.Security = clamp(g.sim.state.Metrics.Security+impact.Security, -100, 100)
-	g.sim.state.Metrics.Diplomacy = clamp(g.sim.state.Metrics.Diplomacy+impact.Diplomacy, -100, 100)
-	g.sim.state.Metrics.Environment = clamp(g.sim.state.Metrics.Environment+impact.Environment, -100, 100)
-	g.sim.state.Metrics.Approval = clamp(g.sim.state.Metrics.Approval+impact.Approval, -100, 100)
-	g.sim.state.Metrics.Stability = clamp(g.sim.state.Metrics.Stability+impact.Stability, -100, 100)
+// ApplyImpact adds impact to current, clamping each metric to [-100, 100], and reports
+// whether the result ends the game (any metric at or below zero). It has no side effects.
+func ApplyImpact(current, impact WorldMetrics) (next WorldMetrics, gameOver bool) {
+	next = WorldMetrics{
+		Economy:     clamp(current.Economy+impact.Economy, -100, 100),
+		Security:    clamp(current.Security+impact.Security, -100, 100),
+		Diplomacy:   clamp(current.Diplomacy+impact.Diplomacy, -100, 100),
+		Environment: clamp(current.Environment+impact.Environment, -100, 100),
+		Approval:    clamp(current.Approval+impact.Approval, -100, 100),
+		Stability:   clamp(current.Stability+impact.Stability, -100, 100),
+	}
+	return next, metricTriggersGameOver(next)
+}
+
+// capImpact scales impact down uniformly so no metric moves more than maxDelta, keeping the
+// metrics' relative proportions; maxDelta <= 0 leaves it unchanged
+func capImpact(impact WorldMetrics, maxDelta float64) WorldMetrics {
+	if maxDelta <= 0 { return impact }
+	largest := 0.0
+	for _, d := range []float64{impact.Economy, impact.Security, impact.Diplomacy, impact.Environment, impact.Approval, impact.Stability} {
+		largest = math.Max(largest, math.Abs(d))
+	}
+	if largest <= maxDelta { return impact }
+	f := maxDelta / largest
+	return WorldMetrics{
+		Economy:     impact.Economy * f,
+		Security:    impact.Security * f,
+		Diplomacy:   impact.Diplomacy * f,
+		Environment: impact.Environment * f,
+		Approval:    impact.Approval * f,
+		Stability:   impact.Stability * f,
+	}
 }
 
 func clamp(value, min, max float64) float64 {
@@ -409,10 +619,11 @@ func clamp(value, min, max float64) float64 {
 }
 
 func (g *GameOrchestrator) GetCurrentState() *GameState { return g.sim.state }
-func (g *GameOrchestrator) IsGameComplete() bool { return g.sim.state.Turn > g.sim.state.MaxTurns }
-
-// snippet utility
-func snippet(s string, n int) string { if len(s) <= n { return s }; return s[:n] + "..." }
+func (g *GameOrchestrator) IsGameComplete() bool {
+	g.sim.stateMu.RLock()
+	defer g.sim.stateMu.RUnlock()
+	return g.sim.state.Turn > g.sim.state.MaxTurns
+}
 
 // synthFallbackAdvice (restored)
 func synthFallbackAdvice(advisor Advisor) string {
@@ -421,136 +632,18 @@ func synthFallbackAdvice(advisor Advisor) string {
 }
 
 // ImpactDecision represents the model's impact decision for an evaluation
-type ImpactDecision struct {
-	Level         string `json:"level"`
-	Direction     string `json:"direction"` // "+", "-", or "0"
-	Justification string `json:"justification,omitempty"`
-}
+type ImpactDecision = fw.ImpactDecision
 
-// parseImpactLevelsFromText scans the text for a JSON object containing an `impacts` map
-// with keys for metrics and values of {level, direction, justification?}.
+// parseImpactLevelsFromText extracts the impacts map via the framework parser and maps
+// metric aliases onto the game's six metrics.
 func parseImpactLevelsFromText(text string) (map[string]ImpactDecision, bool) {
-	text = strings.TrimSpace(text)
-	// Trim simple code fences/backticks if present
-	text = strings.Trim(text, "`")
-
-	// Prefer extracting the balanced JSON that contains the "impacts" key
-	if frag, ok := extractImpactsJSON(text); ok {
-		var obj map[string]any
-		if json.Unmarshal([]byte(frag), &obj) == nil {
-			var impactsRaw any
-			if v, ok := obj["impacts"]; ok {
-				impactsRaw = v
-			} else if v, ok := obj["impact"]; ok {
-				impactsRaw = v
-			}
-			if impactsRaw != nil {
-				if m, ok := impactsRaw.(map[string]any); ok {
-					res := map[string]ImpactDecision{}
-					for k, v := range m {
-						key := normalizeMetricKey(k)
-						mv, ok2 := v.(map[string]any)
-						if !ok2 { continue }
-						lvl, _ := mv["level"].(string)
-						dir, _ := mv["direction"].(string)
-						just, _ := mv["justification"].(string)
-						if lvl == "" || dir == "" { continue }
-						res[key] = ImpactDecision{Level: strings.ToLower(lvl), Direction: dir, Justification: just}
-					}
-					if len(res) > 0 { return res, true }
-				}
-			}
-		}
-	}
-
-	// NEW: robust fallback – scan for any balanced JSON object containing the key "impacts" (or "impact")
-	if frag, ok := findAnyBalancedJSONWithImpact(text); ok {
-		var obj map[string]any
-		if json.Unmarshal([]byte(frag), &obj) == nil {
-			var impactsRaw any
-			if v, ok := obj["impacts"]; ok { impactsRaw = v } else if v, ok := obj["impact"]; ok { impactsRaw = v }
-			if impactsRaw != nil {
-				if m, ok := impactsRaw.(map[string]any); ok {
-					res := map[string]ImpactDecision{}
-					for k, v := range m {
-						key := normalizeMetricKey(k)
-						mv, ok2 := v.(map[string]any)
-						if !ok2 { continue }
-						lvl, _ := mv["level"].(string)
-						dir, _ := mv["direction"].(string)
-						just, _ := mv["justification"].(string)
-						if lvl == "" || dir == "" { continue }
-						res[key] = ImpactDecision{Level: strings.ToLower(lvl), Direction: dir, Justification: just}
-					}
-					if len(res) > 0 { return res, true }
-				}
-			}
-		}
-	}
-
-	// Fallback: regex-based scan of JSON candidates (may fail on nested objects but useful as backup)
-	locs := jsonCandidateRE.FindAllStringIndex(text, -1)
-	for i := len(locs) - 1; i >= 0; i-- {
-		frag := text[locs[i][0]:locs[i][1]]
-		var obj map[string]any
-		if json.Unmarshal([]byte(frag), &obj) != nil { continue }
-		var impactsRaw any
-		if v, ok := obj["impacts"]; ok { impactsRaw = v } else if v, ok := obj["impact"]; ok { impactsRaw = v }
-		if impactsRaw == nil { continue }
-		m, ok := impactsRaw.(map[string]any)
-		if !ok { continue }
-		res := map[string]ImpactDecision{}
-		for k, v := range m {
-			key := normalizeMetricKey(k)
-			mv, ok2 := v.(map[string]any)
-			if !ok2 { continue }
-			lvl, _ := mv["level"].(string)
-			dir, _ := mv["direction"].(string)
-			just, _ := mv["justification"].(string)
-			if lvl == "" || dir == "" { continue }
-			res[key] = ImpactDecision{Level: strings.ToLower(lvl), Direction: dir, Justification: just}
-		}
-		if len(res) > 0 { return res, true }
+	levels, ok := fw.ParseImpactLevels(text)
+	if !ok { return nil, false }
+	res := make(map[string]ImpactDecision, len(levels))
+	for k, v := range levels {
+		res[normalizeMetricKey(k)] = v
 	}
-	return nil, false
-}
-
-// extractImpactsJSON finds a balanced JSON object around the last occurrence of the "impacts" (or "impact") key.
-func extractImpactsJSON(s string) (string, bool) {
-	low := strings.ToLower(s)
-	idx := strings.LastIndex(low, "\"impacts\"")
-	if idx == -1 { idx = strings.LastIndex(low, "\"impact\"") }
-	if idx == -1 { return "", false }
-	// Find opening '{' before the key
-	open := -1
-	for i := idx; i >= 0; i-- {
-		if s[i] == '{' { open = i; break }
-	}
-	if open == -1 { return "", false }
-	// Scan forward to find the matching closing '}' with brace counting while respecting strings and escapes
-	end, ok := matchBalancedClosingBrace(s, open)
-	if !ok { return "", false }
-	return s[open : end+1], true
-}
-
-// findAnyBalancedJSONWithImpact scans the entire string, and for each JSON object found (balanced braces),
-// returns the last one that contains the key "impacts" (or "impact").
-func findAnyBalancedJSONWithImpact(s string) (string, bool) {
-	lastFrag := ""
-	for i := 0; i < len(s); i++ {
-		if s[i] != '{' { continue }
-		end, ok := matchBalancedClosingBrace(s, i)
-		if !ok { continue }
-		frag := s[i : end+1]
-		lowFrag := strings.ToLower(frag)
-		if strings.Contains(lowFrag, "\"impacts\"") || strings.Contains(lowFrag, "\"impact\"") {
-			lastFrag = frag
-		}
-		// continue scanning to prefer the last occurrence
-		i = end
-	}
-	if lastFrag != "" { return lastFrag, true }
-	return "", false
+	return res, true
 }
 
 func normalizeMetricKey(k string) string {
@@ -578,12 +671,12 @@ func normalizeMetricKey(k string) string {
 }
 
 // convertImpactLevelsToDeltas maps level+direction to numeric deltas using ranges.
-// low: 5-10, medium: 15-30, high: 30-50, extreme: to boundary.
-func convertImpactLevelsToDeltas(levels map[string]ImpactDecision, curr WorldMetrics) WorldMetrics {
+// low: 5-10, medium: 15-30, high: 30-50, extreme: to boundary (at least 50), before the per-turn cap.
+func convertImpactLevelsToDeltas(rng *rand.Rand, levels map[string]ImpactDecision, curr WorldMetrics) WorldMetrics {
 	pick := func(min, max int) float64 {
 		if max < min { max = min }
 		if max == min { return float64(min) }
-		return float64(min + rand.Intn(max-min+1))
+		return float64(min + rng.Intn(max-min+1))
 	}
 	magFor := func(level string, dir string, current float64) float64 {
 		l := strings.ToLower(strings.TrimSpace(level))
@@ -604,9 +697,9 @@ func convertImpactLevelsToDeltas(levels map[string]ImpactDecision, curr WorldMet
 			if neg { return -m }
 			return m
 		case "extreme":
-			// Push to boundary [-100, 100]
-			if neg { return -(current + 100) }
-			return 100 - current
+			// Push toward the boundary [-100, 100] but never weaker than "high"; capImpact bounds the turn
+			if neg { return -math.Max(current+100, 50) }
+			return math.Max(100-current, 50)
 		default:
 			// default to low
 			m := pick(5, 10)
@@ -627,6 +720,7 @@ func convertImpactLevelsToDeltas(levels map[string]ImpactDecision, curr WorldMet
 // Use Director for evaluation with impact-level parsing
 func (g *GameOrchestrator) evaluateChoice(ctx context.Context, turnResult *TurnResult) (string, WorldMetrics, error) {
 	start := time.Now()
+	state := g.sim.snapshotState()
 	log.Printf("[DIRECTOR] evaluating choice turn=%d option=%q category=%s severity=%d", turnResult.Turn, turnResult.Choice.Option, turnResult.Event.Category, turnResult.Event.Severity)
 	de := &fw.GameEvent{Type:"player_choice", PlayerID:"president", Timestamp: time.Now(), Location:"white_house", Action:"decision", Parameters: map[string]interface{}{
 		"option": turnResult.Choice.Option,
@@ -635,121 +729,53 @@ func (g *GameOrchestrator) evaluateChoice(ctx context.Context, turnResult *TurnR
 		"event_title": turnResult.Event.Title,
 		"event_description": turnResult.Event.Description,
 		"reasoning": turnResult.Choice.Reasoning,
+		"history": summarizeTurnHistory(state.History),
 	}}
-	decision, err := g.sim.director.ProcessEvent(ctx, de)
+	// The engine falls back from Theta to Gemini itself; the trace says which one answered
+	traced, viaGemini := withGeminiTrace(ctx)
+	countDirector := func(s *AIUsageStats) {
+		if viaGemini.Load() { s.DirectorGemini++ } else { s.DirectorTheta++ }
+	}
+	decision, err := g.sim.director.ProcessEvent(traced, de)
 	if err == nil {
-		// Try new impact-levels parser first
-		if levels, ok := parseImpactLevelsFromText(decision.Reasoning); ok {
-			imp := convertImpactLevelsToDeltas(levels, g.sim.state.Metrics)
-			g.sim.state.Stats.DirectorTheta++
+		// Try new impact-levels parser first (Reasoning holds the narrative, Raw the full output incl. JSON)
+		if levels, ok := parseImpactLevelsFromText(decision.Raw); ok {
+			imp := convertImpactLevelsToDeltas(g.sim.rng, levels, state.Metrics)
+			g.sim.countUsage(countDirector)
 			analysis := extractActionAnalysisText(decision.Reasoning)
 			if strings.TrimSpace(analysis) == "" { analysis = formatDirectorNarrative(turnResult, imp) }
 			log.Printf("[DIRECTOR] levels parsed latency=%s", time.Since(start))
 			return analysis, imp, nil
 		}
 		// Backward compatibility: try legacy metrics JSON
-		if impact, ok := parseDirectorMetricsFromReasoning(decision.Reasoning); ok {
-			g.sim.state.Stats.DirectorTheta++
+		if impact, ok := parseDirectorMetricsFromReasoning(decision.Raw); ok {
+			g.sim.countUsage(countDirector)
 			analysis := extractActionAnalysisText(decision.Reasoning)
 			if strings.TrimSpace(analysis) == "" { analysis = formatDirectorNarrative(turnResult, impact) }
 			log.Printf("[DIRECTOR] legacy metrics parsed latency=%s", time.Since(start))
 			return analysis, impact, nil
 		}
 	}
-	if err != nil { log.Printf("[DIRECTOR] error: %v (trying Gemini fallback)", err) } else { log.Printf("[DIRECTOR] no parsable output; using Gemini path") }
-
-	analysis2, impact2, gerr2 := g.directorMetricsViaGemini(ctx, turnResult)
-	if gerr2 == nil {
-		g.sim.state.Stats.DirectorGemini++
-		log.Printf("[DIRECTOR] Gemini success latency=%s", time.Since(start))
-		if strings.TrimSpace(analysis2) == "" { analysis2 = formatDirectorNarrative(turnResult, impact2) }
-		return analysis2, impact2, nil
-	}
-	log.Printf("[DIRECTOR] Gemini evaluation failed detail: %v (using random)", gerr2)
+	if err != nil { log.Printf("[DIRECTOR] error (%s): %v (using random)", thetaFailureKind(err), err) } else { log.Printf("[DIRECTOR] no parsable output (using random)") }
 	return g.randomEval(turnResult), g.randomImpact(), nil
 }
 
-// Gemini path now requests impact levels + directions and converts to numeric deltas
-func (g *GameOrchestrator) directorMetricsViaGemini(ctx context.Context, t *TurnResult) (string, WorldMetrics, error) {
-	c := gemini.New()
-	if c.APIKey == "" {
-		return "", WorldMetrics{}, errors.New("GOOGLE_AI_API_KEY not set")
-	}
-	pp := fmt.Sprintf(`Event Evaluation Prompt
-You are an experienced policy analyst. Explain in simple, everyday language.
-
-Provide:
-1) Action Analysis: 1–2 short sentences. Keep it clear and conversational. No jargon.
-2) A final single-line JSON object with categorical impact levels and directions per metric. Use exactly this schema keys and ranges:
-{"impacts":{
-  "economy":{"level":"low|medium|high|extreme","direction":"+|-|0","justification":"<why>"},
-  "security":{"level":"low|medium|high|extreme","direction":"+|-|0","justification":"<why>"},
-  "diplomacy":{"level":"low|medium|high|extreme","direction":"+|-|0","justification":"<why>"},
-  "environment":{"level":"low|medium|high|extreme","direction":"+|-|0","justification":"<why>"},
-  "approval":{"level":"low|medium|high|extreme","direction":"+|-|0","justification":"<why>"},
-  "stability":{"level":"low|medium|high|extreme","direction":"+|-|0","justification":"<why>"}
-}}
-Rules:
-- Choose a LEVEL per metric: low (5–10), medium (15–30), high (30–50), extreme (maximal effect).
-- Direction: "+" increases the metric, "-" decreases it, "0" means no change.
-- Output ONLY the JSON object on the final line. No markdown after it.
-
-Event Description:
-%s
-
-Player's Chosen Action:
-%s`, t.Event.Description, t.Choice.Reasoning)
-	ctx2, cancel := context.WithTimeout(ctx, 22*time.Second)
-	defer cancel()
-	out, err := c.GenerateText(ctx2, pp)
-	if err != nil { return "", WorldMetrics{}, err }
-	raw := strings.TrimSpace(out)
-	analysis := extractActionAnalysisText(raw)
-	levels, ok := parseImpactLevelsFromText(raw)
-	if !ok {
-		// Log raw Gemini response to help debug formatting/prompting issues
-		log.Printf("[GEMINI RAW OUTPUT] %s", raw)
-		return analysis, WorldMetrics{}, errors.New("gemini did not return impact levels")
-	}
-	imp := convertImpactLevelsToDeltas(levels, g.sim.state.Metrics)
-	return strings.TrimSpace(analysis), imp, nil
-}
-
-// Extracts text before the last JSON object in a string.
-func extractAnalysisText(s string) string {
-	s = strings.TrimSpace(s)
-	if s == "" { return "" }
-	start := strings.LastIndex(s, "{")
-	end := strings.LastIndex(s, "}")
-	if start >= 0 && end > start {
-		return strings.TrimSpace(s[:start])
+// summarizeTurnHistory renders completed turns as one-line summaries for the Director prompt
+func summarizeTurnHistory(history []TurnResult) []string {
+	lines := make([]string, 0, len(history))
+	for _, t := range history {
+		outcome := firstNSentences(extractActionAnalysisText(t.Evaluation), 1)
+		lines = append(lines, fmt.Sprintf("Turn %d: %s (%s, severity %d/10) | response: %s | outcome: %s",
+			t.Turn, t.Event.Title, t.Event.Category, t.Event.Severity, textutil.Snippet(strings.TrimSpace(t.Choice.Reasoning), 120), textutil.Snippet(outcome, 160)))
 	}
-	return s
+	return lines
 }
 
 // extractActionAnalysisText returns only the "Action Analysis" narrative, without any "Metric Impact" section or trailing JSON.
 func extractActionAnalysisText(s string) string {
-	s = strings.TrimSpace(s)
+	// 1) Drop the impacts/metrics JSON (even when truncated) and any other trailing object
+	s = jsonextract.Prose(s, "impacts", "impact", "metrics")
 	if s == "" { return "" }
-	// 0) If an impacts/metrics JSON starts but may be truncated, cut from its opening brace
-	lowAll := strings.ToLower(s)
-	cutIdx := -1
-	if idx := strings.LastIndex(lowAll, "\"impacts\""); idx >= 0 { cutIdx = idx }
-	if cutIdx == -1 {
-		if idx := strings.LastIndex(lowAll, "\"impact\""); idx >= 0 { cutIdx = idx }
-	}
-	if cutIdx == -1 {
-		if idx := strings.LastIndex(lowAll, "\"metrics\""); idx >= 0 { cutIdx = idx }
-	}
-	if cutIdx >= 0 {
-		open := -1
-		for i := cutIdx; i >= 0; i-- { if s[i] == '{' { open = i; break } }
-		if open >= 0 { s = strings.TrimSpace(s[:open]) } else { s = strings.TrimSpace(s[:cutIdx]) }
-	}
-	// 1) Remove trailing JSON block if present
-	if start, end := strings.LastIndex(s, "{"), strings.LastIndex(s, "}"); start >= 0 && end > start {
-		s = strings.TrimSpace(s[:start])
-	}
 	// 2) Cut off any Metric Impact section (case-insensitive)
 	low := strings.ToLower(s)
 	if idx := strings.Index(low, "metric impact"); idx >= 0 {
@@ -765,11 +791,7 @@ func extractActionAnalysisText(s string) string {
 
 // parseDirectorMetricsFromReasoning extracts only if a final JSON with metrics exists; no heuristics.
 func parseDirectorMetricsFromReasoning(text string) (WorldMetrics, bool) {
-	text = strings.TrimSpace(text)
-	start := strings.LastIndex(text, "{")
-	end := strings.LastIndex(text, "}")
-	if start >= 0 && end > start {
-		fragment := text[start : end+1]
+	if fragment, ok := jsonextract.LastObjectWithKey(text, "metrics"); ok {
 		var outer struct{ Metrics map[string]int `json:"metrics"` }
 		if json.Unmarshal([]byte(fragment), &outer) == nil && len(outer.Metrics) > 0 {
 			m := outer.Metrics
@@ -786,28 +808,165 @@ func parseDirectorMetricsFromReasoning(text string) (WorldMetrics, bool) {
 	return WorldMetrics{}, false
 }
 
-// --- Gemini fallbacks ---
-
-func (g *GameOrchestrator) advisorOpinionViaGemini(ctx context.Context, advisor Advisor, event GameEvent) (string, error) {
-	c := gemini.New()
-	if c.APIKey == "" {
-		return "", errors.New("GOOGLE_AI_API_KEY not set")
+// thetaFailureKind names why a Theta call failed, for fallback logging
+func thetaFailureKind(err error) string {
+	switch {
+	case errors.Is(err, fw.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, fw.ErrServerError):
+		return "server_error"
+	case errors.Is(err, fw.ErrDecode):
+		return "decode_error"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	}
+	return "error"
+}
+
+// newspaperRecap is the model's endgame newspaper
+type newspaperRecap struct {
+	Headline string `json:"headline"`
+	Article  string `json:"article"`
+}
+
+// EndgameNewspaper returns the end-of-term newspaper, generated once per game and cached on the
+// game state. With GameConfig.LLMNewspaper it is written by Theta (then Gemini) from the full
+// history and final metrics; otherwise, or if both fail, buildEndgameNewspaper is used. Before the
+// game is complete it returns the deterministic newspaper for the term so far and caches nothing.
+func (g *GameOrchestrator) EndgameNewspaper(ctx context.Context) string {
+	g.newspaperMu.Lock()
+	defer g.newspaperMu.Unlock()
+	state := g.sim.snapshotState()
+	if state.Turn <= state.MaxTurns { return buildEndgameNewspaper(&state, g.sim.scoreWeights()) }
+	if state.Newspaper != "" { return state.Newspaper }
+	paper := ""
+	if g.sim.config != nil && g.sim.config.LLMNewspaper {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		recap, err := g.newspaperViaLLM(ctx, &state)
+		if err != nil { log.Printf("[NEWSPAPER] LLM recap failed: %v (using deterministic newspaper)", err) }
+		if err == nil { paper = formatNewspaper(&state, g.sim.scoreWeights(), recap) }
+	}
+	if paper == "" { paper = buildEndgameNewspaper(&state, g.sim.scoreWeights()) }
+	g.sim.stateMu.Lock()
+	g.sim.state.Newspaper = paper
+	g.sim.stateMu.Unlock()
+	return paper
+}
+
+// newspaperViaLLM asks the engine's providers (Theta, then Gemini) for a newspaper recap of the
+// whole term in state
+func (g *GameOrchestrator) newspaperViaLLM(ctx context.Context, state *GameState) (newspaperRecap, error) {
+	prompt := buildNewspaperPrompt(state, g.sim.scoreWeights())
+	recap, err := fw.GenerateJSON[newspaperRecap](ctx, g.sim.engine, &fw.LLMRequest{Model: fw.ModelStoryDefault, Prompt: prompt, MaxTokens: fw.DefaultStoryMaxTokens, Temperature: fw.Float64(0.7)})
+	if err != nil { return newspaperRecap{}, fmt.Errorf("%s: %w", thetaFailureKind(err), err) }
+	if !validRecap(recap) { return newspaperRecap{}, errors.New("empty recap") }
+	return recap, nil
+}
+
+func validRecap(r newspaperRecap) bool {
+	return strings.TrimSpace(r.Headline) != "" && strings.TrimSpace(r.Article) != ""
+}
+
+// buildNewspaperPrompt feeds the full term history and final metrics to the recap prompt
+func buildNewspaperPrompt(state *GameState, weights WorldMetricsWeights) string {
+	m := state.Metrics
+	return fmt.Sprintf(`You are the editor of a national newspaper writing the front-page recap of a presidency that just ended.
+Final metrics (0-100): Economy %.0f, Security %.0f, Diplomacy %.0f, Environment %.0f, Approval %.0f, Stability %.0f. Final score %.0f/100.
+The term, turn by turn:
+%s
+Task: Write a punchy headline and a 3-5 short paragraph article recapping the term: the key decisions, how they played out, and the legacy they leave. Plain everyday language, journalistic third person ("the President"). No markdown.
+Output ONLY valid JSON: {"headline":"<headline>","article":"<article, paragraphs separated by \n\n>"}`,
+		m.Economy, m.Security, m.Diplomacy, m.Environment, m.Approval, m.Stability, calculateFinalScore(m, weights),
+		strings.Join(summarizeTurnHistory(state.History), "\n"))
+}
+
+// formatNewspaper lays out an LLM recap with the same masthead, metrics and sign-off as buildEndgameNewspaper
+func formatNewspaper(state *GameState, weights WorldMetricsWeights, recap newspaperRecap) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🗞️ NATIONAL LEDGER — %s\n", strings.ToUpper(strings.TrimSpace(recap.Headline)))
+	fmt.Fprintf(&b, "=======================================\n\n")
+	fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(recap.Article))
+	fmt.Fprintf(&b, "Final Metrics — Economy %.1f | Security %.1f | Diplomacy %.1f | Environment %.1f | Approval %.1f | Stability %.1f\n\n",
+		state.Metrics.Economy, state.Metrics.Security, state.Metrics.Diplomacy, state.Metrics.Environment, state.Metrics.Approval, state.Metrics.Stability)
+	fmt.Fprintf(&b, "Final Score %.1f/100 (%s)\n\n", calculateFinalScore(state.Metrics, weights), formatScoreWeights(weights))
+	b.WriteString("— End of Term —\n")
+	return b.String()
+}
+
+// NewspaperTurn is one turn's section of the streamed endgame newspaper
+type NewspaperTurn struct {
+	Turn     int    `json:"turn"`
+	Headline string `json:"headline,omitempty"`
+	Body     string `json:"body,omitempty"`
+}
+
+// StreamNewspaper has Theta write the endgame recap one turn at a time, sending each section on
+// sections as soon as it is written. It returns an error, possibly after some sections were sent,
+// when GameConfig.LLMNewspaper is off, there is no history, or a turn's recap cannot be generated;
+// callers then fall back to buildEndgameNewspaper.
+func (g *GameOrchestrator) StreamNewspaper(ctx context.Context, sections chan<- NewspaperTurn) error {
+	if g.sim.config == nil || !g.sim.config.LLMNewspaper { return errors.New("LLM newspaper disabled") }
+	// Work from a snapshot: the recap takes a while and the player may start a new game meanwhile
+	state := g.sim.snapshotState()
+	history := state.History
+	if len(history) == 0 { return errors.New("no turns to recap") }
+	term := summarizeTurnHistory(history)
+	for i, t := range history {
+		prompt := buildNewspaperTurnPrompt(&state, g.sim.scoreWeights(), term, i)
+		recap, err := fw.GenerateJSON[newspaperRecap](ctx, g.sim.engine, &fw.LLMRequest{Model: fw.ModelStoryDefault, Prompt: prompt, MaxTokens: fw.DefaultStoryMaxTokens, Temperature: fw.Float64(0.7)})
+		if err == nil && !validRecap(recap) { err = errors.New("empty recap") }
+		if err != nil { return fmt.Errorf("turn %d recap: %w", t.Turn, err) }
+		select {
+		case sections <- NewspaperTurn{Turn: t.Turn, Headline: strings.TrimSpace(recap.Headline), Body: strings.TrimSpace(recap.Article)}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
+	return nil
+}
+
+// buildNewspaperTurnPrompt asks for the recap section covering history[i], with the whole term as context
+func buildNewspaperTurnPrompt(state *GameState, weights WorldMetricsWeights, term []string, i int) string {
+	m := state.Metrics
+	return fmt.Sprintf(`You are the editor of a national newspaper writing the front-page recap of a presidency that just ended, one turn at a time.
+Final metrics (0-100): Economy %.0f, Security %.0f, Diplomacy %.0f, Environment %.0f, Approval %.0f, Stability %.0f. Final score %.0f/100.
+The term, turn by turn:
+%s
+Task: Write the section covering turn %d only: a punchy headline and one short paragraph on the decision and how it played out. Plain everyday language, journalistic third person ("the President"). No markdown.
+Output ONLY valid JSON: {"headline":"<headline>","article":"<paragraph>"}`,
+		m.Economy, m.Security, m.Diplomacy, m.Environment, m.Approval, m.Stability, calculateFinalScore(m, weights),
+		strings.Join(term, "\n"), state.History[i].Turn)
+}
+
+// advisorOpinion is the JSON an advisor fallback prompt asks for
+type advisorOpinion struct {
+	AdvisorOpinion string   `json:"advisor_opinion"`
+	Conviction     *float64 `json:"conviction"`
+}
+
+// advisorOpinionViaEngine asks the engine's providers (Theta, then Gemini) for the advisor's opinion
+// when the Llama endpoint fails, reporting whether Gemini answered
+func (g *GameOrchestrator) advisorOpinionViaEngine(ctx context.Context, advisor Advisor, event GameEvent) (string, int, bool, error) {
 	pp := fmt.Sprintf(`You are %s (%s), a senior presidential advisor.
 Event: %s
 Category: %s (severity %d/10)
 Description: %s
 Task: Provide one concise, actionable advisory opinion.
 Constraints: 2-4 sentences. No internal reasoning, no preamble, no self-reference.
-Output ONLY valid JSON exactly like: {"advisor_opinion":"<your concise advisory>"}
+Rate from 0 (tentative) to 10 (certain) how strongly you stand behind the advice.
+Output ONLY valid JSON exactly like: {"advisor_opinion":"<your concise advisory>","conviction":<0-10>}
 No markdown.`, advisor.Name, advisor.Title, event.Title, event.Category, event.Severity, event.Description)
 	ctx2, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
-	out, err := c.GenerateText(ctx2, pp)
-	if err != nil { return "", err }
-	op := extractAdvisorOpinion(strings.TrimSpace(out))
-	if op == "" || looksMetaLike(op) { return "", errors.New("gemini returned invalid advisor_opinion") }
-	return op, nil
+	ctx2, viaGemini := withGeminiTrace(ctx2)
+	resp, err := fw.GenerateJSON[advisorOpinion](ctx2, g.sim.engine, &fw.LLMRequest{Model: fw.ModelDialogueDefault, Prompt: pp, MaxTokens: 300, Temperature: fw.Float64(0.7)})
+	if err != nil { return "", 0, false, err }
+	op := sanitizeOpinion(resp.AdvisorOpinion, g.sim.maxOpinionSentences())
+	if op == "" || looksMetaLike(op) { return "", 0, false, errors.New("fallback returned invalid advisor_opinion") }
+	conviction := neutralConviction
+	if resp.Conviction != nil { conviction = clampConviction(*resp.Conviction) }
+	return op, conviction, viaGemini.Load(), nil
 }
 
 // formatDirectorNarrative builds a concise analysis header when model analysis text is empty.
@@ -817,27 +976,3 @@ func formatDirectorNarrative(t *TurnResult, impact WorldMetrics) string {
 	return fmt.Sprintf("Action analysis: You chose: %s. Event: %s (%s, %d/10).", reason, t.Event.Title, t.Event.Category, t.Event.Severity)
 }
 
-// matchBalancedClosingBrace returns the index of the matching '}' starting from an opening '{',
-// handling nested braces and JSON string literals with escapes.
-func matchBalancedClosingBrace(s string, start int) (int, bool) {
-	if start < 0 || start >= len(s) || s[start] != '{' { return -1, false }
-	depth := 0
-	inStr := false
-	esc := false
-	for i := start; i < len(s); i++ {
-		ch := s[i]
-		if inStr {
-			if esc { esc = false; continue }
-			if ch == '\\' { esc = true; continue }
-			if ch == '"' { inStr = false; continue }
-			continue
-		}
-		if ch == '"' { inStr = true; continue }
-		if ch == '{' { depth++ }
-		if ch == '}' {
-			depth--
-			if depth == 0 { return i, true }
-		}
-	}
-	return -1, false
-}
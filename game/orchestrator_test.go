@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	fw "github.com/emergent-world-engine/backend/pkg/framework"
+	gemini "presidential-simulator/internal/gemini_client"
+)
+
+func TestApplyImpact(t *testing.T) {
+	base := WorldMetrics{Economy: 50, Security: 50, Diplomacy: 50, Environment: 50, Approval: 50, Stability: 50}
+	cases := []struct {
+		name     string
+		current  WorldMetrics
+		impact   WorldMetrics
+		want     WorldMetrics
+		gameOver bool
+	}{
+		{"no change", base, WorldMetrics{}, base, false},
+		{"adds per metric", base,
+			WorldMetrics{Economy: 10, Security: -5, Diplomacy: 1, Environment: -1, Approval: 20, Stability: -20},
+			WorldMetrics{Economy: 60, Security: 45, Diplomacy: 51, Environment: 49, Approval: 70, Stability: 30}, false},
+		{"clamps at the upper cap", base,
+			WorldMetrics{Economy: 80, Approval: 51},
+			WorldMetrics{Economy: 100, Security: 50, Diplomacy: 50, Environment: 50, Approval: 100, Stability: 50}, false},
+		{"clamps only the overflowing metric", WorldMetrics{Economy: 95, Security: 95, Diplomacy: 50, Environment: 50, Approval: 50, Stability: 50},
+			WorldMetrics{Economy: 10, Security: 5},
+			WorldMetrics{Economy: 100, Security: 100, Diplomacy: 50, Environment: 50, Approval: 50, Stability: 50}, false},
+		{"clamps at the lower cap", base,
+			WorldMetrics{Environment: -500},
+			WorldMetrics{Economy: 50, Security: 50, Diplomacy: 50, Environment: -100, Approval: 50, Stability: 50}, true},
+		{"just above zero survives", base,
+			WorldMetrics{Stability: -49.5},
+			WorldMetrics{Economy: 50, Security: 50, Diplomacy: 50, Environment: 50, Approval: 50, Stability: 0.5}, false},
+		{"exactly zero ends the game", base,
+			WorldMetrics{Approval: -50},
+			WorldMetrics{Economy: 50, Security: 50, Diplomacy: 50, Environment: 50, Approval: 0, Stability: 50}, true},
+		{"any single metric ends the game", base,
+			WorldMetrics{Economy: 40, Diplomacy: -60},
+			WorldMetrics{Economy: 90, Security: 50, Diplomacy: -10, Environment: 50, Approval: 50, Stability: 50}, true},
+	}
+	for _, tc := range cases {
+		got, gameOver := ApplyImpact(tc.current, tc.impact)
+		if got != tc.want || gameOver != tc.gameOver {
+			t.Errorf("%s: got %+v (game over %v), want %+v (game over %v)", tc.name, got, gameOver, tc.want, tc.gameOver)
+		}
+	}
+}
+
+func TestAdvisorsPerTurn(t *testing.T) {
+	sim := newTestSim(t)
+	if got := sim.advisorsPerTurn(); got != defaultAdvisorsPerTurn {
+		t.Errorf("Expected the default of %d advisors, got %d", defaultAdvisorsPerTurn, got)
+	}
+	sim.config = nil
+	if got := sim.advisorsPerTurn(); got != defaultAdvisorsPerTurn {
+		t.Errorf("Expected the default of %d advisors without config, got %d", defaultAdvisorsPerTurn, got)
+	}
+
+	sim = newTestSim(t)
+	sim.config.AdvisorsPerTurn = 5
+	g := NewGameOrchestrator(sim)
+	selected := g.selectRandomAdvisors(sim.advisorsPerTurn())
+	if len(selected) != 5 {
+		t.Fatalf("Expected 5 advisors, got %d", len(selected))
+	}
+	seen := map[string]bool{}
+	for _, a := range selected {
+		if seen[a.ID] {
+			t.Errorf("Expected distinct advisors, got %s twice", a.ID)
+		}
+		seen[a.ID] = true
+	}
+
+	sim.config.AdvisorsPerTurn = 20
+	if got := sim.advisorsPerTurn(); got != len(defaultAdvisors) {
+		t.Errorf("Expected the count to be capped at the %d-advisor roster, got %d", len(defaultAdvisors), got)
+	}
+	if got := len(g.selectRandomAdvisors(sim.advisorsPerTurn())); got != len(defaultAdvisors) {
+		t.Errorf("Expected the whole roster, got %d advisors", got)
+	}
+}
+
+// TestTurnAdvisorResponses tests that a turn returns one opinion per configured advisor, each from
+// a distinct roster advisor and carrying the model's advice
+func TestTurnAdvisorResponses(t *testing.T) {
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"text": "{\"advisor_opinion\": \"Call your allies before you act.\", \"conviction\": 7}"}`))
+	}))
+	defer llm.Close()
+	t.Setenv("LLAMA_CHAT_URL", llm.URL)
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+
+	roster := map[string]Advisor{}
+	for _, a := range defaultAdvisors {
+		roster[a.ID] = a
+	}
+	for _, tc := range []struct{ configured, want int }{{1, 1}, {3, 3}, {len(defaultAdvisors) + 4, len(defaultAdvisors)}} {
+		sim := newTestSim(t)
+		sim.imageGen = &stubImageGen{}
+		sim.config.AdvisorsPerTurn = tc.configured
+		turn, err := NewGameOrchestrator(sim).StartNewTurn(context.Background())
+		if err != nil {
+			t.Fatalf("%d advisors: StartNewTurn failed: %v", tc.configured, err)
+		}
+		if len(turn.Advisors) != tc.want {
+			t.Errorf("%d advisors: expected %d responses, got %d", tc.configured, tc.want, len(turn.Advisors))
+		}
+		seen := map[string]bool{}
+		for _, resp := range turn.Advisors {
+			advisor, ok := roster[resp.AdvisorID]
+			if !ok || seen[resp.AdvisorID] || resp.AdvisorName != advisor.Name {
+				t.Errorf("%d advisors: expected a distinct roster advisor, got %+v", tc.configured, resp)
+			}
+			seen[resp.AdvisorID] = true
+			if resp.Advice != "Call your allies before you act." || resp.Conviction != 7 {
+				t.Errorf("%d advisors: expected the model's advice with conviction 7, got %+v", tc.configured, resp)
+			}
+		}
+	}
+}
+
+func TestExtractAdvisorConviction(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want int
+	}{
+		{`{"advisor_opinion": "Hold firm.", "conviction": 8}`, 8},
+		{`{"advisor_opinion": "Hold firm.", "Conviction": "6"}`, 6},
+		{`{"conviction": 7.5}`, 8},
+		{`{"conviction": 7.4}`, 7},
+		{`{"conviction": 14}`, maxConviction},
+		{`{"conviction": -3}`, 0},
+		{`draft {"conviction": 2} final {"advisor_opinion": "Act now.", "conviction": 9}`, 9},
+		{`{"advisor_opinion": "Hold firm."}`, neutralConviction},
+		{`{"conviction": "very"}`, neutralConviction},
+		{"", neutralConviction},
+	}
+	for _, c := range cases {
+		if got := extractAdvisorConviction(c.raw); got != c.want {
+			t.Errorf("extractAdvisorConviction(%q) = %d, want %d", c.raw, got, c.want)
+		}
+	}
+}
+
+// finishedSim returns a simulator whose term is over, with one turn of history
+func finishedSim(t *testing.T, llm *stubLLM) *PresidentSim {
+	t.Helper()
+	sim := newTestSim(t, fw.WithProviders(llm))
+	sim.state.History = []TurnResult{{Turn: 1, Event: GameEvent{ID: "evt_1", Title: "Border Standoff", Category: "security"}, Choice: PlayerChoice{Option: "Open talks"}}}
+	sim.state.Turn = sim.state.MaxTurns + 1
+	return sim
+}
+
+func TestEndgameNewspaperCachesLLMRecap(t *testing.T) {
+	llm := &stubLLM{text: `{"headline": "PRESIDENT TALKS DOWN BORDER CRISIS", "article": "Calm returned to the border."}`}
+	g := NewGameOrchestrator(finishedSim(t, llm))
+
+	paper := g.EndgameNewspaper(context.Background())
+	if !strings.Contains(paper, "PRESIDENT TALKS DOWN BORDER CRISIS") {
+		t.Errorf("Expected the LLM headline in the newspaper, got %q", paper)
+	}
+	if again := g.EndgameNewspaper(context.Background()); again != paper {
+		t.Error("Expected the cached newspaper on the second call")
+	}
+	if calls := llm.callCount(); calls != 1 {
+		t.Errorf("Expected one LLM call across both requests, got %d", calls)
+	}
+}
+
+func TestEndgameNewspaperFallback(t *testing.T) {
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+	sim := finishedSim(t, &stubLLM{err: errors.New("theta unavailable")})
+	g := NewGameOrchestrator(sim)
+
+	want := buildEndgameNewspaper(sim.state, sim.scoreWeights())
+	if paper := g.EndgameNewspaper(context.Background()); paper != want {
+		t.Errorf("Expected the deterministic newspaper, got %q", paper)
+	}
+}
+
+func TestEndgameNewspaperMidGame(t *testing.T) {
+	llm := &stubLLM{text: `{"headline": "TOO EARLY", "article": "Not yet."}`}
+	sim := finishedSim(t, llm)
+	sim.state.Turn = 2
+	g := NewGameOrchestrator(sim)
+
+	if paper := g.EndgameNewspaper(context.Background()); paper != buildEndgameNewspaper(sim.state, sim.scoreWeights()) {
+		t.Errorf("Expected the deterministic newspaper mid-game, got %q", paper)
+	}
+	if sim.state.Newspaper != "" || llm.callCount() != 0 {
+		t.Errorf("Expected no LLM call and nothing cached mid-game, got %d calls and %q", llm.callCount(), sim.state.Newspaper)
+	}
+
+	sim.state.Turn = sim.state.MaxTurns + 1
+	if paper := g.EndgameNewspaper(context.Background()); !strings.Contains(paper, "TOO EARLY") {
+		t.Errorf("Expected the LLM newspaper once the game is complete, got %q", paper)
+	}
+}
+
+// redirectTransport sends every request to target, keeping the path and query
+type redirectTransport struct{ target *url.URL }
+
+func (rt redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = rt.target.Scheme, rt.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestDirectorFallsBackToGeminiProvider(t *testing.T) {
+	var prompts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Contents []struct {
+				Parts []struct{ Text string } `json:"parts"`
+			} `json:"contents"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		prompts = append(prompts, body.Contents[0].Parts[0].Text)
+		text := `Action Analysis: Talks cool the standoff. {"impacts": {"security": {"level": "medium", "direction": "+"}, "diplomacy": {"level": "low", "direction": "+"}}}`
+		json.NewEncoder(w).Encode(map[string]any{"candidates": []any{map[string]any{"content": map[string]any{"parts": []any{map[string]string{"text": text}}}}}})
+	}))
+	defer srv.Close()
+	target, _ := url.Parse(srv.URL)
+	provider := &geminiProvider{client: &gemini.Client{APIKey: "test_key", Model: "gemini-test", HTTP: &http.Client{Transport: redirectTransport{target}}}}
+	sim := newTestSim(t, fw.WithProviders(provider))
+	g := NewGameOrchestrator(sim)
+	turn := &TurnResult{Turn: 1, Event: GameEvent{ID: "evt_1", Title: "Border Standoff", Category: "security", Severity: 6}}
+
+	if err := g.ProcessPlayerChoice(context.Background(), turn, 0, "Open talks."); err != nil {
+		t.Fatalf("ProcessPlayerChoice failed: %v", err)
+	}
+	if stats := sim.usageStats(); stats.DirectorGemini != 1 || stats.DirectorTheta != 0 {
+		t.Errorf("Expected the Director to be answered by Gemini after Theta failed, got %+v", stats)
+	}
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "Open talks") {
+		t.Errorf("Expected one Gemini call with the Director prompt, got %d", len(prompts))
+	}
+	if turn.Impact.Security <= 0 || turn.Impact.Diplomacy <= 0 {
+		t.Errorf("Expected Gemini's impacts to be applied, got %+v", turn.Impact)
+	}
+}
+
+func TestExtremeImpactRespectsTurnCap(t *testing.T) {
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+	llm := &stubLLM{text: `{"action_analysis": "A sweeping gamble.", "impacts": {
+		"economy": {"level": "extreme", "direction": "+"},
+		"security": {"level": "extreme", "direction": "-"},
+		"diplomacy": {"level": "extreme", "direction": "+"},
+		"environment": {"level": "extreme", "direction": "+"},
+		"approval": {"level": "extreme", "direction": "+"},
+		"stability": {"level": "extreme", "direction": "+"}}}`}
+	sim := newTestSim(t, fw.WithProviders(llm))
+	sim.state.Metrics = WorldMetrics{Economy: 0, Security: 90, Diplomacy: 50, Environment: 50, Approval: 50, Stability: 50}
+	g := NewGameOrchestrator(sim)
+	turn := &TurnResult{Turn: 1, Event: GameEvent{ID: "evt_1", Title: "Border Standoff", Category: "security", Severity: 9}}
+
+	if err := g.ProcessPlayerChoice(context.Background(), turn, 0, "Mobilize everything at once."); err != nil {
+		t.Fatalf("ProcessPlayerChoice failed: %v", err)
+	}
+	if sim.state.Stats.DirectorTheta != 1 {
+		t.Fatalf("Expected the stubbed Director impacts to be used, got stats %+v", sim.state.Stats)
+	}
+	imp := turn.Impact
+	largest := 0.0
+	for _, d := range []float64{imp.Economy, imp.Security, imp.Diplomacy, imp.Environment, imp.Approval, imp.Stability} {
+		largest = math.Max(largest, math.Abs(d))
+	}
+	if math.Abs(largest-defaultMaxDeltaPerTurn) > 1e-9 {
+		t.Errorf("Expected the largest swing to be capped at %d, got %.2f (%+v)", defaultMaxDeltaPerTurn, largest, imp)
+	}
+	if imp.Security >= 0 || imp.Economy <= 0 {
+		t.Errorf("Expected directions to survive the cap, got %+v", imp)
+	}
+	if ratio := imp.Economy / -imp.Security; math.Abs(ratio-100.0/190.0) > 1e-9 {
+		t.Errorf("Expected the cap to keep metric proportions, got economy/security %.4f", ratio)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"I said no. Then we waited.", []string{"I said no.", "Then we waited."}},
+		{"Ask Dr. Chen first. The U.S. economy can wait.", []string{"Ask Dr. Chen first.", "The U.S. economy can wait."}},
+		{"Brief Sec. Mitchell and Col. Wright today. Act by Dec. First we talk.", []string{"Brief Sec. Mitchell and Col. Wright today.", "Act by Dec. First we talk."}},
+		{"Cut spending by 5 sec. Then relax.", []string{"Cut spending by 5 sec.", "Then relax."}},
+		{"Is it safe? \"Yes.\" Move fast!", []string{"Is it safe?", "\"Yes.\"", "Move fast!"}},
+		{"Prices rose e.g. fuel. Hold steady", []string{"Prices rose e.g. fuel.", "Hold steady"}},
+		{"Costs are est. Numbers vary.", []string{"Costs are est.", "Numbers vary."}},
+	}
+	for _, c := range cases {
+		got := splitSentences(c.text)
+		if strings.Join(got, "|") != strings.Join(c.want, "|") {
+			t.Errorf("splitSentences(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+}
+
+func TestSanitizeOpinionSentenceCap(t *testing.T) {
+	text := "  \"Call Dr. Chen now.   Say no. Then brief the U.S. Senate! Wait a week.\" "
+	cases := map[int]string{
+		0: "Call Dr. Chen now. Say no. Then brief the U.S. Senate! Wait a week.",
+		1: "Call Dr. Chen now.",
+		3: "Call Dr. Chen now. Say no. Then brief the U.S. Senate!",
+		9: "Call Dr. Chen now. Say no. Then brief the U.S. Senate! Wait a week.",
+	}
+	for n, want := range cases {
+		if got := sanitizeOpinion(text, n); got != want {
+			t.Errorf("sanitizeOpinion(_, %d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -2,23 +2,30 @@ package main
 
 import (
 	"context"
+	crand "crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
-	"math/rand"
+	"net"
 	"net/http"
 	"time"
 	"strings"
 	"os"
 	"regexp"
-	imgc "presidential-simulator/internal/ondemand_image_client"
+	"unicode/utf8"
+
+	fw "github.com/emergent-world-engine/backend/pkg/framework"
 )
 
 // WebServer handles HTTP requests for the Presidential Simulator
 type WebServer struct {
 	orchestrator *GameOrchestrator
 	port         string
+	server       *http.Server
+	logger       fw.Logger // request log; nil logs through the engine logger
 }
 
 // ChatMessage is a UI-friendly message item for the client feed
@@ -31,6 +38,7 @@ type ChatMessage struct {
 	Time           string `json:"time"`
 	Timestamp      int64  `json:"timestamp"`
 	ProfilePicture string `json:"profilePicture"`
+	Conviction     *int   `json:"conviction,omitempty"` // advisor messages only: 0-10 confidence in the advice
 }
 
 // GameStateResponse represents the current game state for the frontend
@@ -70,10 +78,12 @@ type EvaluateResponse struct {
 
 // NewWebServer creates a new web server instance
 func NewWebServer(orchestrator *GameOrchestrator, port string) *WebServer {
-	return &WebServer{
+	ws := &WebServer{
 		orchestrator: orchestrator,
 		port:         port,
 	}
+	ws.server = &http.Server{Addr: ":" + port, Handler: ws.routes()}
+	return ws
 }
 
 func (ws *WebServer) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
@@ -91,27 +101,137 @@ func (ws *WebServer) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// Start starts the web server
-func (ws *WebServer) Start() error {
+// api wraps an /api handler with request logging and CORS
+func (ws *WebServer) api(next http.HandlerFunc) http.HandlerFunc {
+	return ws.loggingMiddleware(ws.corsMiddleware(next))
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 { r.status = code }
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 { r.status = http.StatusOK }
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming responses working through the wrapper
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok { f.Flush() }
+}
+
+// loggingMiddleware logs method, path, status and latency for each request through the framework
+// logger, tagging it with an X-Request-ID (the client's, or a generated one) echoed in the response
+func (ws *WebServer) loggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		reqID := strings.TrimSpace(r.Header.Get("X-Request-ID"))
+		if reqID == "" { reqID = newRequestID() }
+		w.Header().Set("X-Request-ID", reqID)
+		rec := &statusRecorder{ResponseWriter: w}
+		next(rec, r)
+		status := rec.status
+		if status == 0 { status = http.StatusOK }
+		logger := ws.logger
+		if logger == nil { logger = ws.orchestrator.sim.engine.Logger() }
+		logf := logger.Infof
+		switch {
+		case status >= 500: logf = logger.Errorf
+		case status >= 400: logf = logger.Warnf
+		}
+		logf("[%s] %s %s -> %d (%s)", reqID, r.Method, r.URL.Path, status, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+func newRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := crand.Read(buf); err != nil { return fmt.Sprintf("%x", time.Now().UnixNano()) }
+	return hex.EncodeToString(buf)
+}
+
+// routes registers the static UI and the /api endpoints on a fresh mux
+func (ws *WebServer) routes() *http.ServeMux {
+	mux := http.NewServeMux()
 	// Serve static files
-	http.HandleFunc("/", ws.serveStaticFile)
+	mux.HandleFunc("/", ws.serveStaticFile)
 
 	// Existing API endpoints (kept for compatibility)
-	http.HandleFunc("/api/start", ws.corsMiddleware(ws.handleStart))
-	http.HandleFunc("/api/state", ws.corsMiddleware(ws.handleGetState))
-	http.HandleFunc("/api/new-turn", ws.corsMiddleware(ws.handleNewTurn))
-	http.HandleFunc("/api/choice", ws.corsMiddleware(ws.handlePlayerChoice))
+	mux.HandleFunc("/api/start", ws.api(ws.handleStart))
+	mux.HandleFunc("/api/state", ws.api(ws.handleGetState))
+	mux.HandleFunc("/api/new-turn", ws.api(ws.handleNewTurn))
+	mux.HandleFunc("/api/choice", ws.api(ws.handlePlayerChoice))
 
 	// New requested endpoints
-	http.HandleFunc("/api/new-round", ws.corsMiddleware(ws.handleNewRound))
-	http.HandleFunc("/api/evaluate-choice", ws.corsMiddleware(ws.handleEvaluateChoice))
+	mux.HandleFunc("/api/new-round", ws.api(ws.handleNewRound))
+	mux.HandleFunc("/api/new-round-stream", ws.api(ws.handleNewRoundStream))
+	mux.HandleFunc("/api/newspaper-stream", ws.api(ws.handleNewspaperStream))
+	mux.HandleFunc("/api/evaluate-choice", ws.api(ws.handleEvaluateChoice))
 	// Stats-only endpoint
-	http.HandleFunc("/api/stats", ws.corsMiddleware(ws.handleStats))
+	mux.HandleFunc("/api/stats", ws.api(ws.handleStats))
 	// New: on-demand image generation for current event
-	http.HandleFunc("/api/generate-image", ws.corsMiddleware(ws.handleGenerateImage))
+	mux.HandleFunc("/api/generate-image", ws.api(ws.handleGenerateImage))
+	// Discard the current event and generate a fresh one (limited per turn)
+	mux.HandleFunc("/api/reroll", ws.api(ws.handleReroll))
+	// Save the game under a token and restore it later (Redis-backed when REDIS_URL is set)
+	mux.HandleFunc("/api/save", ws.api(ws.handleSave))
+	mux.HandleFunc("/api/load", ws.api(ws.handleLoad))
+	return mux
+}
 
+// Start starts the web server
+func (ws *WebServer) Start() error {
+	ln, err := net.Listen("tcp", ws.server.Addr)
+	if err != nil { return err }
+	return ws.serve(ln)
+}
+
+func (ws *WebServer) serve(ln net.Listener) error {
 	log.Printf("🌐 Presidential Simulator server starting on http://localhost:%s", ws.port)
-	return http.ListenAndServe(":"+ws.port, nil)
+	if err := ws.server.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting new connections and waits for in-flight requests until ctx expires
+func (ws *WebServer) Shutdown(ctx context.Context) error {
+	return ws.server.Shutdown(ctx)
+}
+
+// Run serves until ctx is cancelled (e.g. on SIGINT/SIGTERM), then drains in-flight requests
+// for up to the configured shutdown grace period
+func (ws *WebServer) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", ws.server.Addr)
+	if err != nil { return err }
+	return ws.run(ctx, ln)
+}
+
+func (ws *WebServer) run(ctx context.Context, ln net.Listener) error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- ws.serve(ln) }()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	grace := 40 * time.Second
+	if cfg := ws.orchestrator.sim.config; cfg != nil {
+		grace = cfg.ShutdownGrace
+	}
+	log.Printf("🛑 Shutting down, waiting up to %s for in-flight requests", grace)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	if err := ws.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown: %w", err)
+	}
+	return <-errCh
 }
 
 // serveStaticFile serves the HTML interface
@@ -152,12 +272,19 @@ func (ws *WebServer) handleStart(w http.ResponseWriter, r *http.Request) {
 	}
 	cfg := loadGameConfig()
 	// Reset game state
+	ws.orchestrator.sim.stateMu.Lock()
 	ws.orchestrator.sim.state.Turn = 1
 	ws.orchestrator.sim.state.History = []TurnResult{}
 	ws.orchestrator.sim.state.CurrentTurn = nil
+	ws.orchestrator.sim.state.Rerolls = 0
 	ws.orchestrator.sim.state.Stats = AIUsageStats{}
+	ws.orchestrator.sim.state.Newspaper = ""
+	ws.orchestrator.sim.usageBase = ws.orchestrator.sim.engine.Metrics().TokenUsage
+	ws.orchestrator.sim.config = cfg
+	rng := ws.orchestrator.sim.rng
+	rng.Seed(resolveSeed(cfg.Seed))
 	minV, maxV := cfg.MetricMin, cfg.MetricMax
-	randVal := func() float64 { return float64(minV + rand.Intn(maxV-minV+1)) }
+	randVal := func() float64 { if maxV > minV { return float64(minV + rng.Intn(maxV-minV+1)) }; return float64(minV) }
 	ws.orchestrator.sim.state.Metrics = WorldMetrics{
 		Economy:     randVal(), // Random within configured range
 		Security:    randVal(),
@@ -167,14 +294,16 @@ func (ws *WebServer) handleStart(w http.ResponseWriter, r *http.Request) {
 		Stability:   randVal(),
 	}
 	ws.orchestrator.sim.state.MaxTurns = cfg.MaxTurns
+	ws.orchestrator.sim.stateMu.Unlock()
 
+	st := ws.orchestrator.sim.snapshotState()
 	response := GameStateResponse{
-		Turn:       ws.orchestrator.sim.state.Turn,
-		MaxTurns:   ws.orchestrator.sim.state.MaxTurns,
-		Metrics:    ws.orchestrator.sim.state.Metrics,
+		Turn:       st.Turn,
+		MaxTurns:   st.MaxTurns,
+		Metrics:    st.Metrics,
 		IsComplete: false,
-		History:    ws.orchestrator.sim.state.History,
-		Stats:      ws.orchestrator.sim.state.Stats,
+		History:    st.History,
+		Stats:      ws.orchestrator.sim.usageStats(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -183,14 +312,15 @@ func (ws *WebServer) handleStart(w http.ResponseWriter, r *http.Request) {
 
 // handleGetState returns current game state
 func (ws *WebServer) handleGetState(w http.ResponseWriter, r *http.Request) {
+	st := ws.orchestrator.sim.snapshotState()
 	response := GameStateResponse{
-		Turn:       ws.orchestrator.sim.state.Turn,
-		MaxTurns:   ws.orchestrator.sim.state.MaxTurns,
-		Metrics:    ws.orchestrator.sim.state.Metrics,
-		IsComplete: ws.orchestrator.IsGameComplete(),
-		CurrentTurn: ws.orchestrator.sim.state.CurrentTurn,
-		History:    ws.orchestrator.sim.state.History,
-		Stats:      ws.orchestrator.sim.state.Stats,
+		Turn:       st.Turn,
+		MaxTurns:   st.MaxTurns,
+		Metrics:    st.Metrics,
+		IsComplete: st.Turn > st.MaxTurns,
+		CurrentTurn: st.CurrentTurn,
+		History:    st.History,
+		Stats:      ws.orchestrator.sim.usageStats(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -223,6 +353,32 @@ func (ws *WebServer) handleNewTurn(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(turnResult)
 }
 
+// decodeBody decodes the JSON request body into dst, reading at most the configured body limit.
+// On failure it writes 413 for an oversized body or 400 otherwise and returns false.
+func (ws *WebServer) decodeBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	limit := ws.orchestrator.sim.maxBodyBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("request body exceeds %d bytes", limit), http.StatusRequestEntityTooLarge)
+			return false
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// validReasoning writes 400 and returns false when the player's reasoning is over the configured length
+func (ws *WebServer) validReasoning(w http.ResponseWriter, reasoning string) bool {
+	if limit := ws.orchestrator.sim.maxReasoningChars(); utf8.RuneCountInString(reasoning) > limit {
+		http.Error(w, fmt.Sprintf("reasoning exceeds %d characters", limit), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
 // handlePlayerChoice processes the player's decision (legacy)
 func (ws *WebServer) handlePlayerChoice(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -237,17 +393,17 @@ func (ws *WebServer) handlePlayerChoice(w http.ResponseWriter, r *http.Request)
 		Turn        string `json:"turn"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !ws.decodeBody(w, r, &request) || !ws.validReasoning(w, request.Reasoning) {
 		return
 	}
 
-	// Determine which turn to apply to (use current active turn)
-	turnResult := ws.orchestrator.sim.state.CurrentTurn
-	if turnResult == nil {
+	// Determine which turn to apply to (a copy of the current active turn)
+	current := ws.orchestrator.sim.currentTurn()
+	if current == nil {
 		http.Error(w, "no active turn", http.StatusBadRequest)
 		return
 	}
+	turnResult := *current
 
 	// Derive choice index: if explicit, use; else match against options; else 0
 	choiceIndex := 0
@@ -273,20 +429,21 @@ func (ws *WebServer) handlePlayerChoice(w http.ResponseWriter, r *http.Request)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	if err := ws.orchestrator.ProcessPlayerChoice(ctx, turnResult, choiceIndex, request.Reasoning); err != nil {
+	if err := ws.orchestrator.ProcessPlayerChoice(ctx, &turnResult, choiceIndex, request.Reasoning); err != nil {
 		log.Printf("Error processing player choice: %v", err)
 		http.Error(w, fmt.Sprintf("Failed to process choice: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	st := ws.orchestrator.sim.snapshotState()
 	response := GameStateResponse{
-		Turn:        ws.orchestrator.sim.state.Turn,
-		MaxTurns:    ws.orchestrator.sim.state.MaxTurns,
-		Metrics:     ws.orchestrator.sim.state.Metrics,
-		IsComplete:  ws.orchestrator.IsGameComplete(),
+		Turn:        st.Turn,
+		MaxTurns:    st.MaxTurns,
+		Metrics:     st.Metrics,
+		IsComplete:  st.Turn > st.MaxTurns,
 		CurrentTurn: nil,
-		History:     ws.orchestrator.sim.state.History,
-		Stats:       ws.orchestrator.sim.state.Stats,
+		History:     st.History,
+		Stats:       ws.orchestrator.sim.usageStats(),
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -355,17 +512,8 @@ func (ws *WebServer) handleNewRound(w http.ResponseWriter, r *http.Request) {
 
 	// If already complete, return newspaper now
 	if ws.orchestrator.IsGameComplete() {
-		paper := buildEndgameNewspaper(ws.orchestrator.sim.state)
-		resp := NewRoundResponse{
-			GameOver:  true,
-			Turn:      ws.orchestrator.sim.state.Turn,
-			MaxTurns:  ws.orchestrator.sim.state.MaxTurns,
-			Metrics:   &ws.orchestrator.sim.state.Metrics,
-			Newspaper: paper,
-			Stats:     ws.orchestrator.sim.state.Stats,
-		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
+		json.NewEncoder(w).Encode(ws.gameOverResponse(r.Context()))
 		return
 	}
 
@@ -374,78 +522,224 @@ func (ws *WebServer) handleNewRound(w http.ResponseWriter, r *http.Request) {
 	turnResult, err := ws.orchestrator.StartNewTurn(ctx)
 	if err != nil {
 		// If exceeded rounds, return newspaper instead of error
-		paper := buildEndgameNewspaper(ws.orchestrator.sim.state)
-		resp := NewRoundResponse{
-			GameOver:  true,
-			Turn:      ws.orchestrator.sim.state.Turn,
-			MaxTurns:  ws.orchestrator.sim.state.MaxTurns,
-			Metrics:   &ws.orchestrator.sim.state.Metrics,
-			Newspaper: paper,
-			Stats:     ws.orchestrator.sim.state.Stats,
-		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
+		json.NewEncoder(w).Encode(ws.gameOverResponse(r.Context()))
 		return
 	}
 
-	// Best-effort: if no image yet, generate one now so it can be embedded in the event message
+	ws.ensureEventImage(ctx, turnResult)
+	msgs := ws.buildRoundMessages(turnResult)
+
+	st := ws.orchestrator.sim.snapshotState()
+	resp := NewRoundResponse{
+		GameOver:   false,
+		Turn:       st.Turn,
+		MaxTurns:   st.MaxTurns,
+		TurnResult: turnResult,
+		Metrics:    &st.Metrics, // include current metrics
+		Stats:      ws.orchestrator.sim.usageStats(),
+		Messages:   msgs,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ensureEventImage generates the event image synchronously when the async one is not ready yet
+// (best-effort), setting it on turnResult (the caller's copy) and on the published current turn
+func (ws *WebServer) ensureEventImage(ctx context.Context, turnResult *TurnResult) {
 	if strings.TrimSpace(turnResult.Event.ImageURL) == "" {
-		client := imgc.New()
-		if url, err := client.Generate(ctx, buildBBCPhotoPrompt(&turnResult.Event), 800, 450); err == nil && strings.TrimSpace(url) != "" {
+		if url, err := ws.orchestrator.sim.eventImage(ctx, &turnResult.Event, turnImageWidth, turnImageHeight); err == nil && strings.TrimSpace(url) != "" {
 			turnResult.Event.ImageURL = url
+			ws.orchestrator.sim.setEventImage(turnResult.Event.ID, url)
 		} else if err != nil {
 			log.Printf("[IMAGE] sync generation failed: %v", err)
 		}
 	}
+}
 
+// buildRoundMessages renders the event and advisor responses of a turn as chat feed messages
+func (ws *WebServer) buildRoundMessages(turnResult *TurnResult) []ChatMessage {
 	// Build message list: 1) Event message (with optional image) 2) Advisor messages
 	msgs := make([]ChatMessage, 0, 1+len(turnResult.Advisors))
 	baseTime := time.Now().UTC()
-	timestamp := baseTime.UnixMilli()
-	
-	evt := turnResult.Event
+	msgs = append(msgs, ws.eventMessage(turnResult.Event, baseTime))
+	for i, a := range turnResult.Advisors {
+		msgs = append(msgs, ws.advisorMessage(a, baseTime.Add(time.Duration(i+1)*time.Second)))
+	}
+	return msgs
+}
+
+// eventMessage renders a turn's event as the opening chat feed message
+func (ws *WebServer) eventMessage(evt GameEvent, at time.Time) ChatMessage {
 	// Compose event message with embedded image if present
 	eventText := fmt.Sprintf("**%s**\n\n%s", evt.Title, evt.Description)
 	if strings.TrimSpace(evt.ImageURL) != "" {
 		eventText = fmt.Sprintf("**%s**\n\n%s\n\n![Event image](%s)", evt.Title, evt.Description, evt.ImageURL)
 	}
-	msgs = append(msgs, ChatMessage{
-		ID:             fmt.Sprintf("event_%d_%d", ws.orchestrator.sim.state.Turn, timestamp),
+	timestamp := at.UnixMilli()
+	st := ws.orchestrator.sim.snapshotState()
+	return ChatMessage{
+		ID:             fmt.Sprintf("event_%d_%d", st.Turn, timestamp),
 		Name:           "", // no sender shown
 		Text:           eventText,
 		Title:          "", // no role/title
 		TitleColor:     colorForCategory(evt.Category),
-		Time:           baseTime.Format(time.RFC3339),
+		Time:           at.Format(time.RFC3339),
 		Timestamp:      timestamp,
 		ProfilePicture: "", // no avatar
-	})
-	
-	for i, a := range turnResult.Advisors {
-		advisorTime := baseTime.Add(time.Duration(i+1) * time.Second)
-		advisorTimestamp := advisorTime.UnixMilli()
-		msgs = append(msgs, ChatMessage{
-			ID:             fmt.Sprintf("advisor_%s_%d_%d", a.AdvisorID, ws.orchestrator.sim.state.Turn, advisorTimestamp),
-			Name:           a.AdvisorName,
-			Text:           a.Advice,
-			Title:          a.Title,
-			TitleColor:     colorForSpecialty(strings.ToLower(findAdvisorSpecialty(ws.orchestrator.sim.state.Advisors, a.AdvisorID))),
-			Time:           advisorTime.Format(time.RFC3339),
-			Timestamp:      advisorTimestamp,
-			ProfilePicture: avatarURL(a.AdvisorID),
-		})
 	}
+}
+
+// advisorMessage renders one advisor's response as a chat feed message
+func (ws *WebServer) advisorMessage(a AdvisorResponse, at time.Time) ChatMessage {
+	timestamp := at.UnixMilli()
+	st := ws.orchestrator.sim.snapshotState()
+	return ChatMessage{
+		ID:             fmt.Sprintf("advisor_%s_%d_%d", a.AdvisorID, st.Turn, timestamp),
+		Name:           a.AdvisorName,
+		Text:           a.Advice,
+		Title:          a.Title,
+		TitleColor:     colorForSpecialty(strings.ToLower(findAdvisorSpecialty(st.Advisors, a.AdvisorID))),
+		Time:           at.Format(time.RFC3339),
+		Timestamp:      timestamp,
+		ProfilePicture: avatarURL(a.AdvisorID),
+		Conviction:     &a.Conviction,
+	}
+}
 
-	resp := NewRoundResponse{
+// handleNewRoundStream is handleNewRound over Server-Sent Events. It sends an "event" message
+// as soon as the event is generated, an "advisor" message as each advisor responds, then
+// "done" with the full NewRoundResponse. A finished game sends only "done" with the newspaper.
+func (ws *WebServer) handleNewRoundStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	send, ok := startSSE(w)
+	if !ok {
+		return
+	}
+
+	if ws.orchestrator.IsGameComplete() {
+		send("done", ws.gameOverResponse(r.Context()))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 35*time.Second)
+	defer cancel()
+	updates := make(chan TurnUpdate)
+	type turnOutcome struct {
+		turn *TurnResult
+		err  error
+	}
+	outcome := make(chan turnOutcome, 1)
+	go func() {
+		turn, err := ws.orchestrator.StartNewTurnStream(ctx, updates)
+		outcome <- turnOutcome{turn, err}
+		close(updates)
+	}()
+
+	baseTime := time.Now().UTC()
+	advisors := 0
+	for u := range updates {
+		switch {
+		case u.Event != nil:
+			send("event", ws.eventMessage(*u.Event, baseTime))
+		case u.Advisor != nil:
+			advisors++
+			send("advisor", ws.advisorMessage(*u.Advisor, baseTime.Add(time.Duration(advisors)*time.Second)))
+		}
+	}
+	res := <-outcome
+	if res.err != nil {
+		log.Printf("[SSE] new round failed: %v", res.err)
+		send("done", ws.gameOverResponse(r.Context()))
+		return
+	}
+	ws.ensureEventImage(ctx, res.turn)
+	st := ws.orchestrator.sim.snapshotState()
+	send("done", NewRoundResponse{
 		GameOver:   false,
-		Turn:       ws.orchestrator.sim.state.Turn,
-		MaxTurns:   ws.orchestrator.sim.state.MaxTurns,
-		TurnResult: turnResult,
-		Metrics:    &ws.orchestrator.sim.state.Metrics, // include current metrics
-		Stats:      ws.orchestrator.sim.state.Stats,
-		Messages:   msgs,
+		Turn:       st.Turn,
+		MaxTurns:   st.MaxTurns,
+		TurnResult: res.turn,
+		Metrics:    &st.Metrics,
+		Stats:      ws.orchestrator.sim.usageStats(),
+		Messages:   ws.buildRoundMessages(res.turn),
+	})
+}
+
+// startSSE sets the Server-Sent Events headers and returns a function sending one JSON-encoded
+// message per call. It writes a 500 and returns false when w cannot stream.
+func startSSE(w http.ResponseWriter) (func(name string, v interface{}), bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	return func(name string, v interface{}) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			log.Printf("[SSE] failed to encode %s: %v", name, err)
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+		flusher.Flush()
+	}, true
+}
+
+// handleNewspaperStream streams the endgame newspaper over Server-Sent Events: a "headline" then a
+// "body" message per turn as the model writes each section, then "done". If the LLM recap fails
+// or is disabled, the deterministic newspaper is sent in one "newspaper" message before "done".
+func (ws *WebServer) handleNewspaperStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !ws.orchestrator.IsGameComplete() {
+		http.Error(w, "game not complete", http.StatusBadRequest)
+		return
+	}
+	send, ok := startSSE(w)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	sections := make(chan NewspaperTurn)
+	result := make(chan error, 1)
+	go func() {
+		result <- ws.orchestrator.StreamNewspaper(ctx, sections)
+		close(sections)
+	}()
+	for sec := range sections {
+		send("headline", NewspaperTurn{Turn: sec.Turn, Headline: sec.Headline})
+		send("body", NewspaperTurn{Turn: sec.Turn, Body: sec.Body})
+	}
+	if err := <-result; err != nil {
+		log.Printf("[NEWSPAPER] streamed recap failed: %v (using deterministic newspaper)", err)
+		state := ws.orchestrator.sim.snapshotState()
+		send("newspaper", map[string]string{"newspaper": buildEndgameNewspaper(&state, ws.orchestrator.sim.scoreWeights())})
+	}
+	send("done", struct{}{})
+}
+
+// gameOverResponse is the NewRoundResponse returned once no further round can be played; ctx bounds
+// the newspaper generation
+func (ws *WebServer) gameOverResponse(ctx context.Context) NewRoundResponse {
+	st := ws.orchestrator.sim.snapshotState()
+	return NewRoundResponse{
+		GameOver:  true,
+		Turn:      st.Turn,
+		MaxTurns:  st.MaxTurns,
+		Metrics:   &st.Metrics,
+		Newspaper: ws.orchestrator.EndgameNewspaper(ctx),
+		Stats:     ws.orchestrator.sim.usageStats(),
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
 }
 
 func findAdvisorSpecialty(list []Advisor, id string) string {
@@ -466,16 +760,16 @@ func (ws *WebServer) handleEvaluateChoice(w http.ResponseWriter, r *http.Request
 		ChoiceIndex *int   `json:"choiceIndex"`
 		Choice      string `json:"choice"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !ws.decodeBody(w, r, &request) || !ws.validReasoning(w, request.Reasoning) {
 		return
 	}
 
-	turnResult := ws.orchestrator.sim.state.CurrentTurn
-	if turnResult == nil {
+	current := ws.orchestrator.sim.currentTurn()
+	if current == nil {
 		http.Error(w, "no active turn", http.StatusBadRequest)
 		return
 	}
+	turnResult := *current
 	choiceIndex := 0
 	if request.ChoiceIndex != nil {
 		choiceIndex = *request.ChoiceIndex
@@ -487,14 +781,15 @@ func (ws *WebServer) handleEvaluateChoice(w http.ResponseWriter, r *http.Request
 
 	ctx, cancel := context.WithTimeout(context.Background(), 35*time.Second)
 	defer cancel()
-	if err := ws.orchestrator.ProcessPlayerChoice(ctx, turnResult, choiceIndex, request.Reasoning); err != nil {
+	if err := ws.orchestrator.ProcessPlayerChoice(ctx, &turnResult, choiceIndex, request.Reasoning); err != nil {
 		log.Printf("Error processing player choice: %v", err)
 		http.Error(w, fmt.Sprintf("Failed to process choice: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	// Last history item has evaluation and impact
-	hist := ws.orchestrator.sim.state.History
+	st := ws.orchestrator.sim.snapshotState()
+	hist := st.History
 	last := hist[len(hist)-1]
 
 	evalTime := time.Now().UTC()
@@ -502,7 +797,7 @@ func (ws *WebServer) handleEvaluateChoice(w http.ResponseWriter, r *http.Request
 
 	// Build a metrics line showing current value with delta in brackets, omit brackets if zero
 	impact := last.Impact
-	curr := ws.orchestrator.sim.state.Metrics
+	curr := st.Metrics
 	fmtMetric := func(val, delta float64) string {
 		v := int(math.Round(val))
 		d := int(math.Round(delta))
@@ -528,7 +823,7 @@ func (ws *WebServer) handleEvaluateChoice(w http.ResponseWriter, r *http.Request
 
 	msgs := []ChatMessage{
 		{
-			ID:             fmt.Sprintf("evaluation_%d_%d", ws.orchestrator.sim.state.Turn, evalTimestamp),
+			ID:             fmt.Sprintf("evaluation_%d_%d", st.Turn, evalTimestamp),
 			Name:           "",
 			Text:           msgText,
 			Title:          "",
@@ -541,7 +836,7 @@ func (ws *WebServer) handleEvaluateChoice(w http.ResponseWriter, r *http.Request
 
 	// If game is complete after this move, append a concise system message and include newspaper
 	var newspaper string
-	if ws.orchestrator.IsGameComplete() {
+	if st.Turn > st.MaxTurns {
 		msgs = append(msgs, ChatMessage{
 			ID:        fmt.Sprintf("system_gameover_%d", evalTimestamp),
 			Name:      "",
@@ -550,17 +845,17 @@ func (ws *WebServer) handleEvaluateChoice(w http.ResponseWriter, r *http.Request
 			Time:      evalTime.Format(time.RFC3339),
 			Timestamp: evalTimestamp + 1,
 		})
-		newspaper = buildEndgameNewspaper(ws.orchestrator.sim.state)
+		newspaper = ws.orchestrator.EndgameNewspaper(r.Context())
 	}
 
 	resp := EvaluateResponse{
 		Evaluation: shortEval,
 		Impact:     last.Impact,
-		Metrics:    ws.orchestrator.sim.state.Metrics,
-		IsComplete: ws.orchestrator.IsGameComplete(),
-		Turn:       ws.orchestrator.sim.state.Turn,
-		MaxTurns:   ws.orchestrator.sim.state.MaxTurns,
-		Stats:      ws.orchestrator.sim.state.Stats,
+		Metrics:    st.Metrics,
+		IsComplete: st.Turn > st.MaxTurns,
+		Turn:       st.Turn,
+		MaxTurns:   st.MaxTurns,
+		Stats:      ws.orchestrator.sim.usageStats(),
 		Messages:   msgs,
 	}
 	// If complete, also piggy-back a newspaper via a header for frontend to pick up (optional)
@@ -578,7 +873,7 @@ func (ws *WebServer) handleStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ws.orchestrator.sim.state.Stats)
+	json.NewEncoder(w).Encode(ws.orchestrator.sim.usageStats())
 }
 
 // handleGenerateImage generates a BBC/AP style image for the current event and returns the URL
@@ -587,44 +882,44 @@ func (ws *WebServer) handleGenerateImage(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	turn := ws.orchestrator.sim.state.CurrentTurn
+	turn := ws.orchestrator.sim.currentTurn()
 	if turn == nil {
 		http.Error(w, "no active turn", http.StatusBadRequest)
 		return
 	}
+	evt := turn.Event
 	// Optional body: { width?: number, height?: number }
 	var req struct{ Width, Height int }
-	_ = json.NewDecoder(r.Body).Decode(&req)
+	_ = json.NewDecoder(http.MaxBytesReader(w, r.Body, ws.orchestrator.sim.maxBodyBytes())).Decode(&req)
 	if req.Width <= 0 { req.Width = 800 }
 	if req.Height <= 0 { req.Height = 450 }
 
 	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
 	defer cancel()
 
-	prompt := buildBBCPhotoPrompt(&turn.Event)
-	client := imgc.New()
-	url, err := client.Generate(ctx, prompt, req.Width, req.Height)
+	url, err := ws.orchestrator.sim.eventImage(ctx, &evt, req.Width, req.Height)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("image generation failed: %v", err), http.StatusBadGateway)
 		return
 	}
 	// Attach to current event
-	turn.Event.ImageURL = url
+	ws.orchestrator.sim.setEventImage(evt.ID, url)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{
-		"eventId": turn.Event.ID,
+		"eventId": evt.ID,
 		"imageUrl": url,
 	})
 }
 
 // buildEndgameNewspaper creates a simple newspaper-style summary of the run
-func buildEndgameNewspaper(state *GameState) string {
+func buildEndgameNewspaper(state *GameState, weights WorldMetricsWeights) string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "🗞️ NATIONAL LEDGER — PRESIDENCY COMPLETE\n")
 	fmt.Fprintf(&b, "=======================================\n\n")
 	fmt.Fprintf(&b, "Final Metrics — Economy %.1f | Security %.1f | Diplomacy %.1f | Environment %.1f | Approval %.1f | Stability %.1f\n\n",
 		state.Metrics.Economy, state.Metrics.Security, state.Metrics.Diplomacy, state.Metrics.Environment, state.Metrics.Approval, state.Metrics.Stability)
+	fmt.Fprintf(&b, "Final Score %.1f/100 (%s)\n\n", calculateFinalScore(state.Metrics, weights), formatScoreWeights(weights))
 	for _, t := range state.History {
 		fmt.Fprintf(&b, "TURN %d — %s (%s, sev %d/10)\n", t.Turn, t.Event.Title, t.Event.Category, t.Event.Severity)
 		// Print first line of evaluation
@@ -651,3 +946,93 @@ func firstNSentences(s string, n int) string {
 	out := strings.TrimSpace(strings.Join(parts, " "))
 	return out
 }
+
+// handleReroll discards the current event (and its advisor opinions) and returns a fresh one for the same turn
+func (ws *WebServer) handleReroll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 35*time.Second)
+	defer cancel()
+	if err := ws.orchestrator.RerollEvent(ctx); err != nil {
+		switch {
+		case errors.Is(err, errNoActiveTurn):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, errRerollLimit):
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		case errors.Is(err, errSuperseded):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	st := ws.orchestrator.sim.snapshotState()
+	if st.CurrentTurn == nil {
+		http.Error(w, "turn superseded while rerolling", http.StatusConflict)
+		return
+	}
+	turnResult := st.CurrentTurn
+	ws.ensureEventImage(ctx, turnResult)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"turn":           st.Turn,
+		"maxTurns":       st.MaxTurns,
+		"turnResult":     turnResult,
+		"rerollsUsed":    st.Rerolls,
+		"rerollsAllowed": ws.orchestrator.sim.maxRerollsPerTurn(),
+		"messages":       ws.buildRoundMessages(turnResult),
+	})
+}
+
+// handleSave serializes the current game and returns a token that /api/load accepts
+func (ws *WebServer) handleSave(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	token, err := ws.orchestrator.sim.SaveState(ctx)
+	if err != nil {
+		log.Printf("Error saving game: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"token": token, "turn": ws.orchestrator.sim.snapshotState().Turn})
+}
+
+// handleLoad restores the game saved under {"token": "..."} and returns the resulting state
+func (ws *WebServer) handleLoad(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Token string `json:"token"`
+	}
+	if !ws.decodeBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Token) == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	// Work still running for the pre-load event must not write into the restored state
+	ws.orchestrator.beginTurnWork()
+	if err := ws.orchestrator.sim.LoadState(ctx, strings.TrimSpace(req.Token)); err != nil {
+		if errors.Is(err, errSaveNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error loading game: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ws.handleGetState(w, r)
+}
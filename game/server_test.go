@@ -0,0 +1,444 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	fw "github.com/emergent-world-engine/backend/pkg/framework"
+)
+
+// newTestServer wires a web server to a fresh test simulator
+func newTestServer(t *testing.T) *WebServer {
+	t.Helper()
+	return NewWebServer(NewGameOrchestrator(newTestSim(t)), "0")
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	ws := newTestServer(t)
+	state := ws.orchestrator.sim.state
+	state.Turn = 3
+	state.Metrics.Economy = 77
+	state.History = append(state.History, TurnResult{Turn: 1, Event: GameEvent{ID: "evt_1", Title: "Border Standoff"}})
+
+	rec := httptest.NewRecorder()
+	ws.handleSave(rec, httptest.NewRequest(http.MethodPost, "/api/save", nil))
+	var saved struct {
+		Token string `json:"token"`
+		Turn  int    `json:"turn"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&saved); err != nil || rec.Code != http.StatusOK || saved.Token == "" || saved.Turn != 3 {
+		t.Fatalf("Expected a save token for turn 3, got %d %+v (%v)", rec.Code, saved, err)
+	}
+
+	state.Turn, state.Metrics.Economy, state.History = 5, 1, nil
+	rec = httptest.NewRecorder()
+	ws.handleLoad(rec, httptest.NewRequest(http.MethodPost, "/api/load", strings.NewReader(`{"token": "`+saved.Token+`"}`)))
+	var loaded GameStateResponse
+	if err := json.NewDecoder(rec.Body).Decode(&loaded); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Expected the save to load, got %d (%v)", rec.Code, err)
+	}
+	if loaded.Turn != 3 || loaded.Metrics.Economy != 77 || len(loaded.History) != 1 || loaded.History[0].Event.Title != "Border Standoff" {
+		t.Errorf("Expected the saved state back, got %+v", loaded)
+	}
+	if state.Turn != 3 || len(state.History) != 1 {
+		t.Errorf("Expected the simulator state to be restored, got turn %d with %d turns of history", state.Turn, len(state.History))
+	}
+}
+
+func TestLoadUnknownToken(t *testing.T) {
+	ws := newTestServer(t)
+	rec := httptest.NewRecorder()
+	ws.handleLoad(rec, httptest.NewRequest(http.MethodPost, "/api/load", strings.NewReader(`{"token": "missing"}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown token, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	ws.handleLoad(rec, httptest.NewRequest(http.MethodPost, "/api/load", strings.NewReader(`{"token": " "}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a blank token, got %d", rec.Code)
+	}
+}
+
+func TestRunDrainsInFlightRequests(t *testing.T) {
+	ws := newTestServer(t)
+	ws.orchestrator.sim.config.ShutdownGrace = 10 * time.Second
+	started, release := make(chan struct{}), make(chan struct{})
+	ws.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	})
+	shuttingDown := make(chan struct{})
+	ws.server.RegisterOnShutdown(func() { close(shuttingDown) })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- ws.run(ctx, ln) }()
+
+	type result struct {
+		status int
+		err    error
+	}
+	respCh := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			respCh <- result{err: err}
+			return
+		}
+		resp.Body.Close()
+		respCh <- result{status: resp.StatusCode}
+	}()
+
+	<-started
+	cancel()
+	<-shuttingDown
+	// The listener is closed before shutdown hooks run, so new connections are refused while draining
+	fresh := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 2 * time.Second}
+	if resp, err := fresh.Get("http://" + ln.Addr().String() + "/late"); err == nil {
+		resp.Body.Close()
+		t.Errorf("Expected a request made while draining to be refused, got %d", resp.StatusCode)
+	}
+	select {
+	case res := <-respCh:
+		t.Fatalf("Expected the in-flight request to still be running, got %+v", res)
+	default:
+	}
+	close(release)
+
+	if res := <-respCh; res.err != nil || res.status != http.StatusOK {
+		t.Errorf("Expected the in-flight request to finish with 200, got %d (%v)", res.status, res.err)
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("Expected a clean shutdown, got %v", err)
+	}
+}
+
+// recordingLogger keeps each message with its level
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *recordingLogger) record(level, f string, a ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, level+" "+fmt.Sprintf(f, a...))
+}
+
+func (l *recordingLogger) Debugf(f string, a ...interface{}) { l.record("DEBUG", f, a...) }
+func (l *recordingLogger) Infof(f string, a ...interface{})  { l.record("INFO", f, a...) }
+func (l *recordingLogger) Warnf(f string, a ...interface{})  { l.record("WARN", f, a...) }
+func (l *recordingLogger) Errorf(f string, a ...interface{}) { l.record("ERROR", f, a...) }
+
+func TestLoggingMiddleware(t *testing.T) {
+	ws := newTestServer(t)
+	logger := &recordingLogger{}
+	ws.logger = logger
+	cases := []struct {
+		status int
+		level  string
+	}{
+		{http.StatusOK, "INFO"},
+		{http.StatusNotFound, "WARN"},
+		{http.StatusInternalServerError, "ERROR"},
+	}
+	for _, c := range cases {
+		handler := ws.loggingMiddleware(func(w http.ResponseWriter, r *http.Request) {
+			if c.status != http.StatusOK {
+				http.Error(w, "failed", c.status)
+				return
+			}
+			w.Write([]byte("ok"))
+		})
+		req := httptest.NewRequest(http.MethodGet, "/api/state", nil)
+		req.Header.Set("X-Request-ID", "req-1")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Header().Get("X-Request-ID") != "req-1" {
+			t.Errorf("Expected the request ID to be echoed, got %q", rec.Header().Get("X-Request-ID"))
+		}
+		last := logger.entries[len(logger.entries)-1]
+		want := fmt.Sprintf("%s [req-1] GET /api/state -> %d", c.level, c.status)
+		if !strings.HasPrefix(last, want) {
+			t.Errorf("Expected a log line starting %q, got %q", want, last)
+		}
+	}
+}
+
+// sseFrame is one Server-Sent Events message
+type sseFrame struct {
+	event string
+	data  string
+}
+
+// readSSE splits an event stream into frames
+func readSSE(t *testing.T, body io.Reader) []sseFrame {
+	t.Helper()
+	var frames []sseFrame
+	var cur sseFrame
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			cur.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			cur.data = strings.TrimPrefix(line, "data: ")
+		case line == "" && cur.event != "":
+			frames = append(frames, cur)
+			cur = sseFrame{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to read event stream: %v", err)
+	}
+	return frames
+}
+
+func TestNewRoundStream(t *testing.T) {
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"text": "{\"advisor_opinion\": \"Call your allies before you act.\", \"conviction\": 7}"}`))
+	}))
+	defer llm.Close()
+	t.Setenv("LLAMA_CHAT_URL", llm.URL)
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+
+	ws := newTestServer(t)
+	ws.orchestrator.sim.imageGen = &stubImageGen{}
+	srv := httptest.NewServer(ws.server.Handler)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/new-round-stream")
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+	frames := readSSE(t, resp.Body)
+
+	perTurn := ws.orchestrator.sim.advisorsPerTurn()
+	if len(frames) != perTurn+2 {
+		t.Fatalf("Expected an event, %d advisors and done, got %d frames: %+v", perTurn, len(frames), frames)
+	}
+	if frames[0].event != "event" {
+		t.Errorf("Expected the event frame first, got %q", frames[0].event)
+	}
+	for _, f := range frames[1 : perTurn+1] {
+		var msg ChatMessage
+		if f.event != "advisor" || json.Unmarshal([]byte(f.data), &msg) != nil {
+			t.Fatalf("Expected an advisor frame, got %+v", f)
+		}
+		if msg.Text != "Call your allies before you act." || msg.Conviction == nil || *msg.Conviction != 7 {
+			t.Errorf("Expected the stubbed advice with conviction 7, got %+v", msg)
+		}
+	}
+	last := frames[len(frames)-1]
+	var done NewRoundResponse
+	if last.event != "done" || json.Unmarshal([]byte(last.data), &done) != nil {
+		t.Fatalf("Expected a final done frame, got %+v", last)
+	}
+	if done.GameOver || done.TurnResult == nil || len(done.TurnResult.Advisors) != perTurn || done.TurnResult.Event.ImageURL != "https://img.test/800x450.png" {
+		t.Errorf("Expected the finished turn with its image, got %+v", done)
+	}
+}
+
+func TestEventImageCache(t *testing.T) {
+	ws := newTestServer(t)
+	sim := ws.orchestrator.sim
+	gen := &stubImageGen{}
+	sim.imageGen = gen
+	sim.state.CurrentTurn = &TurnResult{Turn: 1, Event: GameEvent{ID: "evt_1", Title: "Border Standoff", Category: "security"}}
+
+	generate := func(body string) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		ws.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/generate-image", strings.NewReader(body)))
+		var resp struct {
+			EventID  string `json:"eventId"`
+			ImageURL string `json:"imageUrl"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || rec.Code != http.StatusOK || resp.EventID != "evt_1" {
+			t.Fatalf("Expected an image for evt_1, got %d %+v (%v)", rec.Code, resp, err)
+		}
+		return resp.ImageURL
+	}
+
+	first := generate(`{}`)
+	if first != "https://img.test/800x450.png" {
+		t.Fatalf("Expected a generated image at the default size, got %q", first)
+	}
+	if again := generate(`{"width": 800, "height": 450}`); again != first || gen.calls.Load() != 1 {
+		t.Errorf("Expected the second request to hit the cache, got %q after %d generations", again, gen.calls.Load())
+	}
+	if thumb := generate(`{"width": 400, "height": 225}`); thumb != "https://img.test/400x225.png" || gen.calls.Load() != 2 {
+		t.Errorf("Expected a different size to generate its own image, got %q after %d generations", thumb, gen.calls.Load())
+	}
+	if url := sim.currentTurn().Event.ImageURL; url == "" {
+		t.Errorf("Expected the generated image to be attached to the current event")
+	}
+}
+
+func TestRerollEvent(t *testing.T) {
+	var ws *WebServer
+	var supersede atomic.Bool
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if supersede.CompareAndSwap(true, false) {
+			ws.orchestrator.beginTurnWork() // another turn starts while the reroll's advisors are running
+		}
+		w.Write([]byte(`{"text": "{\"advisor_opinion\": \"Hold the line.\", \"conviction\": 6}"}`))
+	}))
+	defer llm.Close()
+	t.Setenv("LLAMA_CHAT_URL", llm.URL)
+	t.Setenv("GOOGLE_AI_API_KEY", "")
+
+	ws = newTestServer(t)
+	sim := ws.orchestrator.sim
+	sim.imageGen = &stubImageGen{}
+	sim.config.MaxRerollsPerTurn = 1
+	ctx := context.Background()
+
+	reroll := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ws.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/reroll", nil))
+		return rec
+	}
+
+	if rec := reroll(); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a 400 without an active turn, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, err := ws.orchestrator.StartNewTurn(ctx); err != nil {
+		t.Fatalf("StartNewTurn failed: %v", err)
+	}
+
+	supersede.Store(true)
+	if rec := reroll(); rec.Code != http.StatusConflict {
+		t.Errorf("Expected a 409 when the turn is superseded mid-reroll, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if st := sim.snapshotState(); st.Rerolls != 0 {
+		t.Errorf("Expected a superseded reroll not to count, got %d used", st.Rerolls)
+	}
+
+	rec := reroll()
+	var resp struct {
+		Turn           int         `json:"turn"`
+		TurnResult     *TurnResult `json:"turnResult"`
+		RerollsUsed    int         `json:"rerollsUsed"`
+		RerollsAllowed int         `json:"rerollsAllowed"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Expected the reroll to succeed, got %d (%v)", rec.Code, err)
+	}
+	if resp.Turn != 1 || resp.RerollsUsed != 1 || resp.RerollsAllowed != 1 || resp.TurnResult == nil || len(resp.TurnResult.Advisors) != sim.advisorsPerTurn() {
+		t.Errorf("Expected a fresh turn 1 event with its advisors and 1 of 1 rerolls used, got %+v", resp)
+	}
+	if rec := reroll(); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected a 429 past the reroll cap, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	current := *sim.currentTurn()
+	if err := ws.orchestrator.ProcessPlayerChoice(ctx, &current, 0, "Open talks."); err != nil {
+		t.Fatalf("ProcessPlayerChoice failed: %v", err)
+	}
+	if st := sim.snapshotState(); st.Turn != 2 || st.Rerolls != 0 {
+		t.Errorf("Expected turn 2 with the reroll counter reset, got turn %d with %d used", st.Turn, st.Rerolls)
+	}
+	if _, err := ws.orchestrator.StartNewTurn(ctx); err != nil {
+		t.Fatalf("StartNewTurn failed: %v", err)
+	}
+	if rec := reroll(); rec.Code != http.StatusOK {
+		t.Errorf("Expected a reroll on the next turn, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRequestBodyLimits(t *testing.T) {
+	ws := newTestServer(t)
+	ws.orchestrator.sim.config.MaxBodyBytes = 128
+	ws.orchestrator.sim.config.MaxReasoningChars = 20
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ws.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec
+	}
+
+	oversized := `{"reasoning": "` + strings.Repeat("a", 256) + `"}`
+	for _, path := range []string{"/api/choice", "/api/evaluate-choice"} {
+		rec := post(path, oversized)
+		if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), "exceeds 128 bytes") {
+			t.Errorf("%s: expected a 413 for an oversized body, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := post("/api/choice", `{"reasoning": "`+strings.Repeat("a", 40)+`"}`); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "exceeds 20 characters") {
+		t.Errorf("Expected a 400 for overlong reasoning, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("/api/choice", `{"reasoning": `); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a 400 for malformed JSON, got %d", rec.Code)
+	}
+}
+
+func TestNewspaperStreamTurnOrder(t *testing.T) {
+	var ws *WebServer
+	turnRE := regexp.MustCompile(`covering turn (\d+)`)
+	llm := &stubLLM{reply: func(prompt string) string {
+		turn := turnRE.FindStringSubmatch(prompt)[1]
+		if turn == "1" {
+			// A new game starting mid-stream must not change the recap being written
+			sim := ws.orchestrator.sim
+			sim.stateMu.Lock()
+			sim.state.History = []TurnResult{}
+			sim.stateMu.Unlock()
+		}
+		return `{"headline": "TURN ` + turn + ` HEADLINE", "article": "What happened in turn ` + turn + `."}`
+	}}
+	sim := newTestSim(t, fw.WithProviders(llm))
+	for i := 1; i <= 3; i++ {
+		sim.state.History = append(sim.state.History, TurnResult{Turn: i, Event: GameEvent{ID: fmt.Sprintf("evt_%d", i), Title: fmt.Sprintf("Event %d", i)}})
+	}
+	sim.state.Turn = sim.state.MaxTurns + 1
+	ws = NewWebServer(NewGameOrchestrator(sim), "0")
+	srv := httptest.NewServer(ws.server.Handler)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/newspaper-stream")
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	frames := readSSE(t, resp.Body)
+
+	var got []string
+	for _, f := range frames {
+		var sec NewspaperTurn
+		if f.event == "headline" && json.Unmarshal([]byte(f.data), &sec) == nil {
+			got = append(got, fmt.Sprintf("%d:%s", sec.Turn, sec.Headline))
+		}
+	}
+	want := []string{"1:TURN 1 HEADLINE", "2:TURN 2 HEADLINE", "3:TURN 3 HEADLINE"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected headlines %q in turn order, got %q", want, got)
+	}
+	if len(frames) != 7 || frames[1].event != "body" || frames[len(frames)-1].event != "done" {
+		t.Errorf("Expected headline/body pairs then done, got %+v", frames)
+	}
+}
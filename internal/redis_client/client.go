@@ -3,8 +3,13 @@ package redis_client
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -12,35 +17,91 @@ import (
 
 // RedisClient wraps the Redis client with additional functionality
 type RedisClient struct {
-	client   *redis.Client
-	pubsub   *redis.PubSub
-	ctx      context.Context
-	cancel   context.CancelFunc
+	client    *redis.Client
+	subsMu    sync.Mutex
+	subs      map[*redis.PubSub]struct{} // open subscriptions, for Unsubscribe
+	ctx       context.Context // cancelled by Close, ending all subscriptions
+	cancel    context.CancelFunc
+	opTimeout time.Duration
 }
 
+// DefaultOpTimeout bounds a Redis operation whose context has no deadline of its own
+const DefaultOpTimeout = 5 * time.Second
+
 // Config holds Redis client configuration
 type Config struct {
-	Addr     string
-	Password string
-	DB       int
-	PoolSize int
+	Addr      string
+	Username  string
+	Password  string
+	DB        int
+	PoolSize  int
+	TLSConfig *tls.Config   // non-nil enables TLS (rediss://)
+	OpTimeout time.Duration // per-operation timeout for contexts without a deadline; 0 uses DefaultOpTimeout, negative disables
 }
 
 // NewRedisClient creates a new Redis client
 func NewRedisClient(config *Config) *RedisClient {
 	rdb := redis.NewClient(&redis.Options{
-		Addr:     config.Addr,
-		Password: config.Password,
-		DB:       config.DB,
-		PoolSize: config.PoolSize,
+		Addr:      config.Addr,
+		Username:  config.Username,
+		Password:  config.Password,
+		DB:        config.DB,
+		PoolSize:  config.PoolSize,
+		TLSConfig: config.TLSConfig,
+		// Honor context deadlines on reads and writes, not only on the connection pool
+		ContextTimeoutEnabled: true,
 	})
 
+	opTimeout := config.OpTimeout
+	if opTimeout == 0 {
+		opTimeout = DefaultOpTimeout
+	}
+	rdb.AddHook(opTimeoutHook{timeout: opTimeout})
+
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	return &RedisClient{
-		client: rdb,
-		ctx:    ctx,
-		cancel: cancel,
+		client:    rdb,
+		ctx:       ctx,
+		cancel:    cancel,
+		opTimeout: opTimeout,
+	}
+}
+
+// opContext bounds ctx by the client's operation timeout unless it already has a deadline
+func (r *RedisClient) opContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withOpTimeout(ctx, r.opTimeout)
+}
+
+func withOpTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// opTimeoutHook applies the operation timeout to every command and pipeline, including the
+// connection dial and retries, so a call made with context.Background() cannot hang on an
+// unreachable server
+type opTimeoutHook struct {
+	timeout time.Duration
+}
+
+func (h opTimeoutHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h opTimeoutHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, cancel := withOpTimeout(ctx, h.timeout)
+		defer cancel()
+		return next(ctx, cmd)
+	}
+}
+
+func (h opTimeoutHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, cancel := withOpTimeout(ctx, h.timeout)
+		defer cancel()
+		return next(ctx, cmds)
 	}
 }
 
@@ -53,10 +114,11 @@ func NewRedisClientFromEnv() *RedisClient {
 // DefaultConfig returns default Redis configuration
 func DefaultConfig() *Config {
 	return &Config{
-		Addr:     getEnvOrDefault("REDIS_ADDR", "localhost:6379"),
-		Password: getEnvOrDefault("REDIS_PASSWORD", ""),
-		DB:       getEnvIntOrDefault("REDIS_DB", 0),
-		PoolSize: getEnvIntOrDefault("REDIS_POOL_SIZE", 10),
+		Addr:      getEnvOrDefault("REDIS_ADDR", "localhost:6379"),
+		Password:  getEnvOrDefault("REDIS_PASSWORD", ""),
+		DB:        getEnvIntOrDefault("REDIS_DB", 0),
+		PoolSize:  getEnvIntOrDefault("REDIS_POOL_SIZE", 10),
+		OpTimeout: getEnvDurationOrDefault("REDIS_OP_TIMEOUT", DefaultOpTimeout),
 	}
 }
 
@@ -65,11 +127,9 @@ func (r *RedisClient) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
 }
 
-// Close closes the Redis connection
+// Close cancels the client's context, closing every subscription and ending its message
+// channel, then closes the Redis connection
 func (r *RedisClient) Close() error {
-	if r.pubsub != nil {
-		r.pubsub.Close()
-	}
 	r.cancel()
 	return r.client.Close()
 }
@@ -94,6 +154,11 @@ func (r *RedisClient) Get(ctx context.Context, key string, dest interface{}) err
 	return json.Unmarshal([]byte(val), dest)
 }
 
+// IsNotFound reports whether err means the requested key does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, redis.Nil)
+}
+
 // GetString retrieves a string value
 func (r *RedisClient) GetString(ctx context.Context, key string) (string, error) {
 	return r.client.Get(ctx, key).Result()
@@ -109,6 +174,25 @@ func (r *RedisClient) Delete(ctx context.Context, keys ...string) error {
 	return r.client.Del(ctx, keys...).Err()
 }
 
+// Keys returns all keys matching the glob pattern. It walks the keyspace with SCAN cursors
+// instead of KEYS, so large databases are never blocked; keys may repeat if the keyspace
+// changes during the walk.
+func (r *RedisClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+// ScanPrefix returns all keys starting with prefix; glob characters in prefix match literally
+func (r *RedisClient) ScanPrefix(ctx context.Context, prefix string) ([]string, error) {
+	return r.Keys(ctx, globEscaper.Replace(prefix)+"*")
+}
+
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
 // Exists checks if a key exists
 func (r *RedisClient) Exists(ctx context.Context, key string) (bool, error) {
 	count, err := r.client.Exists(ctx, key).Result()
@@ -205,26 +289,115 @@ func (r *RedisClient) Publish(ctx context.Context, channel string, message inter
 	return r.client.Publish(ctx, channel, data).Err()
 }
 
-// Subscribe subscribes to channels and returns a message channel
+// Subscribe subscribes to channels and returns a message channel. The subscription lasts until
+// ctx is cancelled or Close, which close the channel; waiting for its confirmation is bounded by
+// the operation timeout.
 func (r *RedisClient) Subscribe(ctx context.Context, channels ...string) (<-chan *redis.Message, error) {
 	pubsub := r.client.Subscribe(ctx, channels...)
-	r.pubsub = pubsub
-	
+
 	// Wait for subscription confirmation
-	_, err := pubsub.Receive(ctx)
+	confirmCtx, cancel := r.opContext(ctx)
+	defer cancel()
+	_, err := pubsub.Receive(confirmCtx)
 	if err != nil {
+		pubsub.Close()
 		return nil, fmt.Errorf("failed to subscribe: %w", err)
 	}
-	
+
+	r.subsMu.Lock()
+	if r.subs == nil {
+		r.subs = make(map[*redis.PubSub]struct{})
+	}
+	r.subs[pubsub] = struct{}{}
+	r.subsMu.Unlock()
+	var once sync.Once
+	end := func() {
+		once.Do(func() {
+			r.subsMu.Lock()
+			delete(r.subs, pubsub)
+			r.subsMu.Unlock()
+			pubsub.Close()
+		})
+	}
+	context.AfterFunc(ctx, end)
+	context.AfterFunc(r.ctx, end)
+
 	return pubsub.Channel(), nil
 }
 
-// Unsubscribe unsubscribes from channels
+// ForwardGameEvents decodes each message from msgs into a GameEvent and sends it on out until ctx
+// is cancelled or msgs closes. Messages that do not decode are passed to onError and skipped.
+func ForwardGameEvents(ctx context.Context, msgs <-chan *redis.Message, out chan<- GameEvent, onError func(error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			var event GameEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				onError(fmt.Errorf("failed to decode event on %s: %w", msg.Channel, err))
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// SubscribeGameEvents subscribes to channels (ChannelGameEvents if none) and decodes each message
+// into a GameEvent, the counterpart of PublishGameEvent. Messages that do not decode, and a failed
+// subscription, are reported on the error channel, which buffers a few errors and drops the rest
+// when not read. Both channels close when ctx is cancelled or the subscription ends.
+func (r *RedisClient) SubscribeGameEvents(ctx context.Context, channels ...string) (<-chan GameEvent, <-chan error) {
+	out := make(chan GameEvent, 16)
+	errs := make(chan error, 8)
+	if len(channels) == 0 {
+		channels = []string{ChannelGameEvents}
+	}
+	msgs, err := r.Subscribe(ctx, channels...)
+	if err != nil {
+		errs <- err
+		close(out)
+		close(errs)
+		return out, errs
+	}
+	go func() {
+		defer close(errs)
+		defer close(out)
+		ForwardGameEvents(ctx, msgs, out, func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		})
+	}()
+	return out, errs
+}
+
+// Unsubscribe unsubscribes every open subscription from channels (all of their channels if none)
 func (r *RedisClient) Unsubscribe(ctx context.Context, channels ...string) error {
-	if r.pubsub == nil {
+	r.subsMu.Lock()
+	subs := make([]*redis.PubSub, 0, len(r.subs))
+	for pubsub := range r.subs {
+		subs = append(subs, pubsub)
+	}
+	r.subsMu.Unlock()
+	if len(subs) == 0 {
 		return fmt.Errorf("no active subscription")
 	}
-	return r.pubsub.Unsubscribe(ctx, channels...)
+	var errs []error
+	for _, pubsub := range subs {
+		if err := pubsub.Unsubscribe(ctx, channels...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // === Game-Specific Operations ===
@@ -253,6 +426,24 @@ func (r *RedisClient) GetNPCMemories(ctx context.Context, npcID string) (map[str
 	return r.HGetAll(ctx, key)
 }
 
+// StoreNPCSnapshot stores a full NPC snapshot under a single key
+func (r *RedisClient) StoreNPCSnapshot(ctx context.Context, npcID string, snapshot interface{}) error {
+	key := fmt.Sprintf("npc:snapshot:%s", npcID)
+	return r.Set(ctx, key, snapshot, 0) // Snapshots must survive restarts
+}
+
+// GetNPCSnapshot retrieves a full NPC snapshot; found is false when none has been saved
+func (r *RedisClient) GetNPCSnapshot(ctx context.Context, npcID string, dest interface{}) (bool, error) {
+	key := fmt.Sprintf("npc:snapshot:%s", npcID)
+	if err := r.Get(ctx, key, dest); err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // StoreWorldState stores global world state
 func (r *RedisClient) StoreWorldState(ctx context.Context, state interface{}) error {
 	return r.Set(ctx, "world:state", state, 5*time.Minute) // 5 minute expiration
@@ -263,6 +454,29 @@ func (r *RedisClient) GetWorldState(ctx context.Context, dest interface{}) error
 	return r.Get(ctx, "world:state", dest)
 }
 
+// IncrementWorldMetric atomically adds delta to a world metric with HINCRBYFLOAT and returns the
+// new value, so server instances sharing Redis never lose each other's updates
+func (r *RedisClient) IncrementWorldMetric(ctx context.Context, metric string, delta float64) (float64, error) {
+	return r.client.HIncrByFloat(ctx, KeyPatternWorldMetrics, metric, delta).Result()
+}
+
+// GetWorldMetrics retrieves all world metrics set by IncrementWorldMetric
+func (r *RedisClient) GetWorldMetrics(ctx context.Context) (map[string]float64, error) {
+	fields, err := r.client.HGetAll(ctx, KeyPatternWorldMetrics).Result()
+	if err != nil {
+		return nil, err
+	}
+	metrics := make(map[string]float64, len(fields))
+	for name, raw := range fields {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for world metric %s: %w", name, err)
+		}
+		metrics[name] = v
+	}
+	return metrics, nil
+}
+
 // AddActiveQuest adds a quest to the active quests set
 func (r *RedisClient) AddActiveQuest(ctx context.Context, questID string) error {
 	return r.SAdd(ctx, "quests:active", questID)
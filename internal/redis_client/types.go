@@ -4,6 +4,7 @@ package redis_client
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 // Helper functions for environment variable parsing
@@ -14,6 +15,15 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
 func getEnvIntOrDefault(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -47,6 +57,7 @@ const (
 	KeyPatternQuestData    = "quest:data:%s"
 	KeyPatternAssetMeta    = "asset:metadata:%s"
 	KeyPatternWorldState   = "world:state"
+	KeyPatternWorldMetrics = "world:metrics"
 	KeyPatternActiveQuests = "quests:active"
 	KeyPatternActiveNPCs   = "npcs:active"
 )
@@ -5,17 +5,22 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/emergent-world-engine/backend/pkg/textutil"
 )
 
 const (
@@ -30,10 +35,14 @@ type ThetaClient struct {
 	apiKey     string
 	retryAttempts int
 	retryBackoff  time.Duration
+	maxRetryWait  time.Duration // cap for server-requested Retry-After waits
 	rateLimitRPS  int
 	tokens        chan struct{}
 	onceInit      sync.Once
 	metrics       *clientMetrics
+
+	endpointsMu    sync.RWMutex
+	modelEndpoints map[string]string // model -> hosted chat-completion URL, seeded from defaultModelEndpoints
 }
 
 type clientMetrics struct {
@@ -41,6 +50,68 @@ type clientMetrics struct {
 	llmFailures    atomic.Int64
 	llmStreamReqs  atomic.Int64
 	llmStreamTokens atomic.Int64
+	imageRequests   atomic.Int64
+	videoRequests   atomic.Int64
+	ttsRequests     atomic.Int64
+	visionRequests  atomic.Int64
+	model3DRequests atomic.Int64
+
+	usageMu    sync.Mutex
+	usage      map[string]Usage     // accumulated LLM token usage by model
+	modelCosts map[string]ModelCost // per-model prices for TokenUsage.EstimatedCostUSD
+}
+
+// ModelCost is the price of a model's tokens in US dollars per million tokens
+type ModelCost struct {
+	PromptPerMillion     float64
+	CompletionPerMillion float64
+}
+
+// TokenUsage is the LLM token usage accumulated by a client, with its estimated cost.
+// Models missing from the cost table count towards the tokens but not the cost.
+type TokenUsage struct {
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+	EstimatedCostUSD float64
+}
+
+// SetModelCosts sets the per-model cost table used to estimate TokenUsage.EstimatedCostUSD
+func (c *ThetaClient) SetModelCosts(costs map[string]ModelCost) {
+	c.metrics.usageMu.Lock()
+	defer c.metrics.usageMu.Unlock()
+	c.metrics.modelCosts = make(map[string]ModelCost, len(costs))
+	for model, cost := range costs { c.metrics.modelCosts[model] = cost }
+}
+
+// recordUsage adds a response's token usage to the model's running totals
+func (c *ThetaClient) recordUsage(model string, u Usage) {
+	if u.PromptTokens == 0 && u.CompletionTokens == 0 && u.TotalTokens == 0 { return }
+	if u.TotalTokens == 0 { u.TotalTokens = u.PromptTokens + u.CompletionTokens }
+	c.metrics.usageMu.Lock()
+	defer c.metrics.usageMu.Unlock()
+	if c.metrics.usage == nil { c.metrics.usage = make(map[string]Usage) }
+	acc := c.metrics.usage[model]
+	acc.PromptTokens += u.PromptTokens
+	acc.CompletionTokens += u.CompletionTokens
+	acc.TotalTokens += u.TotalTokens
+	c.metrics.usage[model] = acc
+}
+
+// tokenUsage totals the recorded usage across models and prices it with the cost table
+func (c *ThetaClient) tokenUsage() TokenUsage {
+	c.metrics.usageMu.Lock()
+	defer c.metrics.usageMu.Unlock()
+	var t TokenUsage
+	for model, u := range c.metrics.usage {
+		t.PromptTokens += int64(u.PromptTokens)
+		t.CompletionTokens += int64(u.CompletionTokens)
+		t.TotalTokens += int64(u.TotalTokens)
+		if cost, ok := c.metrics.modelCosts[model]; ok {
+			t.EstimatedCostUSD += (float64(u.PromptTokens)*cost.PromptPerMillion + float64(u.CompletionTokens)*cost.CompletionPerMillion) / 1e6
+		}
+	}
+	return t
 }
 
 // NewThetaClient creates a new Theta EdgeCloud client
@@ -51,8 +122,10 @@ func NewThetaClient(baseURL, apiKey string) *ThetaClient {
 		apiKey:        apiKey,
 		retryAttempts: 3,
 		retryBackoff:  200 * time.Millisecond,
+		maxRetryWait:  30 * time.Second,
 		rateLimitRPS:  8,
 		metrics:       &clientMetrics{},
+		modelEndpoints: DefaultModelEndpoints(),
 	}
 	c.initRateLimiter()
 	return c
@@ -88,24 +161,39 @@ func (c *ThetaClient) reinitRateLimiter() {
 	}()
 }
 
-func (c *ThetaClient) acquire() { <-c.tokens }
+// acquire waits for a rate-limit token, giving up when ctx is cancelled
+func (c *ThetaClient) acquire(ctx context.Context) error {
+	select {
+	case <-c.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
 // SetRetry configures retry behaviour
 func (c *ThetaClient) SetRetry(attempts int, backoff time.Duration) { if attempts>0 { c.retryAttempts = attempts }; if backoff>0 { c.retryBackoff = backoff } }
+// SetMaxRetryWait caps how long a Retry-After header may delay the next attempt
+func (c *ThetaClient) SetMaxRetryWait(d time.Duration) { if d>0 { c.maxRetryWait = d } }
 // SetRateLimit sets requests per second
 func (c *ThetaClient) SetRateLimit(rps int) { if rps<=0 { return }; c.rateLimitRPS = rps; c.reinitRateLimiter() }
+// Retry returns the configured attempts per request and the base backoff between them
+func (c *ThetaClient) Retry() (int, time.Duration) { return c.retryAttempts, c.retryBackoff }
+// RateLimit returns the configured requests per second
+func (c *ThetaClient) RateLimit() int { return c.rateLimitRPS }
 
 // LLMRequest represents a request to an LLM model
 type LLMRequest struct {
 	Model         string                 `json:"model"`
 	Prompt        string                 `json:"prompt"`
 	MaxTokens     int                    `json:"max_tokens,omitempty"`
-	Temperature   float64                `json:"temperature,omitempty"`
+	Temperature   *float64               `json:"temperature,omitempty"` // nil leaves the model default; an explicit 0 is sent
 	TopP          float64                `json:"top_p,omitempty"`
 	Stop          []string               `json:"stop,omitempty"`
 	Stream        bool                   `json:"stream,omitempty"`
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 	ResponseFormat interface{}           `json:"response_format,omitempty"`
+	SystemPrompt  string                 `json:"-"` // system message for hosted chat models (empty uses DefaultSystemPrompt); prepended to Prompt on the generic endpoint
 }
 
 // LLMResponse represents a response from an LLM model
@@ -168,8 +256,11 @@ type Image struct {
 type TTSRequest struct {
 	Text     string                 `json:"text"`
 	Voice    string                 `json:"voice,omitempty"`
+	Style    string                 `json:"style,omitempty"` // one of the VoiceStyle* constants
+	Language string                 `json:"language,omitempty"` // BCP 47 code of the text, e.g. "es" or "en-gb"
 	Speed    float64                `json:"speed,omitempty"`
 	Format   string                 `json:"format,omitempty"`
+	Stream   bool                   `json:"stream,omitempty"` // set by GenerateVoiceStream
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -290,35 +381,195 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("Theta API Error [%d]: %s", e.Code, e.Message)
 }
 
+// Is reports whether the API error falls into the ErrRateLimited or ErrServerError class
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.Code == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.Code >= 500
+	}
+	return false
+}
+
+// Error classes surfaced by the client, matched with errors.Is; the failing *APIError
+// itself is reachable with errors.As
+var (
+	// ErrRateLimited means the API kept answering 429 Too Many Requests
+	ErrRateLimited = errors.New("theta rate limited")
+	// ErrServerError means the API kept answering with a 5xx status
+	ErrServerError = errors.New("theta server error")
+	// ErrDecode means the API answered but its response body could not be decoded
+	ErrDecode = errors.New("failed to decode response")
+)
+
+// defaultModelEndpoints maps models served from dedicated chat-completion hosts to their URLs;
+// every other model goes through the generic /v1/inference/llm endpoint.
+var defaultModelEndpoints = map[string]string{
+	"deepseek_r1":   "https://ondemand.thetaedgecloud.com/infer_request/deepseek_r1/completions",
+	"llama_3_1_70b": "https://llama3170b2oczc2osyg-07554694ea35fad5.tec-s20.onthetaedgecloud.com/v1/chat/completions",
+}
+
+// DefaultModelEndpoints returns a copy of the built-in model -> hosted chat endpoint table
+func DefaultModelEndpoints() map[string]string { return maps.Clone(defaultModelEndpoints) }
+
+// SetModelEndpoints overrides the hosted chat endpoint of each model in endpoints, e.g. to follow
+// a rotated or regional Theta host. An empty URL removes the model's hosted endpoint so it goes
+// through the generic LLM endpoint; models not listed keep their current endpoint.
+func (c *ThetaClient) SetModelEndpoints(endpoints map[string]string) {
+	c.endpointsMu.Lock()
+	defer c.endpointsMu.Unlock()
+	for model, url := range endpoints {
+		if url == "" { delete(c.modelEndpoints, model); continue }
+		c.modelEndpoints[model] = url
+	}
+}
+
+// ModelEndpoints returns a copy of the client's model -> hosted chat endpoint table
+func (c *ThetaClient) ModelEndpoints() map[string]string {
+	c.endpointsMu.RLock()
+	defer c.endpointsMu.RUnlock()
+	return maps.Clone(c.modelEndpoints)
+}
+
+// hostedEndpoint returns the hosted chat endpoint serving model, if it has one
+func (c *ThetaClient) hostedEndpoint(model string) (string, bool) {
+	c.endpointsMu.RLock()
+	defer c.endpointsMu.RUnlock()
+	url, ok := c.modelEndpoints[model]
+	return url, ok
+}
+
+// DefaultSystemPrompt is the system message sent to hosted chat models when LLMRequest.SystemPrompt is empty
+const DefaultSystemPrompt = "You are an adaptive strategic assistant."
+
+// promptMessages wraps a single-prompt request in the system and user messages hosted chat models expect
+func promptMessages(req *LLMRequest) []ChatMessage {
+	system := req.SystemPrompt
+	if system == "" { system = DefaultSystemPrompt }
+	return []ChatMessage{{Role: "system", Content: system}, {Role: "user", Content: req.Prompt}}
+}
+
+// genericRequest is req as sent to the generic endpoint, which has no system message: a set
+// SystemPrompt is prepended to the prompt instead
+func genericRequest(req *LLMRequest) *LLMRequest {
+	out := *req
+	if req.SystemPrompt != "" { out.Prompt = req.SystemPrompt + "\n\n" + req.Prompt }
+	return &out
+}
+
 // GenerateWithLLM sends a request to an LLM model
 func (c *ThetaClient) GenerateWithLLM(ctx context.Context, req *LLMRequest) (*LLMResponse, error) {
-	var endpoint string
-	if req.Model == "deepseek_r1" { endpoint = "https://ondemand.thetaedgecloud.com/infer_request/deepseek_r1/completions" } else if req.Model == "llama_3_1_70b" { endpoint = "https://llama3170b2oczc2osyg-07554694ea35fad5.tec-s20.onthetaedgecloud.com/v1/chat/completions" } else { endpoint = fmt.Sprintf("%s/v1/inference/llm", c.baseURL) }
 	// DeepSeek custom handling
-	if req.Model == "deepseek_r1" || req.Model == "llama_3_1_70b" {
-		messages := []map[string]string{{"role":"system","content":"You are an adaptive strategic assistant."},{"role":"user","content":req.Prompt}}
-		if req.MaxTokens == 0 { if req.Model == "deepseek_r1" { req.MaxTokens = fallbackReasoningMaxTokens } else { req.MaxTokens = fallbackDialogueMaxTokens } }
-		payload := map[string]interface{}{"input": map[string]interface{}{"messages":messages, "max_tokens":req.MaxTokens, "temperature":req.Temperature}}
+	if endpoint, ok := c.hostedEndpoint(req.Model); ok {
+		messages := promptMessages(req)
+		if req.MaxTokens == 0 { req.MaxTokens = defaultHostedMaxTokens(req.Model) }
+		input := map[string]interface{}{"messages":messages, "max_tokens":req.MaxTokens}
+		if req.Temperature != nil { input["temperature"] = *req.Temperature }
+		payload := map[string]interface{}{"input": input}
 		if req.ResponseFormat != nil { payload["response_format"] = req.ResponseFormat }
-		rawBody, err := json.Marshal(payload); if err != nil { return nil, fmt.Errorf("marshal payload: %w", err) }
-		reqHTTP, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(rawBody)); if err != nil { return nil, fmt.Errorf("create request: %w", err) }
-		reqHTTP.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
-		reqHTTP.Header.Set("Content-Type", "application/json")
-		resp, err := c.httpClient.Do(reqHTTP); if err != nil { return nil, fmt.Errorf("request failed: %w", err) }
-		defer resp.Body.Close()
-		data, err := io.ReadAll(resp.Body); if err != nil { return nil, fmt.Errorf("read body: %w", err) }
-		if resp.StatusCode >= 400 { return nil, fmt.Errorf("%s http %d: %s", req.Model, resp.StatusCode, snippet(string(data),180)) }
-		// Parse SSE style lines if they are streamed, else treat as direct JSON
-		text := parseSSEorJSONCompletion(data)
-		if text == "" { return nil, fmt.Errorf("%s produced no content", req.Model) }
-		c.metrics.llmRequests.Add(1)
-		return &LLMResponse{Model: req.Model, Choices: []Choice{{Index:0, Text: text}}}, nil
+		return c.postHostedChat(ctx, req.Model, endpoint, payload)
 	}
+	endpoint := fmt.Sprintf("%s/v1/inference/llm", c.baseURL)
+	var resp LLMResponse
+	err := c.sendRequest(ctx, "POST", endpoint, genericRequest(req), &resp)
+	if err == nil { c.metrics.llmRequests.Add(1); c.recordUsage(req.Model, resp.Usage) }
+	return &resp, err
+}
+
+// ChatMessage is a single role-tagged message of a chat conversation
+type ChatMessage struct {
+	Role    string `json:"role"` // "system", "user", "assistant"
+	Content string `json:"content"`
+}
+
+// chatRequest is the generic endpoint payload for ChatCompletion
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []ChatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	TopP        float64       `json:"top_p,omitempty"`
+	Stop        []string      `json:"stop,omitempty"`
+}
+
+// ChatOption configures a ChatCompletion call
+type ChatOption func(*chatRequest)
+
+// WithTemperature sets the sampling temperature
+func WithTemperature(t float64) ChatOption { return func(r *chatRequest) { r.Temperature = &t } }
+
+// WithMaxTokens caps the completion length
+func WithMaxTokens(n int) ChatOption { return func(r *chatRequest) { r.MaxTokens = n } }
+
+// WithTopP sets nucleus sampling
+func WithTopP(p float64) ChatOption { return func(r *chatRequest) { r.TopP = p } }
+
+// WithStop sets stop sequences
+func WithStop(stop ...string) ChatOption { return func(r *chatRequest) { r.Stop = append(r.Stop, stop...) } }
+
+// ChatCompletion sends a multi-turn conversation to model, routing to the model's hosted
+// chat endpoint (messages under input.messages) or to the generic LLM endpoint.
+func (c *ThetaClient) ChatCompletion(ctx context.Context, model string, messages []ChatMessage, opts ...ChatOption) (*LLMResponse, error) {
+	if len(messages) == 0 { return nil, errors.New("chat completion requires at least one message") }
+	req := &chatRequest{Model: model, Messages: messages}
+	for _, opt := range opts { opt(req) }
+
+	if endpoint, ok := c.hostedEndpoint(model); ok {
+		if req.MaxTokens == 0 { req.MaxTokens = defaultHostedMaxTokens(model) }
+		input := map[string]interface{}{"messages": req.Messages, "max_tokens": req.MaxTokens}
+		if req.Temperature != nil { input["temperature"] = *req.Temperature }
+		if req.TopP > 0 { input["top_p"] = req.TopP }
+		if len(req.Stop) > 0 { input["stop"] = req.Stop }
+		return c.postHostedChat(ctx, model, endpoint, map[string]interface{}{"input": input})
+	}
+	endpoint := fmt.Sprintf("%s/v1/inference/llm", c.baseURL)
 	var resp LLMResponse
 	err := c.sendRequest(ctx, "POST", endpoint, req, &resp)
+	if err == nil { c.metrics.llmRequests.Add(1); c.recordUsage(req.Model, resp.Usage) }
 	return &resp, err
 }
 
+func defaultHostedMaxTokens(model string) int {
+	if model == "deepseek_r1" { return fallbackReasoningMaxTokens }
+	return fallbackDialogueMaxTokens
+}
+
+// postHostedChat posts a chat payload to a hosted model endpoint and parses the SSE or JSON completion
+func (c *ThetaClient) postHostedChat(ctx context.Context, model, endpoint string, payload map[string]interface{}) (*LLMResponse, error) {
+	rawBody, err := json.Marshal(payload); if err != nil { return nil, fmt.Errorf("marshal payload: %w", err) }
+	if err := c.acquire(ctx); err != nil { return nil, fmt.Errorf("rate limiter: %w", err) }
+	reqHTTP, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(rawBody)); if err != nil { return nil, fmt.Errorf("create request: %w", err) }
+	reqHTTP.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	reqHTTP.Header.Set("Content-Type", "application/json")
+	resp, err := c.clientFor(ctx).Do(reqHTTP); if err != nil { return nil, fmt.Errorf("request failed: %w", err) }
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body); if err != nil { return nil, fmt.Errorf("read body: %w", err) }
+	if resp.StatusCode >= 400 { return nil, fmt.Errorf("%s: %w", model, &APIError{Code: resp.StatusCode, Message: textutil.Snippet(string(data),180)}) }
+	// Parse SSE style lines if they are streamed, else treat as direct JSON
+	text := parseSSEorJSONCompletion(data)
+	if text == "" { return nil, fmt.Errorf("%s produced no content", model) }
+	usage := parseCompletionUsage(data)
+	c.metrics.llmRequests.Add(1)
+	c.recordUsage(model, usage)
+	return &LLMResponse{Model: model, Choices: []Choice{{Index:0, Text: text}}, Usage: usage}, nil
+}
+
+// parseCompletionUsage returns the usage block of a hosted completion, taken from the plain
+// JSON body or from the last SSE frame that carries one
+func parseCompletionUsage(data []byte) Usage {
+	var frame struct{ Usage *Usage `json:"usage"` }
+	if json.Unmarshal(data, &frame) == nil && frame.Usage != nil { return *frame.Usage }
+	var usage Usage
+	for _, line := range strings.Split(string(data), "\n") {
+		payload, ok := strings.CutPrefix(strings.TrimSpace(line), "data:")
+		if !ok { continue }
+		frame.Usage = nil
+		if json.Unmarshal([]byte(strings.TrimSpace(payload)), &frame) == nil && frame.Usage != nil { usage = *frame.Usage }
+	}
+	return usage
+}
+
 // helper to parse either SSE style or plain JSON for llama/deepseek endpoints
 func parseSSEorJSONCompletion(data []byte) string {
 	str := string(data)
@@ -337,6 +588,7 @@ func parseSSEorJSONCompletion(data []byte) string {
 
 // GenerateImage generates an image using FLUX.1-schnell or similar
 func (c *ThetaClient) GenerateImage(ctx context.Context, req *ImageGenerationRequest) (*ImageGenerationResponse, error) {
+	c.metrics.imageRequests.Add(1)
 	endpoint := fmt.Sprintf("%s/v1/inference/flux-schnell", c.baseURL)
 	var resp ImageGenerationResponse
 	err := c.sendRequest(ctx, "POST", endpoint, req, &resp)
@@ -345,20 +597,181 @@ func (c *ThetaClient) GenerateImage(ctx context.Context, req *ImageGenerationReq
 
 // GenerateVoice generates speech using Kokoro 82M
 func (c *ThetaClient) GenerateVoice(ctx context.Context, req *TTSRequest) (*TTSResponse, error) {
+	c.metrics.ttsRequests.Add(1)
 	endpoint := fmt.Sprintf("%s/v1/inference/kokoro", c.baseURL)
 	var resp TTSResponse
 	err := c.sendRequest(ctx, "POST", endpoint, req, &resp)
 	return &resp, err
 }
 
+// voiceStreamChunkSize is the read size for raw chunked audio responses
+const voiceStreamChunkSize = 16 * 1024
+
+// GenerateVoiceStream streams synthesized audio from Kokoro so playback can start before
+// synthesis finishes. Raw chunked responses are forwarded as they are read; SSE responses
+// carry base64 frames (bare or as {"audio"|"audio_data"|"chunk": "..."}). The audio channel
+// closes when the stream ends and the error channel then yields at most one error.
+func (c *ThetaClient) GenerateVoiceStream(ctx context.Context, req *TTSRequest) (<-chan []byte, <-chan error) {
+	out := make(chan []byte, 8); errCh := make(chan error, 1)
+	go func() {
+		defer close(out); defer close(errCh)
+		c.metrics.ttsRequests.Add(1)
+		if err := c.acquire(ctx); err != nil { errCh <- fmt.Errorf("rate limiter: %w", err); return }
+		streamReq := *req
+		streamReq.Stream = true
+		body, err := json.Marshal(&streamReq)
+		if err != nil { errCh <- fmt.Errorf("failed to marshal request: %w", err); return }
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/v1/inference/kokoro?stream=true", c.baseURL), bytes.NewReader(body))
+		if err != nil { errCh <- fmt.Errorf("failed to create request: %w", err); return }
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, err := c.clientFor(ctx).Do(httpReq)
+		if err != nil { errCh <- fmt.Errorf("request failed: %w", err); return }
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			errCh <- fmt.Errorf("voice stream http %d: %s", resp.StatusCode, textutil.Snippet(string(b), 180))
+			return
+		}
+		send := func(chunk []byte) bool {
+			select {
+			case out <- chunk:
+				return true
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return false
+			}
+		}
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			reader := bufio.NewReader(resp.Body)
+			for {
+				line, readErr := reader.ReadString('\n')
+				if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:"); ok {
+					data = strings.TrimSpace(data)
+					if data == "[DONE]" { return }
+					if data != "" {
+						chunk, err := decodeAudioFrame(data)
+						if err != nil { errCh <- err; return }
+						if len(chunk) > 0 && !send(chunk) { return }
+					}
+				}
+				if readErr != nil {
+					if !errors.Is(readErr, io.EOF) { errCh <- readErr }
+					return
+				}
+			}
+		}
+		buf := make([]byte, voiceStreamChunkSize)
+		for {
+			n, readErr := resp.Body.Read(buf)
+			if n > 0 && !send(append([]byte(nil), buf[:n]...)) { return }
+			if readErr != nil {
+				if !errors.Is(readErr, io.EOF) { errCh <- readErr }
+				return
+			}
+		}
+	}()
+	return out, errCh
+}
+
+// decodeAudioFrame decodes one SSE audio frame: base64 text or a JSON object holding it
+func decodeAudioFrame(data string) ([]byte, error) {
+	if strings.HasPrefix(data, "{") {
+		var frame struct {
+			Audio     string    `json:"audio"`
+			AudioData string    `json:"audio_data"`
+			Chunk     string    `json:"chunk"`
+			Error     *APIError `json:"error,omitempty"`
+		}
+		if err := json.Unmarshal([]byte(data), &frame); err != nil { return nil, fmt.Errorf("invalid audio frame: %w", err) }
+		if frame.Error != nil { return nil, frame.Error }
+		data = frame.Audio + frame.AudioData + frame.Chunk
+	}
+	chunk, err := base64.StdEncoding.DecodeString(data)
+	if err != nil { return nil, fmt.Errorf("invalid audio frame: %w", err) }
+	return chunk, nil
+}
+
 // GenerateVideo generates video using Stable Diffusion Video
 func (c *ThetaClient) GenerateVideo(ctx context.Context, req *VideoGenerationRequest) (*VideoGenerationResponse, error) {
+	c.metrics.videoRequests.Add(1)
 	endpoint := fmt.Sprintf("%s/v1/inference/stable-video-diffusion", c.baseURL)
 	var resp VideoGenerationResponse
 	err := c.sendRequest(ctx, "POST", endpoint, req, &resp)
 	return &resp, err
 }
 
+// parseRetryAfter reads a Retry-After header in delta-seconds or HTTP-date form
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" { return 0, false }
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 { return 0, false }
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 { return d, true }
+		return 0, true
+	}
+	return 0, false
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 { return nil }
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Generate3DModel generates a 3D model. Reference images are sent as a multipart upload
+// alongside the other request fields; without them the request is plain JSON.
+func (c *ThetaClient) Generate3DModel(ctx context.Context, req *Model3DRequest) (*Model3DResponse, error) {
+	c.metrics.model3DRequests.Add(1)
+	endpoint := fmt.Sprintf("%s/v1/inference/%s", c.baseURL, Model3DGeneration)
+	var resp Model3DResponse
+	var err error
+	if len(req.ReferenceImages) == 0 {
+		err = c.sendRequest(ctx, "POST", endpoint, req, &resp)
+	} else {
+		body, contentType, encErr := encodeModel3DMultipart(req)
+		if encErr != nil { return nil, encErr }
+		err = c.sendRaw(ctx, "POST", endpoint, body, contentType, &resp)
+	}
+	if err != nil { return &resp, err }
+	if resp.Error != nil { return &resp, resp.Error }
+	if st := strings.ToLower(resp.Status); st == "failed" || st == "error" {
+		return &resp, fmt.Errorf("3d generation %s: status %s", resp.ID, resp.Status)
+	}
+	return &resp, nil
+}
+
+func encodeModel3DMultipart(req *Model3DRequest) ([]byte, string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	_ = writer.WriteField("prompt", req.Prompt)
+	if req.ModelType != "" { _ = writer.WriteField("model_type", req.ModelType) }
+	if req.Resolution != "" { _ = writer.WriteField("resolution", req.Resolution) }
+	if req.Format != "" { _ = writer.WriteField("format", req.Format) }
+	_ = writer.WriteField("include_textures", strconv.FormatBool(req.IncludeTextures))
+	if len(req.Metadata) > 0 {
+		meta, err := json.Marshal(req.Metadata)
+		if err != nil { return nil, "", fmt.Errorf("failed to marshal metadata: %w", err) }
+		_ = writer.WriteField("metadata", string(meta))
+	}
+	for i, img := range req.ReferenceImages {
+		fileWriter, err := writer.CreateFormFile("reference_images", fmt.Sprintf("reference_%d.png", i))
+		if err != nil { return nil, "", fmt.Errorf("failed form file: %w", err) }
+		if _, err := fileWriter.Write(img); err != nil { return nil, "", fmt.Errorf("failed write reference image: %w", err) }
+	}
+	if err := writer.Close(); err != nil { return nil, "", fmt.Errorf("close multipart: %w", err) }
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
 // sendRequest is a helper method for sending HTTP requests
 func (c *ThetaClient) sendRequest(ctx context.Context, method, endpoint string, reqBody, respBody interface{}) error {
 	var rawBody []byte
@@ -367,18 +780,23 @@ func (c *ThetaClient) sendRequest(ctx context.Context, method, endpoint string,
 		rawBody, err = json.Marshal(reqBody)
 		if err != nil { return fmt.Errorf("failed to marshal request: %w", err) }
 	}
+	return c.sendRaw(ctx, method, endpoint, rawBody, "application/json", respBody)
+}
+
+// sendRaw sends a pre-encoded body with retry and rate limiting and decodes the JSON response
+func (c *ThetaClient) sendRaw(ctx context.Context, method, endpoint string, rawBody []byte, contentType string, respBody interface{}) error {
 	attempts := c.retryAttempts
 	var lastErr error
 	for attempt := 0; attempt < attempts; attempt++ {
-		c.acquire()
+		if err := c.acquire(ctx); err != nil { return fmt.Errorf("rate limiter: %w", err) }
 		var body io.Reader
 		if rawBody != nil { body = bytes.NewReader(rawBody) }
 		req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
 		if err != nil { return fmt.Errorf("failed to create request: %w", err) }
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", contentType)
 		req.Header.Set("User-Agent", "Emergent-World-Engine/1.0")
-		resp, err := c.httpClient.Do(req)
+		resp, err := c.clientFor(ctx).Do(req)
 		if err != nil {
 			lastErr = err
 			if attempt < attempts-1 { time.Sleep(time.Duration(attempt+1)*c.retryBackoff); continue }
@@ -395,69 +813,209 @@ func (c *ThetaClient) sendRequest(ctx context.Context, method, endpoint string,
 				_ = json.Unmarshal(data, &apiErr)
 				if apiErr.Message == "" { apiErr.Message = strings.TrimSpace(string(data)) }
 				apiErr.Code = resp.StatusCode
-				log.Printf("[THETA][HTTP %d] endpoint=%s body_snip=%q", resp.StatusCode, endpoint, snippet(string(data), 240))
+				log.Printf("[THETA][HTTP %d] endpoint=%s body_snip=%q", resp.StatusCode, endpoint, textutil.Snippet(string(data), 240))
 				lastErr = &apiErr
-				// Retry on 5xx or 429
+				// Retry on 5xx or 429, waiting as long as the server asks (Retry-After) when it says so
 				if resp.StatusCode >=500 || resp.StatusCode==429 {
-					if attempt < attempts-1 { time.Sleep(time.Duration(attempt+1)*c.retryBackoff); err = &apiErr; return }
+					if attempt < attempts-1 {
+						wait := time.Duration(attempt+1)*c.retryBackoff
+						if ra, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok { wait = min(ra, c.maxRetryWait) }
+						err = &apiErr
+						if sleepErr := sleepCtx(ctx, wait); sleepErr != nil { err = sleepErr }
+						return
+					}
 				}
 				err = &apiErr
 				return
 			}
 			if respBody != nil {
 				if decErr := json.Unmarshal(data, respBody); decErr != nil {
-					log.Printf("[THETA][DECODE ERR] endpoint=%s err=%v raw_snip=%q", endpoint, decErr, snippet(string(data), 240))
-					err = fmt.Errorf("failed to decode response: %w", decErr)
+					log.Printf("[THETA][DECODE ERR] endpoint=%s err=%v raw_snip=%q", endpoint, decErr, textutil.Snippet(string(data), 240))
+					err = fmt.Errorf("%w: %w", ErrDecode, decErr)
 					lastErr = err
 					return
 				}
-				c.metrics.llmRequests.Add(1)
 			}
 		}()
 		if err == nil { return nil }
+		if ctx.Err() != nil { return fmt.Errorf("request cancelled: %w", ctx.Err()) }
 		lastErr = err
 	}
 	if lastErr == nil { return fmt.Errorf("exhausted retries: unknown error") }
-	return fmt.Errorf("exhausted retries: last error: %v", lastErr)
+	return fmt.Errorf("exhausted retries: last error: %w", lastErr)
 }
 
-// GenerateWithLLMStream streams an LLM completion (best-effort generic SSE/line JSON parser)
+// GenerateWithLLMStream streams an LLM completion. SSE and newline-delimited JSON frames from
+// every endpoint are normalized by streamAssembler into text increments on the output channel.
 func (c *ThetaClient) GenerateWithLLMStream(ctx context.Context, req *LLMRequest) (<-chan string, <-chan error) {
 	out := make(chan string, 32); errCh := make(chan error, 1)
 	go func(){
-		defer close(out); defer close(errCh); c.acquire()
+		defer close(out); defer close(errCh); if e := c.acquire(ctx); e != nil { errCh <- e; return }
 		endpoint := ""; var body io.Reader
-		if req.Model == "deepseek_r1" {
-			endpoint = "https://ondemand.thetaedgecloud.com/infer_request/deepseek_r1/completions?stream=true"
-			messages := []map[string]string{{"role":"system","content":"You are an adaptive strategic assistant."},{"role":"user","content":req.Prompt}}
+		if hosted, ok := c.hostedEndpoint(req.Model); ok {
+			endpoint = hosted + "?stream=true"
+			messages := promptMessages(req)
 			if req.MaxTokens == 0 { req.MaxTokens = fallbackDialogueMaxTokens }
-			payload := map[string]interface{}{"input": map[string]interface{}{"messages":messages,"max_tokens":req.MaxTokens,"temperature":req.Temperature,"stream":true}}
-			if req.TopP > 0 { payload["input"].(map[string]interface{})["top_p"] = req.TopP }
-			jsonBody, e := json.Marshal(payload); if e != nil { errCh <- e; return }; body = bytes.NewReader(jsonBody)
-		} else if req.Model == "llama_3_1_70b" {
-			endpoint = "https://llama3170b2oczc2osyg-07554694ea35fad5.tec-s20.onthetaedgecloud.com/v1/chat/completions?stream=true"
-			messages := []map[string]string{{"role":"system","content":"You are an adaptive strategic assistant."},{"role":"user","content":req.Prompt}}
-			if req.MaxTokens == 0 { req.MaxTokens = fallbackDialogueMaxTokens }
-			payload := map[string]interface{}{"input": map[string]interface{}{"messages":messages,"max_tokens":req.MaxTokens,"temperature":req.Temperature,"stream":true}}
-			if req.TopP > 0 { payload["input"].(map[string]interface{})["top_p"] = req.TopP }
+			input := map[string]interface{}{"messages":messages,"max_tokens":req.MaxTokens,"stream":true}
+			if req.Temperature != nil { input["temperature"] = *req.Temperature }
+			if req.TopP > 0 { input["top_p"] = req.TopP }
+			payload := map[string]interface{}{"input": input}
 			jsonBody, e := json.Marshal(payload); if e != nil { errCh <- e; return }; body = bytes.NewReader(jsonBody)
 		} else {
-			endpoint = fmt.Sprintf("%s/v1/inference/llm?stream=true", c.baseURL); req.Stream = true; jsonBody, e := json.Marshal(req); if e != nil { errCh <- e; return }; body = bytes.NewReader(jsonBody)
+			endpoint = fmt.Sprintf("%s/v1/inference/llm?stream=true", c.baseURL); streamReq := genericRequest(req); streamReq.Stream = true; jsonBody, e := json.Marshal(streamReq); if e != nil { errCh <- e; return }; body = bytes.NewReader(jsonBody)
 		}
 		httpReq, e := http.NewRequestWithContext(ctx, "POST", endpoint, body); if e != nil { errCh <- e; return }
 		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey)); httpReq.Header.Set("Content-Type","application/json")
-		resp, e := c.httpClient.Do(httpReq); if e != nil { errCh <- e; return }
-		if resp.StatusCode >=400 { b,_ := io.ReadAll(resp.Body); errCh <- fmt.Errorf("stream http %d: %s", resp.StatusCode, snippet(string(b),180)); resp.Body.Close(); return }
-		c.metrics.llmStreamReqs.Add(1); reader := bufio.NewReader(resp.Body)
-		for { line, e := reader.ReadString('\n'); if len(line)>0 { line = strings.TrimSpace(line); if strings.HasPrefix(line,"data:") { line = strings.TrimSpace(strings.TrimPrefix(line,"data:")) }; if line=="[DONE]" { break }; if line=="" { continue }; var obj map[string]interface{}; if json.Unmarshal([]byte(line), &obj)==nil { if delta,ok:=obj["delta"].(string); ok { out<-delta; c.metrics.llmStreamTokens.Add(1); continue }; if text,ok:=obj["text"].(string); ok { out<-text; c.metrics.llmStreamTokens.Add(1); continue }; if choices,ok:=obj["choices"].([]interface{}); ok { for _,ch := range choices { if m,ok2:=ch.(map[string]interface{}); ok2 { // handle direct text
-				if t,ok3:=m["text"].(string); ok3 { out<-t; c.metrics.llmStreamTokens.Add(1) }
-				// handle nested delta.content (DeepSeek/Llama style)
-				if deltaMap,ok4:=m["delta"].(map[string]interface{}); ok4 { if content,ok5:=deltaMap["content"].(string); ok5 { out<-content; c.metrics.llmStreamTokens.Add(1) } }
-			} }; continue } }; out<-line }; if e!=nil { if errors.Is(e, io.EOF) { break }; errCh<-e; break } }
-		resp.Body.Close()
+		resp, e := c.clientFor(ctx).Do(httpReq); if e != nil { errCh <- e; return }
+		if resp.StatusCode >=400 { b,_ := io.ReadAll(resp.Body); errCh <- fmt.Errorf("stream http %d: %w", resp.StatusCode, &APIError{Code: resp.StatusCode, Message: textutil.Snippet(string(b),180)}); resp.Body.Close(); return }
+		defer resp.Body.Close()
+		c.metrics.llmStreamReqs.Add(1)
+		var asm streamAssembler
+		reader := bufio.NewReader(resp.Body)
+		for {
+			line, readErr := reader.ReadString('\n')
+			if payload, ok := streamPayload(line); ok {
+				if payload == "[DONE]" { return }
+				text, err := asm.frame(payload)
+				if err != nil { errCh <- err; return }
+				if text != "" {
+					select {
+					case out <- text:
+						c.metrics.llmStreamTokens.Add(1)
+					case <-ctx.Done():
+						errCh <- ctx.Err()
+						return
+					}
+				}
+			}
+			if readErr != nil {
+				if !errors.Is(readErr, io.EOF) { errCh <- readErr }
+				return
+			}
+		}
 	}(); return out, errCh
 }
 
+// streamPayload returns the data carried by one line of an SSE or newline-delimited JSON
+// stream; blank lines, comments and the event/id/retry SSE fields carry none.
+func streamPayload(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if data, ok := strings.CutPrefix(line, "data:"); ok {
+		data = strings.TrimSpace(data)
+		return data, data != ""
+	}
+	if line == "" || strings.HasPrefix(line, ":") {
+		return "", false
+	}
+	for _, field := range []string{"event:", "id:", "retry:"} {
+		if strings.HasPrefix(line, field) { return "", false }
+	}
+	return line, true
+}
+
+// streamAssembler normalizes streamed LLM frames into text increments. Incremental frames
+// (choices[].text, choices[].delta.content, or a top-level delta/text) are passed through as
+// they arrive, including a chunk that only adds a finish_reason. Full-object frames - a chat
+// message, or a response marked done/completed - carry the whole completion so far, so only
+// the part not yet emitted is returned.
+type streamAssembler struct {
+	text strings.Builder
+}
+
+type streamFrame struct {
+	Delta   *string `json:"delta"`
+	Text    *string `json:"text"`
+	Status  string  `json:"status"`
+	Done    bool    `json:"done"`
+	Choices []struct {
+		Text         string `json:"text"`
+		FinishReason string `json:"finish_reason"`
+		Delta        struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		Message *struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Error *APIError `json:"error"`
+}
+
+// frame returns the new text carried by one stream payload. Payloads that are not JSON
+// objects are raw text tokens; objects of an unrecognized shape carry no text.
+func (a *streamAssembler) frame(payload string) (string, error) {
+	if !strings.HasPrefix(payload, "{") {
+		return a.emit(payload), nil
+	}
+	var f streamFrame
+	if json.Unmarshal([]byte(payload), &f) != nil {
+		return "", nil
+	}
+	if f.Error != nil && f.Error.Message != "" {
+		return "", f.Error
+	}
+	complete := f.Done || strings.EqualFold(f.Status, "completed") || strings.EqualFold(f.Status, "success")
+	switch {
+	case f.Delta != nil:
+		return a.emit(*f.Delta), nil
+	case f.Text != nil:
+		if complete { return a.extend(*f.Text), nil }
+		return a.emit(*f.Text), nil
+	}
+	var b strings.Builder
+	for _, ch := range f.Choices {
+		switch {
+		case ch.Message != nil:
+			b.WriteString(a.extend(ch.Message.Content))
+		case complete:
+			b.WriteString(a.extend(ch.Text))
+			b.WriteString(a.emit(ch.Delta.Content))
+		default:
+			b.WriteString(a.emit(ch.Text))
+			b.WriteString(a.emit(ch.Delta.Content))
+		}
+	}
+	return b.String(), nil
+}
+
+// emit records an incremental token
+func (a *streamAssembler) emit(token string) string {
+	a.text.WriteString(token)
+	return token
+}
+
+// extend records a full-object text, returning only what goes beyond the text already
+// emitted. A full text that does not start with the streamed text is one more increment
+// (servers that mark only their last token as done).
+func (a *streamAssembler) extend(full string) string {
+	if rest, ok := strings.CutPrefix(full, a.text.String()); ok {
+		return a.emit(rest)
+	}
+	return a.emit(full)
+}
+
+// Ping checks that the Theta endpoint is reachable and accepts the API key with a single
+// GET to /v1/health (no retries). 401/403 and 5xx responses are returned as *APIError;
+// any other status means the service answered and the key was not rejected.
+func (c *ThetaClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/v1/health", c.baseURL), nil)
+	if err != nil { return fmt.Errorf("failed to create request: %w", err) }
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	req.Header.Set("User-Agent", "Emergent-World-Engine/1.0")
+	resp, err := c.clientFor(ctx).Do(req)
+	if err != nil { return fmt.Errorf("theta unreachable: %w", err) }
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode >= 500 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		apiErr := &APIError{}
+		_ = json.Unmarshal(data, apiErr)
+		if apiErr.Message == "" { apiErr.Message = strings.TrimSpace(string(data)) }
+		if apiErr.Message == "" { apiErr.Message = http.StatusText(resp.StatusCode) }
+		apiErr.Code = resp.StatusCode
+		return apiErr
+	}
+	return nil
+}
+
 // GetJobStatus checks the status of an async job
 func (c *ThetaClient) GetJobStatus(ctx context.Context, jobID string) (map[string]interface{}, error) {
 	endpoint := fmt.Sprintf("%s/v1/jobs/%s", c.baseURL, jobID)
@@ -466,11 +1024,50 @@ func (c *ThetaClient) GetJobStatus(ctx context.Context, jobID string) (map[strin
 	return result, err
 }
 
-// SetTimeout sets the HTTP client timeout
+// maxJobPollInterval caps the backoff between WaitForJob polls
+const maxJobPollInterval = 30 * time.Second
+
+// WaitForJob polls GetJobStatus until the job reports completed or failed, doubling the poll
+// interval after each pending status (capped at maxJobPollInterval). A failed job returns its
+// last status together with an error; ctx bounds the total wait.
+func (c *ThetaClient) WaitForJob(ctx context.Context, jobID string, pollInterval time.Duration) (map[string]interface{}, error) {
+	if pollInterval <= 0 { pollInterval = time.Second }
+	for {
+		status, err := c.GetJobStatus(ctx, jobID)
+		if err != nil {
+			if ctx.Err() != nil { return nil, fmt.Errorf("waiting for job %s: %w", jobID, ctx.Err()) }
+			return nil, fmt.Errorf("failed to poll job %s: %w", jobID, err)
+		}
+		state, _ := status["status"].(string)
+		switch strings.ToLower(state) {
+		case "completed", "succeeded", "success":
+			return status, nil
+		case "failed", "error", "cancelled", "canceled":
+			if reason, ok := status["error"]; ok && reason != nil { return status, fmt.Errorf("job %s %s: %v", jobID, state, reason) }
+			return status, fmt.Errorf("job %s %s", jobID, state)
+		}
+		if err := sleepCtx(ctx, pollInterval); err != nil { return status, fmt.Errorf("waiting for job %s: %w", jobID, err) }
+		pollInterval = min(pollInterval*2, maxJobPollInterval)
+	}
+}
+
+// SetTimeout sets the HTTP client timeout. It caps requests whose context has no deadline;
+// a context deadline replaces it, so long image and video calls can outlast it.
 func (c *ThetaClient) SetTimeout(timeout time.Duration) {
 	c.httpClient.Timeout = timeout
 }
 
+// clientFor returns the HTTP client for a request: when ctx has a deadline it bounds the request
+// instead of the client timeout
+func (c *ThetaClient) clientFor(ctx context.Context) *http.Client {
+	if _, ok := ctx.Deadline(); !ok || c.httpClient.Timeout == 0 {
+		return c.httpClient
+	}
+	untimed := *c.httpClient
+	untimed.Timeout = 0
+	return &untimed
+}
+
 // SetBaseURL updates the base URL for the client
 func (c *ThetaClient) SetBaseURL(baseURL string) {
 	c.baseURL = baseURL
@@ -482,14 +1079,23 @@ type ClientMetrics struct {
 	LLMFailures int64
 	LLMStreamRequests int64
 	LLMStreamTokens int64
+	ImageRequests   int64 // calls issued per modality, successful or not
+	VideoRequests   int64
+	TTSRequests     int64
+	VisionRequests  int64
+	Model3DRequests int64
+	TokenUsage      TokenUsage // LLM tokens reported by completed requests
 }
 
 func (c *ThetaClient) Metrics() ClientMetrics {
-	return ClientMetrics{ LLMRequests: c.metrics.llmRequests.Load(), LLMFailures: c.metrics.llmFailures.Load(), LLMStreamRequests: c.metrics.llmStreamReqs.Load(), LLMStreamTokens: c.metrics.llmStreamTokens.Load() }
+	m := c.metrics
+	return ClientMetrics{ LLMRequests: m.llmRequests.Load(), LLMFailures: m.llmFailures.Load(), LLMStreamRequests: m.llmStreamReqs.Load(), LLMStreamTokens: m.llmStreamTokens.Load(),
+		ImageRequests: m.imageRequests.Load(), VideoRequests: m.videoRequests.Load(), TTSRequests: m.ttsRequests.Load(), VisionRequests: m.visionRequests.Load(), Model3DRequests: m.model3DRequests.Load(), TokenUsage: c.tokenUsage() }
 }
 
 // AnalyzeVision performs vision analysis using Grounding Dino (improved multipart with file field)
 func (c *ThetaClient) AnalyzeVision(ctx context.Context, req *VisionRequest) (*VisionResponse, error) {
+	c.metrics.visionRequests.Add(1)
 	endpoint := fmt.Sprintf("%s/v1/inference/grounding-dino", c.baseURL)
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
@@ -498,12 +1104,15 @@ func (c *ThetaClient) AnalyzeVision(ctx context.Context, req *VisionRequest) (*V
 	if err != nil { return nil, fmt.Errorf("failed form file: %w", err) }
 	if _, err := fileWriter.Write(req.Image); err != nil { return nil, fmt.Errorf("failed write image: %w", err) }
 	if req.Query != "" { _ = writer.WriteField("query", req.Query) }
+	if len(req.Classes) > 0 { _ = writer.WriteField("classes", strings.Join(req.Classes, ",")) }
+	if req.Threshold > 0 { _ = writer.WriteField("threshold", strconv.FormatFloat(req.Threshold, 'f', -1, 64)) }
+	if req.MaxResults > 0 { _ = writer.WriteField("max_results", strconv.Itoa(req.MaxResults)) }
 	if err := writer.Close(); err != nil { return nil, fmt.Errorf("close multipart: %w", err) }
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, body)
 	if err != nil { return nil, fmt.Errorf("failed to create request: %w", err) }
 	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
 	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.clientFor(ctx).Do(httpReq)
 	if err != nil { return nil, fmt.Errorf("request failed: %w", err) }
 	defer resp.Body.Close()
 	var result VisionResponse
@@ -511,5 +1120,3 @@ func (c *ThetaClient) AnalyzeVision(ctx context.Context, req *VisionRequest) (*V
 	if result.Error != nil { return &result, result.Error }
 	return &result, nil
 }
-
-func snippet(s string, n int) string { if len(s) <= n { return s }; return s[:n] + "..." }
\ No newline at end of file
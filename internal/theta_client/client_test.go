@@ -0,0 +1,820 @@
+package theta_client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestClient(url string) *ThetaClient {
+	c := NewThetaClient(url, "test_key")
+	c.SetRetry(2, time.Millisecond)
+	return c
+}
+
+func TestGenerate3DModelJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/inference/3d-generation" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected JSON content type, got %s", ct)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if body["prompt"] != "wooden barrel" || body["format"] != "gltf" || body["include_textures"] != true {
+			t.Errorf("Unexpected request body %v", body)
+		}
+		w.Write([]byte(`{"id":"m1","status":"completed","model_url":"https://cdn/m1.gltf","model_data":"Z2x0Zg==","texture_urls":["https://cdn/diffuse.png"]}`))
+	}))
+	defer server.Close()
+
+	resp, err := newTestClient(server.URL).Generate3DModel(context.Background(), &Model3DRequest{
+		Prompt: "wooden barrel", Format: FormatGLTF, IncludeTextures: true,
+	})
+	if err != nil {
+		t.Fatalf("Generate3DModel failed: %v", err)
+	}
+	if string(resp.ModelData) != "gltf" {
+		t.Errorf("Expected decoded model data, got %q", resp.ModelData)
+	}
+	if len(resp.TextureURLs) != 1 || resp.TextureURLs[0] != "https://cdn/diffuse.png" {
+		t.Errorf("Unexpected texture URLs %v", resp.TextureURLs)
+	}
+}
+
+func TestGenerate3DModelMultipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			t.Errorf("Expected multipart upload, got %s", r.Header.Get("Content-Type"))
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Failed to parse multipart: %v", err)
+		}
+		if r.FormValue("prompt") != "stone golem" || r.FormValue("include_textures") != "false" {
+			t.Errorf("Unexpected form fields %v", r.MultipartForm.Value)
+		}
+		files := r.MultipartForm.File["reference_images"]
+		if len(files) != 2 {
+			t.Fatalf("Expected 2 reference images, got %d", len(files))
+		}
+		f, _ := files[1].Open()
+		data, _ := io.ReadAll(f)
+		f.Close()
+		if string(data) != "img-2" {
+			t.Errorf("Unexpected reference image content %q", data)
+		}
+		w.Write([]byte(`{"id":"m2","status":"completed","model_url":"https://cdn/m2.obj"}`))
+	}))
+	defer server.Close()
+
+	resp, err := newTestClient(server.URL).Generate3DModel(context.Background(), &Model3DRequest{
+		Prompt:          "stone golem",
+		ReferenceImages: [][]byte{[]byte("img-1"), []byte("img-2")},
+	})
+	if err != nil {
+		t.Fatalf("Generate3DModel failed: %v", err)
+	}
+	if resp.ModelURL != "https://cdn/m2.obj" {
+		t.Errorf("Unexpected model URL %s", resp.ModelURL)
+	}
+}
+
+func TestGenerate3DModelStatus(t *testing.T) {
+	cases := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"http error", http.StatusBadRequest, `{"message":"prompt too long"}`, "prompt too long"},
+		{"embedded error", http.StatusOK, `{"id":"m3","status":"failed","error":{"code":500,"message":"mesh reconstruction failed"}}`, "mesh reconstruction failed"},
+		{"failed status", http.StatusOK, `{"id":"m4","status":"failed"}`, "status failed"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			_, err := newTestClient(server.URL).Generate3DModel(context.Background(), &Model3DRequest{Prompt: "x"})
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSendRequestHonorsRetryAfter(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"id":"img","status":"completed"}`))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	if _, err := newTestClient(server.URL).GenerateImage(context.Background(), &ImageGenerationRequest{Prompt: "x"}); err != nil {
+		t.Fatalf("GenerateImage failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 1900*time.Millisecond || elapsed > 4*time.Second {
+		t.Errorf("Expected ~2s wait from Retry-After, took %s", elapsed)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
+
+func TestSendRequestCapsRetryAfter(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"id":"img","status":"completed"}`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	c.SetMaxRetryWait(50 * time.Millisecond)
+	start := time.Now()
+	if _, err := c.GenerateImage(context.Background(), &ImageGenerationRequest{Prompt: "x"}); err != nil {
+		t.Fatalf("GenerateImage failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Retry-After capped at 50ms, took %s", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if d, ok := parseRetryAfter("3", now); !ok || d != 3*time.Second {
+		t.Errorf("Expected 3s, got %s %v", d, ok)
+	}
+	if d, ok := parseRetryAfter(now.Add(5*time.Second).Format(http.TimeFormat), now); !ok || d != 5*time.Second {
+		t.Errorf("Expected 5s from HTTP-date, got %s %v", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Error("Expected invalid header to be ignored")
+	}
+	if _, ok := parseRetryAfter("", now); ok {
+		t.Error("Expected missing header to be ignored")
+	}
+}
+
+func TestAcquireRespectsContext(t *testing.T) {
+	// An unbuffered bucket with no refill never yields a token, so only the context can end the wait
+	c := &ThetaClient{tokens: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected an already-cancelled context to fail acquire, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.acquire(ctx) }()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancelling a waiting acquire to fail it, got %v", err)
+	}
+
+	c.tokens = make(chan struct{}, 1)
+	c.tokens <- struct{}{}
+	if err := c.acquire(context.Background()); err != nil {
+		t.Errorf("Expected acquire to take an available token, got %v", err)
+	}
+}
+
+func TestChatCompletionHostedMessages(t *testing.T) {
+	for _, model := range []string{"deepseek_r1", "llama_3_1_70b"} {
+		t.Run(model, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Input struct {
+						Messages    []ChatMessage `json:"messages"`
+						MaxTokens   int           `json:"max_tokens"`
+						Temperature float64       `json:"temperature"`
+						TopP        float64       `json:"top_p"`
+						Stop        []string      `json:"stop"`
+					} `json:"input"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Fatalf("Failed to decode request: %v", err)
+				}
+				in := body.Input
+				if len(in.Messages) != 3 || in.Messages[0].Role != "system" || in.Messages[2].Content != "And now?" {
+					t.Errorf("Unexpected messages %+v", in.Messages)
+				}
+				if in.MaxTokens != 64 || in.Temperature != 0.3 || in.TopP != 0.9 || len(in.Stop) != 1 {
+					t.Errorf("Unexpected options %+v", in)
+				}
+				w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Hold \"}}]}\n\ndata: {\"choices\":[{\"delta\":{\"content\":\"the line.\"}}]}\n\ndata: [DONE]\n"))
+			}))
+			defer server.Close()
+
+			c := newTestClient("http://unused")
+			c.SetModelEndpoints(map[string]string{model: server.URL})
+			resp, err := c.ChatCompletion(context.Background(), model, []ChatMessage{
+				{Role: "system", Content: "You are a general."},
+				{Role: "user", Content: "Report."},
+				{Role: "user", Content: "And now?"},
+			}, WithMaxTokens(64), WithTemperature(0.3), WithTopP(0.9), WithStop("END"))
+			if err != nil {
+				t.Fatalf("ChatCompletion failed: %v", err)
+			}
+			if resp.Choices[0].Text != "Hold the line." {
+				t.Errorf("Unexpected completion %q", resp.Choices[0].Text)
+			}
+		})
+	}
+}
+
+func TestLLMSystemPrompt(t *testing.T) {
+	for _, model := range []string{"deepseek_r1", "llama_3_1_70b"} {
+		t.Run(model, func(t *testing.T) {
+			var systems []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Input struct {
+						Messages []ChatMessage `json:"messages"`
+					} `json:"input"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Fatalf("Failed to decode request: %v", err)
+				}
+				msgs := body.Input.Messages
+				if len(msgs) != 2 || msgs[0].Role != "system" || msgs[1].Role != "user" || msgs[1].Content != "Report." {
+					t.Errorf("Unexpected messages %+v", msgs)
+				} else {
+					systems = append(systems, msgs[0].Content)
+				}
+				w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"ok\"}}]}\n\ndata: [DONE]\n"))
+			}))
+			defer server.Close()
+
+			c := newTestClient("http://unused")
+			c.SetModelEndpoints(map[string]string{model: server.URL})
+			if _, err := c.GenerateWithLLM(context.Background(), &LLMRequest{Model: model, Prompt: "Report.", SystemPrompt: "You are a terse quartermaster."}); err != nil {
+				t.Fatalf("GenerateWithLLM failed: %v", err)
+			}
+			if _, err := c.GenerateWithLLM(context.Background(), &LLMRequest{Model: model, Prompt: "Report."}); err != nil {
+				t.Fatalf("GenerateWithLLM failed: %v", err)
+			}
+			ch, errCh := c.GenerateWithLLMStream(context.Background(), &LLMRequest{Model: model, Prompt: "Report.", SystemPrompt: "Speak in riddles."})
+			for range ch {
+			}
+			if err := <-errCh; err != nil {
+				t.Fatalf("GenerateWithLLMStream failed: %v", err)
+			}
+
+			want := []string{"You are a terse quartermaster.", DefaultSystemPrompt, "Speak in riddles."}
+			if strings.Join(systems, "|") != strings.Join(want, "|") {
+				t.Errorf("Expected system prompts %q, got %q", want, systems)
+			}
+		})
+	}
+}
+
+func TestGenericSystemPrompt(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if r.URL.Query().Get("stream") == "true" {
+			w.Write([]byte("data: {\"choices\":[{\"text\":\"ok\"}]}\n\ndata: [DONE]\n"))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	req := &LLMRequest{Model: "gpt-oss-20b", Prompt: "Report.", SystemPrompt: "You are a terse quartermaster."}
+	if _, err := c.GenerateWithLLM(context.Background(), req); err != nil {
+		t.Fatalf("GenerateWithLLM failed: %v", err)
+	}
+	ch, errCh := c.GenerateWithLLMStream(context.Background(), req)
+	for range ch {
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("GenerateWithLLMStream failed: %v", err)
+	}
+	if _, err := c.GenerateWithLLM(context.Background(), &LLMRequest{Model: "gpt-oss-20b", Prompt: "Report."}); err != nil {
+		t.Fatalf("GenerateWithLLM failed: %v", err)
+	}
+
+	want := []string{"You are a terse quartermaster.\n\nReport.", "You are a terse quartermaster.\n\nReport.", "Report."}
+	if len(bodies) != len(want) {
+		t.Fatalf("Expected %d requests, got %d", len(want), len(bodies))
+	}
+	for i, body := range bodies {
+		if body["prompt"] != want[i] {
+			t.Errorf("Request %d: expected prompt %q, got %q", i, want[i], body["prompt"])
+		}
+		if _, ok := body["system_prompt"]; ok {
+			t.Errorf("Request %d: expected no system_prompt field, got %v", i, body["system_prompt"])
+		}
+	}
+	if req.Prompt != "Report." {
+		t.Errorf("Expected the caller's prompt to be left alone, got %q", req.Prompt)
+	}
+}
+
+func TestModelEndpointOverride(t *testing.T) {
+	var paths []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == "/v1/inference/llm" {
+			w.Write([]byte(`{"choices":[{"text":"generic"}]}`))
+			return
+		}
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"hosted\"}}]}\n\ndata: [DONE]\n"))
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	if got := c.ModelEndpoints()["deepseek_r1"]; got != DefaultModelEndpoints()["deepseek_r1"] || got == "" {
+		t.Fatalf("Expected the default deepseek_r1 endpoint, got %q", got)
+	}
+	c.SetModelEndpoints(map[string]string{"deepseek_r1": server.URL + "/eu/deepseek", "llama_3_1_70b": ""})
+
+	resp, err := c.GenerateWithLLM(context.Background(), &LLMRequest{Model: "deepseek_r1", Prompt: "Hi"})
+	if err != nil || resp.Choices[0].Text != "hosted" {
+		t.Fatalf("Expected the override endpoint to answer, got %+v (%v)", resp, err)
+	}
+	ch, errCh := c.GenerateWithLLMStream(context.Background(), &LLMRequest{Model: "deepseek_r1", Prompt: "Hi"})
+	for range ch {
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("GenerateWithLLMStream failed: %v", err)
+	}
+	resp, err = c.GenerateWithLLM(context.Background(), &LLMRequest{Model: "llama_3_1_70b", Prompt: "Hi"})
+	if err != nil || resp.Choices[0].Text != "generic" {
+		t.Fatalf("Expected a removed endpoint to use the generic LLM endpoint, got %+v (%v)", resp, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(paths, " ") != "/eu/deepseek /eu/deepseek /v1/inference/llm" {
+		t.Errorf("Unexpected request paths %v", paths)
+	}
+	if DefaultModelEndpoints()["llama_3_1_70b"] == "" {
+		t.Error("Expected overrides to leave the defaults untouched")
+	}
+}
+
+func TestChatCompletionGeneric(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/inference/llm" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if msgs, _ := body["messages"].([]interface{}); len(msgs) != 1 || body["model"] != "gpt-oss-20b" {
+			t.Errorf("Unexpected generic payload %v", body)
+		}
+		w.Write([]byte(`{"choices":[{"text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	resp, err := newTestClient(server.URL).ChatCompletion(context.Background(), "gpt-oss-20b", []ChatMessage{{Role: "user", Content: "hi"}})
+	if err != nil || resp.Choices[0].Text != "ok" {
+		t.Fatalf("Unexpected result %+v, %v", resp, err)
+	}
+	if _, err := newTestClient(server.URL).ChatCompletion(context.Background(), "gpt-oss-20b", nil); err == nil {
+		t.Error("Expected error for empty conversation")
+	}
+}
+
+func TestPing(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		wantCode int
+	}{
+		{"healthy", http.StatusOK, 0},
+		{"no health route", http.StatusNotFound, 0},
+		{"unauthorized", http.StatusUnauthorized, http.StatusUnauthorized},
+		{"server error", http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/v1/health" || r.Header.Get("Authorization") != "Bearer test_key" {
+					t.Errorf("Unexpected ping request %s %s", r.Method, r.URL.Path)
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			err := newTestClient(server.URL).Ping(context.Background())
+			var apiErr *APIError
+			if tc.wantCode == 0 {
+				if err != nil {
+					t.Errorf("Expected healthy, got %v", err)
+				}
+			} else if !errors.As(err, &apiErr) || apiErr.Code != tc.wantCode {
+				t.Errorf("Expected APIError %d, got %v", tc.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestWaitForJob(t *testing.T) {
+	var polls []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/jobs/job-42" {
+			t.Errorf("Unexpected poll %s %s", r.Method, r.URL.Path)
+		}
+		polls = append(polls, time.Now())
+		switch len(polls) {
+		case 1, 2:
+			w.Write([]byte(`{"id":"job-42","status":"pending"}`))
+		case 3:
+			w.Write([]byte(`{"id":"job-42","status":"running","progress":0.6}`))
+		default:
+			w.Write([]byte(`{"id":"job-42","status":"completed","video_url":"https://cdn/v.mp4"}`))
+		}
+	}))
+	defer server.Close()
+
+	status, err := newTestClient(server.URL).WaitForJob(context.Background(), "job-42", 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForJob failed: %v", err)
+	}
+	if len(polls) != 4 || status["video_url"] != "https://cdn/v.mp4" {
+		t.Errorf("Expected completion after 4 polls, got %d polls and %v", len(polls), status)
+	}
+	// Intervals double: 5ms, 10ms, 20ms
+	if gap := polls[3].Sub(polls[2]); gap < 18*time.Millisecond {
+		t.Errorf("Expected backoff to grow to ~20ms, last gap was %s", gap)
+	}
+}
+
+func TestWaitForJobFailedAndTimeout(t *testing.T) {
+	status := "failed"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"` + status + `","error":"out of GPU memory"}`))
+	}))
+	defer server.Close()
+	c := newTestClient(server.URL)
+
+	if _, err := c.WaitForJob(context.Background(), "job-1", time.Millisecond); err == nil || !strings.Contains(err.Error(), "out of GPU memory") {
+		t.Errorf("Expected failed job error, got %v", err)
+	}
+
+	status = "pending"
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := c.WaitForJob(ctx, "job-2", 10*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestModalityMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/inference/llm" {
+			w.Write([]byte(`{"choices":[{"text":"ok"}]}`))
+			return
+		}
+		w.Write([]byte(`{"id":"job","status":"completed"}`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	ctx := context.Background()
+	c.GenerateImage(ctx, &ImageGenerationRequest{Prompt: "x"})
+	c.GenerateImage(ctx, &ImageGenerationRequest{Prompt: "y"})
+	c.GenerateVideo(ctx, &VideoGenerationRequest{Prompt: "x"})
+	c.GenerateVoice(ctx, &TTSRequest{Text: "x"})
+	c.AnalyzeVision(ctx, &VisionRequest{Image: []byte("img")})
+	c.Generate3DModel(ctx, &Model3DRequest{Prompt: "x"})
+	c.GenerateWithLLM(ctx, &LLMRequest{Model: "gpt-oss-20b", Prompt: "x"})
+
+	m := c.Metrics()
+	want := ClientMetrics{LLMRequests: 1, ImageRequests: 2, VideoRequests: 1, TTSRequests: 1, VisionRequests: 1, Model3DRequests: 1}
+	if m != want {
+		t.Errorf("Unexpected metrics %+v, want %+v", m, want)
+	}
+}
+
+func TestGenerateVoiceStream(t *testing.T) {
+	frames := []string{"RIFF", "chunk-one", "chunk-two"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/v1/inference/kokoro" || !body.Stream || body.Text != "Hello there" {
+			t.Errorf("Unexpected request %s %+v", r.URL, body)
+		}
+		flusher := w.(http.Flusher)
+		if strings.HasSuffix(body.Voice, "_sse") {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i, f := range frames {
+				encoded := base64.StdEncoding.EncodeToString([]byte(f))
+				if i == 1 {
+					io.WriteString(w, "data: {\"audio\":\""+encoded+"\"}\n\n")
+				} else {
+					io.WriteString(w, "data: "+encoded+"\n\n")
+				}
+				flusher.Flush()
+			}
+			io.WriteString(w, "data: [DONE]\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		for _, f := range frames {
+			io.WriteString(w, f)
+			flusher.Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	for _, voice := range []string{"af_bella", "af_sse"} {
+		chunks, errs := c.GenerateVoiceStream(context.Background(), &TTSRequest{Text: "Hello there", Voice: voice})
+		var got []string
+		for chunk := range chunks {
+			got = append(got, string(chunk))
+		}
+		if err := <-errs; err != nil {
+			t.Fatalf("%s: stream failed: %v", voice, err)
+		}
+		if strings.Join(got, "") != strings.Join(frames, "") || len(got) < 2 {
+			t.Errorf("%s: expected multiple frames of %q, got %q", voice, frames, got)
+		}
+	}
+	if n := c.Metrics().TTSRequests; n != 2 {
+		t.Errorf("Expected 2 TTS requests counted, got %d", n)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	chunks, errs := newTestClient(failing.URL).GenerateVoiceStream(context.Background(), &TTSRequest{Text: "Hi"})
+	for range chunks {
+	}
+	if err := <-errs; err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected http 503 error, got %v", err)
+	}
+}
+
+func TestAnalyzeVisionMultipartFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/inference/grounding-dino" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Failed to parse multipart form: %v", err)
+		}
+		if _, _, err := r.FormFile("image"); err != nil {
+			t.Errorf("Expected image part: %v", err)
+		}
+		want := map[string]string{"query": "threats", "classes": "sword,shield", "threshold": "0.35", "max_results": "5"}
+		for field, v := range want {
+			if got := r.FormValue(field); got != v {
+				t.Errorf("Expected %s=%q, got %q", field, v, got)
+			}
+		}
+		w.Write([]byte(`{"id":"v1","status":"completed","detections":[{"label":"sword","confidence":0.9}]}`))
+	}))
+	defer server.Close()
+
+	resp, err := newTestClient(server.URL).AnalyzeVision(context.Background(), &VisionRequest{
+		Image: []byte("img"), Query: "threats", Classes: []string{"sword", "shield"}, Threshold: 0.35, MaxResults: 5,
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeVision failed: %v", err)
+	}
+	if len(resp.Detections) != 1 || resp.Detections[0].Label != "sword" {
+		t.Errorf("Unexpected detections %v", resp.Detections)
+	}
+}
+
+func TestAnalyzeVisionOmitsUnsetFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("Failed to parse multipart form: %v", err)
+		}
+		for _, field := range []string{"classes", "threshold", "max_results"} {
+			if _, ok := r.MultipartForm.Value[field]; ok {
+				t.Errorf("Expected %s to be omitted", field)
+			}
+		}
+		w.Write([]byte(`{"id":"v2","status":"completed"}`))
+	}))
+	defer server.Close()
+
+	if _, err := newTestClient(server.URL).AnalyzeVision(context.Background(), &VisionRequest{Image: []byte("img")}); err != nil {
+		t.Fatalf("AnalyzeVision failed: %v", err)
+	}
+}
+
+func TestGenerateWithLLMStreamGeneric(t *testing.T) {
+	streams := map[string][]string{
+		// incremental choices[].text frames closed by a full response object
+		"incremental": {
+			": keep-alive",
+			"event: token",
+			`data: {"object":"text_completion.chunk","choices":[{"index":0,"text":"The council"}]}`,
+			`data: {"object":"text_completion.chunk","choices":[{"index":0,"text":" agrees"}]}`,
+			`data: {"choices":[{"index":0,"delta":{"content":" to"}}]}`,
+			`data: {"delta":" the plan."}`,
+			`data: {"object":"text_completion","status":"completed","choices":[{"index":0,"text":"The council agrees to the plan.","finish_reason":"stop"}]}`,
+			"data: [DONE]",
+		},
+		// every frame repeats the completion so far
+		"cumulative": {
+			`data: {"choices":[{"text":"The council","finish_reason":null}],"status":"running"}`,
+			`data: {"status":"completed","choices":[{"text":"The council agrees","finish_reason":"length"}]}`,
+			`data: {"done":true,"choices":[{"text":"The council agrees to the plan.","finish_reason":"stop"}]}`,
+		},
+		// newline-delimited JSON with a final frame that only carries its own token
+		"ndjson": {
+			`{"text":"The council agrees"}`,
+			`{"choices":[{"text":" to the plan.","finish_reason":"stop"}]}`,
+		},
+		// the last chunk repeats the previous token; finish_reason alone does not make it cumulative
+		"repeated final token": {
+			`data: {"choices":[{"text":"no"}]}`,
+			`data: {"choices":[{"text":"no","finish_reason":"stop"}]}`,
+		},
+		"repeated punctuation": {
+			`data: {"text":"Stop"}`,
+			`data: {"choices":[{"text":"!!"}]}`,
+			`data: {"choices":[{"text":"!!","finish_reason":"stop"}]}`,
+		},
+		// a completed response repeating the whole text adds nothing
+		"completed repeat": {
+			`data: {"delta":"no"}`,
+			`data: {"done":true,"text":"no"}`,
+		},
+	}
+	want := map[string]string{
+		"incremental":          "The council agrees to the plan.",
+		"cumulative":           "The council agrees to the plan.",
+		"ndjson":               "The council agrees to the plan.",
+		"repeated final token": "nono",
+		"repeated punctuation": "Stop!!!!",
+		"completed repeat":     "no",
+	}
+	for name, frames := range streams {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body LLMRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			if r.URL.Path != "/v1/inference/llm" || !body.Stream {
+				t.Errorf("%s: unexpected request %s %+v", name, r.URL, body)
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, f := range frames {
+				io.WriteString(w, f+"\n\n")
+				w.(http.Flusher).Flush()
+			}
+		}))
+		req := &LLMRequest{Model: "generic", Prompt: "Vote?"}
+		tokens, errs := newTestClient(server.URL).GenerateWithLLMStream(context.Background(), req)
+		var got strings.Builder
+		for tok := range tokens {
+			got.WriteString(tok)
+		}
+		if err := <-errs; err != nil {
+			t.Fatalf("%s: stream failed: %v", name, err)
+		}
+		if got.String() != want[name] {
+			t.Errorf("%s: assembled %q, want %q", name, got.String(), want[name])
+		}
+		if req.Stream {
+			t.Errorf("%s: caller's request was modified", name)
+		}
+		server.Close()
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "data: {\"text\":\"Hel\"}\n\ndata: {\"error\":{\"message\":\"model overloaded\"}}\n\n")
+	}))
+	defer failing.Close()
+	tokens, errs := newTestClient(failing.URL).GenerateWithLLMStream(context.Background(), &LLMRequest{Prompt: "Hi"})
+	for range tokens {
+	}
+	if err := <-errs; err == nil || !strings.Contains(err.Error(), "model overloaded") {
+		t.Errorf("Expected in-stream error, got %v", err)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+		code   int
+	}{
+		{"rate limited", http.StatusTooManyRequests, `{"message":"slow down"}`, ErrRateLimited, 429},
+		{"server error", http.StatusInternalServerError, `{"message":"boom"}`, ErrServerError, 500},
+		{"malformed JSON", http.StatusOK, `{"choices": [`, ErrDecode, 0},
+	}
+	for _, tc := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			io.WriteString(w, tc.body)
+		}))
+		_, err := newTestClient(server.URL).GenerateWithLLM(context.Background(), &LLMRequest{Model: "generic", Prompt: "Hi"})
+		server.Close()
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+		for _, other := range []error{ErrRateLimited, ErrServerError, ErrDecode} {
+			if other != tc.want && errors.Is(err, other) {
+				t.Errorf("%s: error %v should not match %v", tc.name, err, other)
+			}
+		}
+		var apiErr *APIError
+		if got := errors.As(err, &apiErr); got != (tc.code != 0) || (got && apiErr.Code != tc.code) {
+			t.Errorf("%s: errors.As(*APIError) = %v (%+v), want code %d", tc.name, got, apiErr, tc.code)
+		}
+	}
+}
+
+func TestTokenUsageAccounting(t *testing.T) {
+	usages := []Usage{{PromptTokens: 100, CompletionTokens: 50, TotalTokens: 150}, {PromptTokens: 200, CompletionTokens: 25}, {}}
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(LLMResponse{Choices: []Choice{{Text: "ok"}}, Usage: usages[calls%len(usages)]})
+		calls++
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	c.SetModelCosts(map[string]ModelCost{"priced": {PromptPerMillion: 1000, CompletionPerMillion: 2000}})
+	ctx := context.Background()
+	if _, err := c.GenerateWithLLM(ctx, &LLMRequest{Model: "priced", Prompt: "a"}); err != nil {
+		t.Fatalf("GenerateWithLLM failed: %v", err)
+	}
+	if _, err := c.ChatCompletion(ctx, "unpriced", []ChatMessage{{Role: "user", Content: "b"}}); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if _, err := c.GenerateWithLLM(ctx, &LLMRequest{Model: "priced", Prompt: "c"}); err != nil {
+		t.Fatalf("GenerateWithLLM failed: %v", err)
+	}
+
+	got := c.Metrics().TokenUsage
+	want := TokenUsage{PromptTokens: 300, CompletionTokens: 75, TotalTokens: 375}
+	if got.PromptTokens != want.PromptTokens || got.CompletionTokens != want.CompletionTokens || got.TotalTokens != want.TotalTokens {
+		t.Errorf("Unexpected token usage %+v, want %+v", got, want)
+	}
+	// Only the first response used a priced model: 100 prompt + 50 completion tokens
+	if wantCost := 0.1 + 0.1; math.Abs(got.EstimatedCostUSD-wantCost) > 1e-9 {
+		t.Errorf("Expected estimated cost %.4f, got %.4f", wantCost, got.EstimatedCostUSD)
+	}
+
+	sse := []byte("data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n" +
+		"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3,\"total_tokens\":15}}\n" +
+		"data: [DONE]\n")
+	if u := parseCompletionUsage(sse); u.PromptTokens != 12 || u.CompletionTokens != 3 || u.TotalTokens != 15 {
+		t.Errorf("Unexpected SSE usage %+v", u)
+	}
+}
+
+func TestClientForUsesContextDeadline(t *testing.T) {
+	c := NewThetaClient("http://unused", "test_key")
+	c.SetTimeout(30 * time.Second)
+	if got := c.clientFor(context.Background()); got != c.httpClient {
+		t.Error("Expected a request without a deadline to keep the client timeout")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	if got := c.clientFor(ctx); got.Timeout != 0 || got.Transport != c.httpClient.Transport {
+		t.Errorf("Expected the deadline to replace the client timeout, got %s", got.Timeout)
+	}
+	if c.httpClient.Timeout != 30*time.Second {
+		t.Errorf("Expected the shared client timeout to stay 30s, got %s", c.httpClient.Timeout)
+	}
+}
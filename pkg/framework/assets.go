@@ -3,21 +3,36 @@ package framework
 import (
 	"context"
 	"crypto/sha1"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/emergent-world-engine/backend/internal/theta_client"
 )
 
-// AssetGenerator handles AI-powered asset generation
+// AssetGenerator handles AI-powered asset generation. It is safe for concurrent use; mu guards cache.
 type AssetGenerator struct {
 	engine   *Engine
 	cache    map[string]*Asset
 	config   *AssetConfig
 	mu       sync.RWMutex
 	maxCache int
+	sem      chan struct{} // bounds in-flight client calls to MaxConcurrent
+
+	stopSweep chan struct{} // closed by Close to stop the expiration sweeper; nil when none runs
+	sweepDone chan struct{}
+	closeOnce sync.Once
 }
 
 // AssetConfig holds asset generation configuration
@@ -30,6 +45,7 @@ type AssetConfig struct {
 	OutputFormat   string
 	DefaultStyle   string
 	MaxConcurrent  int
+	SweepInterval  time.Duration // how often expired cache entries are evicted in the background; 0 disables
 }
 
 // AssetOption allows configuring asset generation behavior
@@ -86,10 +102,43 @@ func WithDefaultStyle(style string) AssetOption {
 	}
 }
 
+// WithOutputFormat sets the image format requested from the model (default "png")
+func WithOutputFormat(format string) AssetOption {
+	return func(ag *AssetGenerator) {
+		if ag.config == nil {
+			ag.config = &AssetConfig{}
+		}
+		ag.config.OutputFormat = format
+	}
+}
+
+// WithCacheSweep evicts expired cache entries every interval from a background goroutine, so
+// entries that are never looked up again don't linger. It only runs when caching is enabled;
+// call AssetGenerator.Close to stop it.
+func WithCacheSweep(interval time.Duration) AssetOption {
+	return func(ag *AssetGenerator) {
+		if ag.config == nil {
+			ag.config = &AssetConfig{}
+		}
+		ag.config.SweepInterval = interval
+	}
+}
+
+// WithMaxConcurrent caps how many generation requests run against Theta at once
+// (default DefaultAssetConcurrency)
+func WithMaxConcurrent(n int) AssetOption {
+	return func(ag *AssetGenerator) {
+		if ag.config == nil {
+			ag.config = &AssetConfig{}
+		}
+		ag.config.MaxConcurrent = n
+	}
+}
+
 // Asset represents a generated game asset
 type Asset struct {
 	ID          string                 `json:"id"`
-	Type        string                 `json:"type"` // "image", "texture", "model", "audio", "video"
+	Type        string                 `json:"type"` // "image", "texture", AssetTypeModel, "audio", "video"
 	Format      string                 `json:"format"`
 	Data        []byte                 `json:"data,omitempty"`
 	URL         string                 `json:"url,omitempty"`
@@ -113,11 +162,12 @@ type Dimensions struct {
 // ImageRequest contains parameters for image generation
 type ImageRequest struct {
 	Prompt      string            `json:"prompt"`
+	NegativePrompt string         `json:"negative_prompt,omitempty"`
 	Style       string            `json:"style,omitempty"`
 	Width       int               `json:"width"`
 	Height      int               `json:"height"`
-	Quality     string            `json:"quality,omitempty"`
-	Seed        int64             `json:"seed,omitempty"`
+	Quality     string            `json:"quality,omitempty"` // draft, standard, high; defaults to the generator quality
+	Seed        int64             `json:"seed,omitempty"`    // fixed seed for reproducible output; 0 lets the model pick
 	Variations  int               `json:"variations"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -144,10 +194,15 @@ type TextureRequest struct {
 	Material     string                 `json:"material"`     // "stone", "wood", "metal", "fabric"
 	Resolution   int                    `json:"resolution"`
 	Tileable     bool                   `json:"tileable"`
+	VerifyTiling bool                   `json:"verify_tiling,omitempty"` // check a tileable texture's seams and mirror-blend it if they show
 	Style        string                 `json:"style,omitempty"`
+	Seed         int64                  `json:"seed,omitempty"` // shared across a texture set for coherent maps
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// PBRTextureTypes are the maps produced by GenerateTextureSet
+var PBRTextureTypes = []string{"diffuse", "normal", "roughness", "metallic"}
+
 // ConceptArtRequest contains parameters for concept art generation
 type ConceptArtRequest struct {
 	Description   string                 `json:"description"`
@@ -159,16 +214,23 @@ type ConceptArtRequest struct {
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// Model3DRequest contains parameters for 3D model generation
+type Model3DRequest struct {
+	Prompt          string                 `json:"prompt"`
+	Style           string                 `json:"style,omitempty"`
+	ReferenceImages [][]byte               `json:"-"`                    // Optional images uploaded as multipart
+	ModelType       string                 `json:"model_type,omitempty"` // "character", "prop", "environment"
+	Resolution      string                 `json:"resolution,omitempty"` // "low", "medium", "high"
+	Format          string                 `json:"format,omitempty"`     // FormatOBJ, FormatFBX, FormatGLTF (default) or FormatPLY
+	IncludeTextures bool                   `json:"include_textures"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
 // GenerateImage creates images using FLUX.1-schnell or other AI models
 func (ag *AssetGenerator) GenerateImage(ctx context.Context, req *ImageRequest) (*Asset, error) {
-	// Check cache first
-	if ag.config != nil && ag.config.CacheEnabled {
-		if cached := ag.getCachedAsset(req.Prompt, "image"); cached != nil {
-			return cached, nil
-		}
-	}
-	
-	// Set defaults
+	// Set defaults on a copy, leaving the caller's request untouched
+	r := *req
+	req = &r
 	if req.Width == 0 {
 		req.Width = 512
 	}
@@ -185,6 +247,18 @@ func (ag *AssetGenerator) GenerateImage(ctx context.Context, req *ImageRequest)
 		style = ag.config.DefaultStyle
 	}
 	
+	// Check cache first (same prompt at a different size, style or seed is a different image)
+	quality := req.Quality
+	if quality == "" {
+		quality = ag.getQualityLevel()
+	}
+	cacheKey := fmt.Sprintf("%s_%s_%dx%d_seed%d_%s_%s", req.Prompt, style, req.Width, req.Height, req.Seed, quality, req.NegativePrompt)
+	if ag.config != nil && ag.config.CacheEnabled {
+		if cached := ag.getCachedAsset(cacheKey, "image"); cached != nil {
+			return cached, nil
+		}
+	}
+
 	// Enhance prompt with style
 	enhancedPrompt := req.Prompt
 	if style != "" {
@@ -192,14 +266,26 @@ func (ag *AssetGenerator) GenerateImage(ctx context.Context, req *ImageRequest)
 	}
 	
 	// Generate image using Theta client
+	format := ag.getOutputFormat()
+	steps, guidance := imageQualityParams(quality)
 	imgReq := &theta_client.ImageGenerationRequest{
-		Prompt: enhancedPrompt,
-		Width:  req.Width,
-		Height: req.Height,
-		Format: "png",
+		Prompt:         enhancedPrompt,
+		NegativePrompt: req.NegativePrompt,
+		Width:          req.Width,
+		Height:         req.Height,
+		Steps:          steps,
+		GuidanceScale:  guidance,
+		Seed:           req.Seed,
+		Format:         format,
 	}
 	
-	imgResp, err := ag.engine.thetaClient.GenerateImage(ctx, imgReq)
+	if err := ag.acquire(ctx); err != nil {
+		return nil, fmt.Errorf("failed to generate image: %w", err)
+	}
+	callCtx, cancel := ag.engine.withCallTimeout(ctx, ag.engine.timeouts.image)
+	defer cancel()
+	imgResp, err := ag.engine.thetaClient.GenerateImage(callCtx, imgReq)
+	ag.release()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate image: %w", err)
 	}
@@ -209,17 +295,16 @@ func (ag *AssetGenerator) GenerateImage(ctx context.Context, req *ImageRequest)
 	var imageData []byte
 	if len(imgResp.Images) > 0 {
 		imageURL = imgResp.Images[0].URL
-		// Convert base64 to bytes if available
-		if imgResp.Images[0].Base64 != "" {
-			// In a real implementation, decode base64 here
-			// imageData, _ = base64.StdEncoding.DecodeString(imgResp.Images[0].Base64)
+		imageData, err = decodeImagePayload(imgResp.Images[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image: %w", err)
 		}
 	}
 
 	asset := &Asset{
-		ID:     fmt.Sprintf("img_%d", time.Now().UnixNano()),
+		ID:     ag.newAssetID("img", cacheKey, "image"),
 		Type:   "image",
-		Format: "png",
+		Format: format,
 		URL:    imageURL,
 		Data:   imageData,
 		Prompt: req.Prompt,
@@ -236,7 +321,7 @@ func (ag *AssetGenerator) GenerateImage(ctx context.Context, req *ImageRequest)
 	if ag.config != nil && ag.config.CacheEnabled {
 		expiration := time.Now().Add(ag.config.CacheDuration)
 		asset.ExpiresAt = &expiration
-		ag.mu.Lock(); if ag.cache == nil { ag.cache = make(map[string]*Asset) }; ag.cache[ag.getCacheKey(req.Prompt, "image")] = asset; ag.enforceCacheLimitLocked(); ag.mu.Unlock()
+		ag.mu.Lock(); if ag.cache == nil { ag.cache = make(map[string]*Asset) }; ag.cache[ag.getCacheKey(cacheKey, "image")] = asset; ag.enforceCacheLimitLocked(); ag.mu.Unlock()
 	}
 	
 	return asset, nil
@@ -244,14 +329,9 @@ func (ag *AssetGenerator) GenerateImage(ctx context.Context, req *ImageRequest)
 
 // GenerateVideo creates videos using AI models
 func (ag *AssetGenerator) GenerateVideo(ctx context.Context, req *VideoRequest) (*Asset, error) {
-	// Check cache first
-	if ag.config != nil && ag.config.CacheEnabled {
-		if cached := ag.getCachedAsset(req.Prompt, "video"); cached != nil {
-			return cached, nil
-		}
-	}
-	
-	// Set defaults
+	// Set defaults on a copy, leaving the caller's request untouched
+	r := *req
+	req = &r
 	if req.Width == 0 {
 		req.Width = 1280
 	}
@@ -264,12 +344,27 @@ func (ag *AssetGenerator) GenerateVideo(ctx context.Context, req *VideoRequest)
 	if req.Duration == 0 {
 		req.Duration = 10.0 // Default to 10 seconds
 	}
-	
+	if req.Format == "" {
+		req.Format = theta_client.FormatMP4
+	}
+	quality := req.Quality
+	if quality == "" {
+		quality = ag.getQualityLevel()
+	}
+
 	// Apply default style if not specified
 	style := req.Style
 	if style == "" && ag.config != nil && ag.config.DefaultStyle != "" {
 		style = ag.config.DefaultStyle
 	}
+
+	// Check cache first (same prompt at different dimensions, timing, quality, motion or seed is a different video)
+	cacheKey := fmt.Sprintf("%s_%s_%dx%d_%.2fs_%dfps_%s_%s_motion%.2f_seed%d", req.Prompt, style, req.Width, req.Height, req.Duration, req.FPS, req.Format, quality, req.MotionStrength, req.Seed)
+	if ag.config != nil && ag.config.CacheEnabled {
+		if cached := ag.getCachedAsset(cacheKey, "video"); cached != nil {
+			return cached, nil
+		}
+	}
 	
 	// Enhance prompt with style
 	enhancedPrompt := req.Prompt
@@ -284,12 +379,19 @@ func (ag *AssetGenerator) GenerateVideo(ctx context.Context, req *VideoRequest)
 		Height:         req.Height,
 		Duration:       req.Duration,
 		FPS:            req.FPS,
-		Quality:        ag.getQualityLevel(),
+		Seed:           req.Seed,
+		Quality:        quality,
 		MotionStrength: req.MotionStrength,
-		Format:         "mp4",
+		Format:         req.Format,
 	}
 	
-	videoResp, err := ag.engine.thetaClient.GenerateVideo(ctx, videoReq)
+	if err := ag.acquire(ctx); err != nil {
+		return nil, fmt.Errorf("failed to generate video: %w", err)
+	}
+	callCtx, cancel := ag.engine.withCallTimeout(ctx, ag.engine.timeouts.video)
+	defer cancel()
+	videoResp, err := ag.engine.thetaClient.GenerateVideo(callCtx, videoReq)
+	ag.release()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate video: %w", err)
 	}
@@ -307,9 +409,9 @@ func (ag *AssetGenerator) GenerateVideo(ctx context.Context, req *VideoRequest)
 	}
 
 	asset := &Asset{
-		ID:     fmt.Sprintf("vid_%d", time.Now().UnixNano()),
+		ID:     ag.newAssetID("vid", cacheKey, "video"),
 		Type:   "video",
-		Format: "mp4",
+		Format: req.Format,
 		URL:    videoURL,
 		Data:   videoData,
 		Prompt: req.Prompt,
@@ -328,7 +430,7 @@ func (ag *AssetGenerator) GenerateVideo(ctx context.Context, req *VideoRequest)
 	if ag.config != nil && ag.config.CacheEnabled {
 		expiration := time.Now().Add(ag.config.CacheDuration)
 		asset.ExpiresAt = &expiration
-		ag.mu.Lock(); if ag.cache == nil { ag.cache = make(map[string]*Asset) }; ag.cache[ag.getCacheKey(req.Prompt, "video")] = asset; ag.enforceCacheLimitLocked(); ag.mu.Unlock()
+		ag.mu.Lock(); if ag.cache == nil { ag.cache = make(map[string]*Asset) }; ag.cache[ag.getCacheKey(cacheKey, "video")] = asset; ag.enforceCacheLimitLocked(); ag.mu.Unlock()
 	}
 	
 	return asset, nil
@@ -336,8 +438,15 @@ func (ag *AssetGenerator) GenerateVideo(ctx context.Context, req *VideoRequest)
 
 // GenerateTexture creates game textures with specific properties
 func (ag *AssetGenerator) GenerateTexture(ctx context.Context, req *TextureRequest) (*Asset, error) {
+	// Set defaults on a copy, leaving the caller's request untouched
+	r := *req
+	req = &r
+	if req.Resolution == 0 {
+		req.Resolution = 512
+	}
+
 	// Check cache
-	cacheKey := fmt.Sprintf("%s_%s_%s", req.BasePrompt, req.TextureType, req.Material)
+	cacheKey := fmt.Sprintf("%s_%s_%s_%s_%dpx_tileable%t_verify%t_seed%d", req.BasePrompt, req.TextureType, req.Material, req.Style, req.Resolution, req.Tileable, req.VerifyTiling, req.Seed)
 	if ag.config != nil && ag.config.CacheEnabled {
 		if cached := ag.getCachedAsset(cacheKey, "texture"); cached != nil {
 			return cached, nil
@@ -347,20 +456,22 @@ func (ag *AssetGenerator) GenerateTexture(ctx context.Context, req *TextureReque
 	// Build texture-specific prompt
 	prompt := ag.buildTexturePrompt(req)
 	
-	// Set defaults
-	if req.Resolution == 0 {
-		req.Resolution = 512
-	}
-	
 	// Generate texture
 	imgReq := &theta_client.ImageGenerationRequest{
 		Prompt: prompt,
 		Width:  req.Resolution,
 		Height: req.Resolution,
+		Seed:   req.Seed,
 		Format: "png",
 	}
 	
-	imgResp, err := ag.engine.thetaClient.GenerateImage(ctx, imgReq)
+	if err := ag.acquire(ctx); err != nil {
+		return nil, fmt.Errorf("failed to generate texture: %w", err)
+	}
+	callCtx, cancel := ag.engine.withCallTimeout(ctx, ag.engine.timeouts.image)
+	defer cancel()
+	imgResp, err := ag.engine.thetaClient.GenerateImage(callCtx, imgReq)
+	ag.release()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate texture: %w", err)
 	}
@@ -370,15 +481,14 @@ func (ag *AssetGenerator) GenerateTexture(ctx context.Context, req *TextureReque
 	var textureData []byte
 	if len(imgResp.Images) > 0 {
 		textureURL = imgResp.Images[0].URL
-		// Convert base64 to bytes if available
-		if imgResp.Images[0].Base64 != "" {
-			// In a real implementation, decode base64 here
-			// textureData, _ = base64.StdEncoding.DecodeString(imgResp.Images[0].Base64)
+		textureData, err = decodeImagePayload(imgResp.Images[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode texture: %w", err)
 		}
 	}
 
 	asset := &Asset{
-		ID:     fmt.Sprintf("tex_%d", time.Now().UnixNano()),
+		ID:     ag.newAssetID("tex", cacheKey, "texture"),
 		Type:   "texture",
 		Format: "png",
 		URL:    textureURL,
@@ -393,9 +503,13 @@ func (ag *AssetGenerator) GenerateTexture(ctx context.Context, req *TextureReque
 			"texture_type": req.TextureType,
 			"material":     req.Material,
 			"tileable":     req.Tileable,
+			"seed":         req.Seed,
 		},
 		GeneratedAt: time.Now(),
 	}
+	if req.Tileable && req.VerifyTiling {
+		ag.verifyTiling(ctx, asset)
+	}
 	
 	// Add to cache
 	if ag.config != nil && ag.config.CacheEnabled {
@@ -407,10 +521,53 @@ func (ag *AssetGenerator) GenerateTexture(ctx context.Context, req *TextureReque
 	return asset, nil
 }
 
+// GenerateTextureSet generates diffuse, normal, roughness and metallic maps for one material,
+// keyed by texture type. All maps share req.Seed (a random one if unset) so they stay coherent;
+// each map is generated and cached like a single GenerateTexture call.
+func (ag *AssetGenerator) GenerateTextureSet(ctx context.Context, req *TextureRequest) (map[string]*Asset, error) {
+	seed := req.Seed
+	if seed == 0 {
+		seed = rand.Int63n(1<<31-1) + 1
+	}
+
+	type result struct {
+		textureType string
+		asset       *Asset
+		err         error
+	}
+	results := make(chan result, len(PBRTextureTypes))
+	for _, textureType := range PBRTextureTypes {
+		mapReq := *req
+		mapReq.TextureType = textureType
+		mapReq.Seed = seed
+		go func(r TextureRequest) {
+			asset, err := ag.GenerateTexture(ctx, &r)
+			results <- result{r.TextureType, asset, err}
+		}(mapReq)
+	}
+
+	set := make(map[string]*Asset, len(PBRTextureTypes))
+	var firstErr error
+	for range PBRTextureTypes {
+		r := <-results
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to generate %s map: %w", r.textureType, r.err)
+			}
+			continue
+		}
+		set[r.textureType] = r.asset
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return set, nil
+}
+
 // GenerateConceptArt creates concept art for game design
 func (ag *AssetGenerator) GenerateConceptArt(ctx context.Context, req *ConceptArtRequest) (*Asset, error) {
 	// Check cache
-	cacheKey := fmt.Sprintf("%s_%s_%s", req.Description, req.ArtStyle, req.Perspective)
+	cacheKey := fmt.Sprintf("%s_%s_%s_%s_%s_1024x768", req.Description, req.ArtStyle, req.Perspective, req.Details, req.ColorPalette)
 	if ag.config != nil && ag.config.CacheEnabled {
 		if cached := ag.getCachedAsset(cacheKey, "concept"); cached != nil {
 			return cached, nil
@@ -428,7 +585,13 @@ func (ag *AssetGenerator) GenerateConceptArt(ctx context.Context, req *ConceptAr
 		Format: "png",
 	}
 	
-	imgResp, err := ag.engine.thetaClient.GenerateImage(ctx, imgReq)
+	if err := ag.acquire(ctx); err != nil {
+		return nil, fmt.Errorf("failed to generate concept art: %w", err)
+	}
+	callCtx, cancel := ag.engine.withCallTimeout(ctx, ag.engine.timeouts.image)
+	defer cancel()
+	imgResp, err := ag.engine.thetaClient.GenerateImage(callCtx, imgReq)
+	ag.release()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate concept art: %w", err)
 	}
@@ -438,15 +601,14 @@ func (ag *AssetGenerator) GenerateConceptArt(ctx context.Context, req *ConceptAr
 	var conceptData []byte
 	if len(imgResp.Images) > 0 {
 		conceptURL = imgResp.Images[0].URL
-		// Convert base64 to bytes if available
-		if imgResp.Images[0].Base64 != "" {
-			// In a real implementation, decode base64 here
-			// conceptData, _ = base64.StdEncoding.DecodeString(imgResp.Images[0].Base64)
+		conceptData, err = decodeImagePayload(imgResp.Images[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode concept art: %w", err)
 		}
 	}
 
 	asset := &Asset{
-		ID:     fmt.Sprintf("concept_%d", time.Now().UnixNano()),
+		ID:     ag.newAssetID("concept", cacheKey, "concept"),
 		Type:   "concept_art",
 		Format: "png",
 		URL:    conceptURL,
@@ -475,19 +637,128 @@ func (ag *AssetGenerator) GenerateConceptArt(ctx context.Context, req *ConceptAr
 	return asset, nil
 }
 
+// ErrUnsupportedFormat is returned when a request names an output format the generator cannot produce
+var ErrUnsupportedFormat = errors.New("unsupported asset format")
+
+// model3DFormats are the formats accepted by Generate3DModel
+var model3DFormats = []string{FormatOBJ, FormatFBX, FormatGLTF, FormatPLY}
+
+// normalize3DFormat lowercases format (tolerating a leading dot) and checks it against model3DFormats;
+// an empty format defaults to glTF
+func normalize3DFormat(format string) (string, error) {
+	f := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(format)), ".")
+	if f == "" {
+		return FormatGLTF, nil
+	}
+	for _, known := range model3DFormats {
+		if f == known {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w: 3D model format %q (want one of %s)", ErrUnsupportedFormat, format, strings.Join(model3DFormats, ", "))
+}
+
+// Generate3DModel creates a 3D model asset, optionally guided by reference images. The format must
+// be one of FormatOBJ, FormatFBX, FormatGLTF or FormatPLY (ErrUnsupportedFormat otherwise); the
+// returned asset's Metadata carries "format" and, when textures were produced, "texture_urls".
+func (ag *AssetGenerator) Generate3DModel(ctx context.Context, req *Model3DRequest) (*Asset, error) {
+	format, err := normalize3DFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+	r := *req // normalize a copy, leaving the caller's request untouched
+	r.Format = format
+	req = &r
+
+	// Apply default style if not specified
+	style := req.Style
+	if style == "" && ag.config != nil && ag.config.DefaultStyle != "" {
+		style = ag.config.DefaultStyle
+	}
+
+	// Check cache (reference-image requests are not cached since the prompt alone doesn't identify them)
+	cacheKey := fmt.Sprintf("%s_%s_%s_%s_%s_%t", req.Prompt, style, req.ModelType, req.Resolution, req.Format, req.IncludeTextures)
+	cacheable := ag.config != nil && ag.config.CacheEnabled && len(req.ReferenceImages) == 0
+	if cacheable {
+		if cached := ag.getCachedAsset(cacheKey, AssetTypeModel); cached != nil {
+			return cached, nil
+		}
+	}
+	enhancedPrompt := req.Prompt
+	if style != "" {
+		enhancedPrompt = fmt.Sprintf("%s, %s style", req.Prompt, style)
+	}
+
+	if err := ag.acquire(ctx); err != nil {
+		return nil, fmt.Errorf("failed to generate 3D model: %w", err)
+	}
+	callCtx, cancel := ag.engine.withCallTimeout(ctx, ag.engine.timeouts.video)
+	defer cancel()
+	modelResp, err := ag.engine.thetaClient.Generate3DModel(callCtx, &theta_client.Model3DRequest{
+		Prompt:          enhancedPrompt,
+		ReferenceImages: req.ReferenceImages,
+		ModelType:       req.ModelType,
+		Resolution:      req.Resolution,
+		Format:          req.Format,
+		IncludeTextures: req.IncludeTextures,
+		Metadata:        req.Metadata,
+	})
+	ag.release()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate 3D model: %w", err)
+	}
+
+	metadata := map[string]interface{}{
+		"model_type": req.ModelType,
+		"resolution": req.Resolution,
+	}
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	metadata["format"] = req.Format
+	if len(modelResp.TextureURLs) > 0 {
+		metadata["texture_urls"] = modelResp.TextureURLs
+	}
+
+	asset := &Asset{
+		ID:          ag.newAssetID("model", cacheKey, AssetTypeModel),
+		Type:        AssetTypeModel,
+		Format:      req.Format,
+		URL:         modelResp.ModelURL,
+		Data:        modelResp.ModelData,
+		Prompt:      req.Prompt,
+		Style:       style,
+		Metadata:    metadata,
+		GeneratedAt: time.Now(),
+	}
+
+	// Add to cache
+	if cacheable {
+		expiration := time.Now().Add(ag.config.CacheDuration)
+		asset.ExpiresAt = &expiration
+		ag.mu.Lock(); if ag.cache == nil { ag.cache = make(map[string]*Asset) }; ag.cache[ag.getCacheKey(cacheKey, AssetTypeModel)] = asset; ag.enforceCacheLimitLocked(); ag.mu.Unlock()
+	}
+
+	return asset, nil
+}
+
 // GetAsset retrieves a generated asset by ID
 func (ag *AssetGenerator) GetAsset(assetID string) (*Asset, bool) {
 	// Check cache first
-	for _, asset := range ag.cache {
+	ag.mu.Lock()
+	for key, asset := range ag.cache {
 		if asset.ID == assetID {
 			// Check if expired
 			if asset.ExpiresAt != nil && time.Now().After(*asset.ExpiresAt) {
-				delete(ag.cache, ag.getCacheKey(asset.Prompt, asset.Type))
+				delete(ag.cache, key)
+				ag.mu.Unlock()
 				return nil, false
 			}
+			ag.mu.Unlock()
 			return asset, true
 		}
 	}
+	ag.mu.Unlock()
 	
 	// Check Redis if available
 	if ag.engine.IsRedisEnabled() {
@@ -501,6 +772,50 @@ func (ag *AssetGenerator) GetAsset(assetID string) (*Asset, bool) {
 	return nil, false
 }
 
+// startSweeper runs sweepExpired on a ticker until Close
+func (ag *AssetGenerator) startSweeper(interval time.Duration) {
+	ag.stopSweep = make(chan struct{})
+	ag.sweepDone = make(chan struct{})
+	go func() {
+		defer close(ag.sweepDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ag.stopSweep:
+				return
+			case now := <-ticker.C:
+				if n := ag.sweepExpired(now); n > 0 {
+					ag.engine.logger.Debugf("asset cache: swept %d expired entries", n)
+				}
+			}
+		}
+	}()
+}
+
+// sweepExpired evicts cache entries that expired before now and returns how many were removed
+func (ag *AssetGenerator) sweepExpired(now time.Time) int {
+	ag.mu.Lock(); defer ag.mu.Unlock()
+	removed := 0
+	for key, asset := range ag.cache {
+		if asset.ExpiresAt != nil && now.After(*asset.ExpiresAt) {
+			delete(ag.cache, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Close stops the background cache sweeper, if one is running. It is safe to call more than once.
+func (ag *AssetGenerator) Close() {
+	ag.closeOnce.Do(func() {
+		if ag.stopSweep != nil {
+			close(ag.stopSweep)
+			<-ag.sweepDone
+		}
+	})
+}
+
 // ListAssets returns all cached assets
 func (ag *AssetGenerator) ListAssets() []*Asset {
 	ag.mu.Lock(); defer ag.mu.Unlock()
@@ -519,6 +834,51 @@ func (ag *AssetGenerator) ListAssets() []*Asset {
 	return assets
 }
 
+// SaveToDir writes the asset to dir as "<id>.<format>" and returns the file path.
+// Inline Data is written as-is; otherwise the asset is downloaded from its URL.
+func (a *Asset) SaveToDir(ctx context.Context, dir string) (string, error) {
+	data := a.Data
+	if len(data) == 0 {
+		if a.URL == "" {
+			return "", fmt.Errorf("asset %s has no data or URL", a.ID)
+		}
+		body, err := downloadAsset(ctx, a.URL)
+		if err != nil {
+			return "", fmt.Errorf("failed to download asset %s: %w", a.ID, err)
+		}
+		data = body
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	format := a.Format
+	if format == "" {
+		format = "png"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s.%s", filepath.Base(a.ID), format))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write asset: %w", err)
+	}
+	return path, nil
+}
+
+func downloadAsset(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
 // ClearCache removes all cached assets
 func (ag *AssetGenerator) ClearCache() { ag.mu.Lock(); ag.cache = make(map[string]*Asset); ag.mu.Unlock() }
 
@@ -533,6 +893,25 @@ func (ag *AssetGenerator) enforceCacheLimitLocked() {
 
 // Helper methods
 
+// decodeImagePayload returns the raw bytes of an inline base64 image (plain or data-URL form).
+// Hosted-only images return nil data; the URL remains the primary reference either way.
+func decodeImagePayload(img theta_client.Image) ([]byte, error) {
+	payload := strings.TrimSpace(img.Base64)
+	if payload == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(payload, "data:") {
+		if idx := strings.Index(payload, ","); idx >= 0 {
+			payload = payload[idx+1:]
+		}
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 image payload: %w", err)
+	}
+	return data, nil
+}
+
 func (ag *AssetGenerator) buildTexturePrompt(req *TextureRequest) string {
 	prompt := fmt.Sprintf("High-resolution %s %s texture", req.Material, req.TextureType)
 	
@@ -594,6 +973,45 @@ func (ag *AssetGenerator) buildConceptArtPrompt(req *ConceptArtRequest) string {
 	return prompt
 }
 
+// acquire takes a concurrency slot, giving up when ctx is cancelled
+func (ag *AssetGenerator) acquire(ctx context.Context) error {
+	if ag.sem == nil {
+		return nil
+	}
+	select {
+	case ag.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire
+func (ag *AssetGenerator) release() {
+	if ag.sem != nil {
+		<-ag.sem
+	}
+}
+
+// imageQualitySettings maps quality levels to diffusion steps and guidance scale
+var imageQualitySettings = map[string]struct {
+	Steps    int
+	Guidance float64
+}{
+	"draft":    {Steps: 4, Guidance: 3.5},
+	"standard": {Steps: 12, Guidance: 5.0},
+	"high":     {Steps: 28, Guidance: 7.0},
+}
+
+// imageQualityParams returns steps and guidance for a quality level, falling back to standard
+func imageQualityParams(quality string) (int, float64) {
+	q, ok := imageQualitySettings[strings.ToLower(quality)]
+	if !ok {
+		q = imageQualitySettings["standard"]
+	}
+	return q.Steps, q.Guidance
+}
+
 func (ag *AssetGenerator) getQualityLevel() string {
 	if ag.config != nil && ag.config.Quality != "" {
 		return ag.config.Quality
@@ -601,17 +1019,37 @@ func (ag *AssetGenerator) getQualityLevel() string {
 	return "standard"
 }
 
+func (ag *AssetGenerator) getOutputFormat() string {
+	if ag.config != nil && ag.config.OutputFormat != "" {
+		return ag.config.OutputFormat
+	}
+	return "png"
+}
+
 func (ag *AssetGenerator) getCacheKey(prompt, assetType string) string {
 	h := sha1.Sum([]byte(assetType + "|" + prompt))
 	return hex.EncodeToString(h[:])
 }
 
+// assetSeq numbers assets generated by this process, keeping their IDs unique
+var assetSeq atomic.Uint64
+
+// newAssetID returns "<prefix>_<digest>_<suffix>". The digest is the first 12 hex digits of the
+// asset's cache key (see getCacheKey), so identical requests, including their seed, share a stable
+// and traceable prefix. The suffix is a process-wide sequence number and random hex, so two assets
+// never share an ID, however close together they are generated.
+func (ag *AssetGenerator) newAssetID(prefix, cacheKey, assetType string) string {
+	return fmt.Sprintf("%s_%s_%s%04x", prefix, ag.getCacheKey(cacheKey, assetType)[:12], strconv.FormatUint(assetSeq.Add(1), 36), rand.Intn(1<<16))
+}
+
+// getCachedAsset returns the unexpired cached asset for prompt, evicting it if it has expired.
+// Eviction happens under the same lock as the lookup so a fresh entry stored meanwhile is never dropped.
 func (ag *AssetGenerator) getCachedAsset(prompt, assetType string) *Asset {
-	ag.mu.RLock(); defer ag.mu.RUnlock()
+	ag.mu.Lock(); defer ag.mu.Unlock()
 	key := ag.getCacheKey(prompt, assetType)
 	if asset, exists := ag.cache[key]; exists {
 		if asset.ExpiresAt != nil && time.Now().After(*asset.ExpiresAt) {
-			go func(k string){ ag.mu.Lock(); delete(ag.cache, k); ag.mu.Unlock() }(key)
+			delete(ag.cache, key)
 			return nil
 		}
 		return asset
@@ -1,5 +1,11 @@
 package framework
 
+import (
+	"time"
+
+	"github.com/emergent-world-engine/backend/internal/theta_client"
+)
+
 // Model and system constants to avoid hard-coded literals
 const (
 	ModelDialogueDefault   = "deepseek_r1"
@@ -17,8 +23,57 @@ const (
 	DefaultDialogueMaxTokens = 220
 	DefaultReasoningMaxTokens = 300
 	DefaultStoryMaxTokens = 400
+	DefaultSummaryMaxTokens = 150
 	DefaultRetryAttempts      = 3
 	DefaultRetryBackoffMs     = 200
+	DefaultRateLimitRPS       = 8
 	DefaultMaxNPCMemory       = 200
 	DefaultAssetCacheMax      = 500
+	DefaultAssetConcurrency   = 4
+	DefaultGroupDialogueConcurrency = 4 // NPCs generating at once in GenerateGroupDialogue
+	MaxEventHistoryWindow     = 10
+	MaxContextValueLen        = 120
+	DefaultEmotionMaxTokens   = 8
+	MaxLoreConsistencyEntries = 8
+	MinTemperature            = 0.0
+	MaxTemperature            = 2.0
+	DefaultMemoryImportance   = 0.5 // importance given to dialogue entries when ranking for eviction
+	MaxPromptMemories         = 5   // top-ranked memories injected into each dialogue prompt
+	MinDifficulty             = 0.0
+	MaxDifficulty             = 2.0
+)
+
+// Per-call timeouts by modality (overridable in Config)
+const (
+	DefaultDialogueTimeout  = 30 * time.Second
+	DefaultReasoningTimeout = 60 * time.Second
+	DefaultImageTimeout     = 2 * time.Minute
+	DefaultVideoTimeout     = 10 * time.Minute
+)
+
+// Limits for images fetched by NPC.PerceiveURL
+const (
+	PerceiveFetchTimeout  = 15 * time.Second
+	MaxPerceiveImageBytes = 10 << 20
+)
+
+// AssetTypeModel is the Asset.Type of 3D models from AssetGenerator.Generate3DModel
+const AssetTypeModel = "model"
+
+// 3D model formats accepted by AssetGenerator.Generate3DModel
+const (
+	FormatOBJ  = theta_client.FormatOBJ
+	FormatFBX  = theta_client.FormatFBX
+	FormatGLTF = theta_client.FormatGLTF
+	FormatPLY  = theta_client.FormatPLY
+)
+
+// Emotions produced by NPC emotion detection
+const (
+	EmotionHappy     = "happy"
+	EmotionAngry     = "angry"
+	EmotionFearful   = "fearful"
+	EmotionSad       = "sad"
+	EmotionNeutral   = "neutral"
+	EmotionSurprised = "surprised"
 )
@@ -2,18 +2,30 @@ package framework
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/emergent-world-engine/backend/internal/redis_client"
 	"github.com/emergent-world-engine/backend/internal/theta_client"
+	"github.com/emergent-world-engine/backend/pkg/jsonextract"
+	"github.com/emergent-world-engine/backend/pkg/textutil"
 )
 
-// Director represents the AI Game Director for strategic decisions
+// Director represents the AI Game Director for strategic decisions. mu guards gameState, config
+// and handlers, so a Director may be shared between goroutines.
 type Director struct {
 	engine    *Engine
 	gameState map[string]interface{}
 	config    *DirectorConfig
+	handlers  map[string]DirectorActionHandler
 	mu        sync.RWMutex
 }
 
@@ -24,11 +36,52 @@ type DirectorConfig struct {
 	PlayerAnalysis    bool
 	EventGeneration   bool
 	DifficultyScaling bool
+	HistoryWindow     int // recent turns rendered into the evaluation prompt (0 disables)
+	Temperature       *float64 // overrides the per-call sampling temperature when set
+	MaxTokens         int      // overrides the per-call completion token budget when > 0
+	MetricSchema      []string // metric names the event evaluation scores; empty uses DefaultMetricSchema
 }
 
+// DefaultMetricSchema is the presidential simulator's metric set used when no schema is configured
+var DefaultMetricSchema = []string{"Public Opinion", "Economy", "National Security", "Geopolitical Standing", "Tech Sector Confidence", "Civil Liberties"}
+
 // DirectorOption allows configuring Director behavior
 type DirectorOption func(*Director)
 
+// WithDirectorMaxTokens sets the completion token budget for all Director calls, overriding the
+// per-call defaults (DefaultReasoningMaxTokens for event analysis); maxTokens <= 0 restores them
+func WithDirectorMaxTokens(maxTokens int) DirectorOption {
+	return func(d *Director) {
+		if d.config == nil {
+			d.config = &DirectorConfig{}
+		}
+		d.config.MaxTokens = max(maxTokens, 0)
+	}
+}
+
+// WithDirectorTemperature sets the sampling temperature for all Director calls,
+// clamped to [MinTemperature, MaxTemperature]
+func WithDirectorTemperature(t float64) DirectorOption {
+	return func(d *Director) {
+		if d.config == nil {
+			d.config = &DirectorConfig{}
+		}
+		t = clampTemperature(t)
+		d.config.Temperature = &t
+	}
+}
+
+// WithMetricSchema sets the metrics the event evaluation prompt asks the model to score. The JSON
+// line requested from the model keys each delta by the metric name in snake_case.
+func WithMetricSchema(metrics ...string) DirectorOption {
+	return func(d *Director) {
+		if d.config == nil {
+			d.config = &DirectorConfig{}
+		}
+		d.config.MetricSchema = append([]string(nil), metrics...)
+	}
+}
+
 // WithStrategicFocus sets the director's primary focus
 func WithStrategicFocus(focus string) DirectorOption {
 	return func(d *Director) {
@@ -69,6 +122,24 @@ func WithDifficultyScaling(enabled bool) DirectorOption {
 	}
 }
 
+// WithEventHistoryWindow includes up to n recent turns in the event evaluation prompt.
+// History lines are supplied by the caller via GameEvent.Parameters["history"] ([]string,
+// oldest first); the window is capped at MaxEventHistoryWindow.
+func WithEventHistoryWindow(n int) DirectorOption {
+	return func(d *Director) {
+		if d.config == nil {
+			d.config = &DirectorConfig{}
+		}
+		if n < 0 {
+			n = 0
+		}
+		if n > MaxEventHistoryWindow {
+			n = MaxEventHistoryWindow
+		}
+		d.config.HistoryWindow = n
+	}
+}
+
 // GameEvent represents an event that occurred in the game
 type GameEvent struct {
 	Type        string                 `json:"type"`
@@ -83,13 +154,27 @@ type GameEvent struct {
 // DirectorDecision represents a strategic decision made by the director
 type DirectorDecision struct {
 	Decision    string                 `json:"decision"`
-	Reasoning   string                 `json:"reasoning"`
+	Reasoning   string                 `json:"reasoning"` // Narrative analysis without the trailing JSON
 	Actions     []DirectorAction       `json:"actions"`
 	Confidence  float64                `json:"confidence"`
 	Priority    int                    `json:"priority"`
+	Impacts     map[string]ImpactDelta `json:"impacts,omitempty"`
+	Raw         string                 `json:"raw,omitempty"` // Unparsed model output
 	Metadata    map[string]interface{} `json:"metadata"`
 }
 
+// ImpactDelta is the model's assessed effect on a single metric, either as a
+// categorical level+direction or as a numeric delta (or both)
+type ImpactDelta struct {
+	Level         string  `json:"level,omitempty"`     // "low", "medium", "high", "extreme"
+	Direction     string  `json:"direction,omitempty"` // "+", "-", "0"
+	Delta         float64 `json:"delta,omitempty"`
+	Justification string  `json:"justification,omitempty"`
+}
+
+// ErrNoDecisionJSON is returned by ParseDecision when the output has no complete trailing JSON object
+var ErrNoDecisionJSON = errors.New("no decision JSON found")
+
 // DirectorAction represents an action the director wants to execute
 type DirectorAction struct {
 	Type       string                 `json:"type"`
@@ -98,56 +183,223 @@ type DirectorAction struct {
 	Delay      time.Duration          `json:"delay"`
 }
 
-// ProcessEvent analyzes a game event and makes strategic decisions
-func (d *Director) ProcessEvent(ctx context.Context, event *GameEvent) (*DirectorDecision, error) {
-	// Build context-aware prompt for strategic reasoning
-	prompt := d.buildEventAnalysisPrompt(event)
+// DirectorActionHandler carries out one DirectorAction of the type it is registered for
+type DirectorActionHandler func(ctx context.Context, action DirectorAction) error
 
-	// Get reasoning model (default to DeepSeek R1 for strategic decisions)
-	model := ModelReasoningDefault
-	if d.config != nil && d.config.ReasoningModel != "" {
-		model = d.config.ReasoningModel
+// RegisterActionHandler sets the handler ExecuteActions runs for actions of actionType,
+// replacing any previous handler for that type
+func (d *Director) RegisterActionHandler(actionType string, fn DirectorActionHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.handlers == nil {
+		d.handlers = make(map[string]DirectorActionHandler)
 	}
+	d.handlers[actionType] = fn
+}
 
-	// Generate strategic response using LLM
-	llmReq := &theta_client.LLMRequest{
-		Model:       model,
-		Prompt:      prompt,
-		MaxTokens:   DefaultReasoningMaxTokens,
-		Temperature: 0.6, // Lower temperature for more consistent strategic decisions
+// ExecuteActions runs the decision's actions through their registered handlers, each after its
+// Delay. Actions run concurrently and ExecuteActions blocks until all have finished, so callers
+// not wanting to wait on delayed actions should run it in a goroutine. Action types without a
+// handler are logged and skipped; handler errors and cancellation are returned joined.
+func (d *Director) ExecuteActions(ctx context.Context, decision *DirectorDecision) error {
+	if decision == nil {
+		return nil
 	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(decision.Actions))
+	for i, action := range decision.Actions {
+		d.mu.RLock()
+		handler, ok := d.handlers[action.Type]
+		d.mu.RUnlock()
+		if !ok {
+			d.engine.logger.Warnf("director: no handler registered for action %q, skipping", action.Type)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, action DirectorAction) {
+			defer wg.Done()
+			if action.Delay > 0 {
+				timer := time.NewTimer(action.Delay)
+				defer timer.Stop()
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					errs[i] = fmt.Errorf("action %s cancelled: %w", action.Type, ctx.Err())
+					return
+				}
+			}
+			if err := handler(ctx, action); err != nil {
+				errs[i] = fmt.Errorf("action %s failed: %w", action.Type, err)
+			}
+		}(i, action)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
 
-	llmResp, err := d.engine.thetaClient.GenerateWithLLM(ctx, llmReq)
-	if err != nil {
+// ProcessEvent analyzes a game event and makes strategic decisions. The analysis is requested
+// through GenerateJSON, so a reply without its metrics JSON is retried once before the decision
+// falls back to the raw reasoning.
+func (d *Director) ProcessEvent(ctx context.Context, event *GameEvent) (*DirectorDecision, error) {
+	llmReq := d.eventAnalysisRequest(event)
+	llmReq.ResponseFormat = textResponseFormat
+
+	callCtx, cancel := d.engine.withCallTimeout(ctx, d.engine.timeouts.reasoning)
+	defer cancel()
+	_, text, err := generateJSON[map[string]json.RawMessage](callCtx, d.engine, llmReq)
+	if err != nil && !errors.Is(err, ErrInvalidJSON) {
 		return nil, fmt.Errorf("failed to process event: %w", err)
 	}
 
-	if len(llmResp.Choices) == 0 {
+	if text == "" {
 		return nil, fmt.Errorf("no decision generated")
 	}
 
+	decision := d.decisionFromText(event, text)
+
+	// Store decision for future reference
+	d.storeDecision(event, decision)
+
+	return decision, nil
+}
+
+// ProcessEventStream is ProcessEvent with the analysis narrative streamed token by token. Narrative
+// tokens are sent until the model opens its trailing JSON block; once the stream completes the
+// parsed decision is sent on the second channel. On failure the error is sent instead of a
+// decision. All channels are closed when the stream ends.
+func (d *Director) ProcessEventStream(ctx context.Context, event *GameEvent) (<-chan string, <-chan *DirectorDecision, <-chan error) {
+	out := make(chan string, 32)
+	decisions := make(chan *DirectorDecision, 1)
+	errOut := make(chan error, 1)
+	llmReq := d.eventAnalysisRequest(event)
+	llmReq.Stream = true
+	go func() {
+		defer close(errOut)
+		defer close(decisions)
+		defer close(out)
+		ctx, cancel := d.engine.withCallTimeout(ctx, d.engine.timeouts.reasoning)
+		defer cancel()
+		ch, errCh := d.engine.llm.GenerateWithLLMStream(ctx, llmReq)
+		cancelled := func() {
+			errOut <- ctx.Err()
+			go func() { for range ch {} }() // let the provider goroutine finish
+		}
+		var full strings.Builder
+		sent, inJSON := 0, false
+		for ch != nil {
+			select {
+			case <-ctx.Done():
+				cancelled()
+				return
+			case tok, ok := <-ch:
+				if !ok {
+					ch = nil
+					continue
+				}
+				full.WriteString(tok)
+				if inJSON {
+					continue
+				}
+				text := full.String()
+				end, opened := narrativeEnd(text)
+				inJSON = opened
+				if end <= sent {
+					continue
+				}
+				select {
+				case out <- text[sent:end]:
+					sent = end
+				case <-ctx.Done():
+					cancelled()
+					return
+				}
+			}
+		}
+		if err := <-errCh; err != nil {
+			errOut <- fmt.Errorf("failed to process event: %w", err)
+			return
+		}
+		if strings.TrimSpace(full.String()) == "" {
+			errOut <- fmt.Errorf("no decision generated")
+			return
+		}
+		decision := d.decisionFromText(event, full.String())
+		d.storeDecision(event, decision)
+		decisions <- decision
+	}()
+	return out, decisions, errOut
+}
+
+// narrativeEnd returns how much of a partial completion is safe to stream as narrative, and
+// whether the trailing JSON block (an object or a code fence) has started. Trailing backticks
+// are held back until it is clear whether they open a fence.
+func narrativeEnd(text string) (int, bool) {
+	end := len(text)
+	if i := strings.Index(text, "```"); i >= 0 {
+		end = i
+	}
+	if i := strings.IndexByte(text[:end], '{'); i >= 0 {
+		end = i
+	}
+	if end < len(text) {
+		return end, true
+	}
+	return len(strings.TrimRight(text, "`")), false
+}
+
+// eventAnalysisRequest builds the reasoning-model request for a Director event analysis
+func (d *Director) eventAnalysisRequest(event *GameEvent) *theta_client.LLMRequest {
+	// Get reasoning model (default to DeepSeek R1 for strategic decisions)
+	model := ModelReasoningDefault
+	if cfg := d.settings(); cfg.ReasoningModel != "" {
+		model = cfg.ReasoningModel
+	}
+	return &theta_client.LLMRequest{
+		Model:       model,
+		Prompt:      d.buildEventAnalysisPrompt(event),
+		MaxTokens:   d.maxTokens(DefaultReasoningMaxTokens),
+		Temperature: Float64(d.temperature(0.6)), // Lower temperature for more consistent strategic decisions
+	}
+}
+
+// decisionFromText builds the decision for event from the model's narrative and trailing JSON
+func (d *Director) decisionFromText(event *GameEvent, text string) *DirectorDecision {
+	// Structured parse of the trailing JSON; falls back to the raw text when there is none
+	parsed, parseErr := ParseDecision(text)
+	if parseErr != nil {
+		d.engine.logger.Debugf("director: %v; keeping raw reasoning", parseErr)
+	}
+
 	decision := &DirectorDecision{
 		Decision:   "analyze_and_respond",
-		Reasoning:  llmResp.Choices[0].Text,
+		Reasoning:  parsed.Reasoning,
 		Actions:    d.generateActions(event),
 		Confidence: 0.8,
 		Priority:   d.calculatePriority(event),
+		Impacts:    parsed.Impacts,
+		Raw:        parsed.Raw,
 		Metadata:   map[string]interface{}{
 			"event_type": event.Type,
 			"player_id":  event.PlayerID,
 			"timestamp":  event.Timestamp,
 		},
 	}
-
-	// Store decision for future reference
-	d.storeDecision(event, decision)
-
-	return decision, nil
+	if parsed.Decision != "" {
+		decision.Decision = parsed.Decision
+	}
+	if parsed.Confidence > 0 {
+		decision.Confidence = parsed.Confidence
+	}
+	if parsed.Priority > 0 {
+		decision.Priority = parsed.Priority
+	}
+	return decision
 }
 
 // AnalyzePlayerBehavior analyzes player patterns and suggests adaptations
 func (d *Director) AnalyzePlayerBehavior(ctx context.Context, playerID string, events []GameEvent) (*PlayerAnalysis, error) {
-	if d.config == nil || !d.config.PlayerAnalysis {
+	cfg := d.settings()
+	if !cfg.PlayerAnalysis {
 		return nil, fmt.Errorf("player analysis not enabled")
 	}
 
@@ -155,18 +407,20 @@ func (d *Director) AnalyzePlayerBehavior(ctx context.Context, playerID string, e
 	prompt := d.buildPlayerAnalysisPrompt(playerID, events)
 
 	model := "deepseek_r1"
-	if d.config.ReasoningModel != "" {
-		model = d.config.ReasoningModel
+	if cfg.ReasoningModel != "" {
+		model = cfg.ReasoningModel
 	}
 
 	llmReq := &theta_client.LLMRequest{
 		Model:       model,
 		Prompt:      prompt,
-		MaxTokens:   400,
-		Temperature: 0.7,
+		MaxTokens:   d.maxTokens(400),
+		Temperature: Float64(d.temperature(0.7)),
 	}
 
-	llmResp, err := d.engine.thetaClient.GenerateWithLLM(ctx, llmReq)
+	callCtx, cancel := d.engine.withCallTimeout(ctx, d.engine.timeouts.reasoning)
+	defer cancel()
+	llmResp, err := d.engine.llm.GenerateWithLLM(callCtx, llmReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze player behavior: %w", err)
 	}
@@ -175,19 +429,55 @@ func (d *Director) AnalyzePlayerBehavior(ctx context.Context, playerID string, e
 		return nil, fmt.Errorf("no analysis generated")
 	}
 
-	return &PlayerAnalysis{
+	analysis := &PlayerAnalysis{
 		PlayerID:      playerID,
 		Analysis:      llmResp.Choices[0].Text,
 		PlayStyle:     d.extractPlayStyle(events),
 		Recommendations: d.generateRecommendations(events),
 		Confidence:    0.75,
 		Timestamp:     time.Now(),
-	}, nil
+	}
+	applyStructuredAnalysis(analysis, llmResp.Choices[0].Text)
+	return analysis, nil
+}
+
+// structuredAnalysis is the JSON shape requested by buildPlayerAnalysisPrompt
+type structuredAnalysis struct {
+	Summary         string   `json:"summary"`
+	PlayStyle       string   `json:"play_style"`
+	Strengths       []string `json:"strengths"`
+	Weaknesses      []string `json:"weaknesses"`
+	Recommendations []string `json:"recommendations"`
+	Confidence      float64  `json:"confidence"`
+}
+
+// applyStructuredAnalysis overrides the heuristic fields with the model's JSON analysis when it
+// parses; fields the model leaves empty keep their heuristic values
+func applyStructuredAnalysis(analysis *PlayerAnalysis, text string) {
+	var parsed structuredAnalysis
+	if decodeJSONText(text, &parsed) != nil {
+		return
+	}
+	if s := strings.TrimSpace(parsed.Summary); s != "" {
+		analysis.Analysis = s
+	}
+	if s := strings.TrimSpace(parsed.PlayStyle); s != "" {
+		analysis.PlayStyle = strings.ToLower(s)
+	}
+	analysis.Strengths = parsed.Strengths
+	analysis.Weaknesses = parsed.Weaknesses
+	if len(parsed.Recommendations) > 0 {
+		analysis.Recommendations = parsed.Recommendations
+	}
+	if parsed.Confidence > 0 && parsed.Confidence <= 1 {
+		analysis.Confidence = parsed.Confidence
+	}
 }
 
 // GenerateEvent creates dynamic events based on current game state
 func (d *Director) GenerateEvent(ctx context.Context, context *GameContext) (*GeneratedEvent, error) {
-	if d.config == nil || !d.config.EventGeneration {
+	cfg := d.settings()
+	if !cfg.EventGeneration {
 		return nil, fmt.Errorf("event generation not enabled")
 	}
 
@@ -195,18 +485,20 @@ func (d *Director) GenerateEvent(ctx context.Context, context *GameContext) (*Ge
 	prompt := d.buildEventGenerationPrompt(context)
 
 	model := "deepseek_r1"
-	if d.config.ReasoningModel != "" {
-		model = d.config.ReasoningModel
+	if cfg.ReasoningModel != "" {
+		model = cfg.ReasoningModel
 	}
 
 	llmReq := &theta_client.LLMRequest{
 		Model:       model,
 		Prompt:      prompt,
-		MaxTokens:   250,
-		Temperature: 0.9, // Higher temperature for creative event generation
+		MaxTokens:   d.maxTokens(250),
+		Temperature: Float64(d.temperature(0.9)), // Higher temperature for creative event generation
 	}
 
-	llmResp, err := d.engine.thetaClient.GenerateWithLLM(ctx, llmReq)
+	callCtx, cancel := d.engine.withCallTimeout(ctx, d.engine.timeouts.reasoning)
+	defer cancel()
+	llmResp, err := d.engine.llm.GenerateWithLLM(callCtx, llmReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate event: %w", err)
 	}
@@ -228,7 +520,7 @@ func (d *Director) GenerateEvent(ctx context.Context, context *GameContext) (*Ge
 
 // AdjustDifficulty automatically adjusts game difficulty based on player performance
 func (d *Director) AdjustDifficulty(ctx context.Context, playerStats *PlayerStats) (*DifficultyAdjustment, error) {
-	if d.config == nil || !d.config.DifficultyScaling {
+	if !d.settings().DifficultyScaling {
 		return nil, fmt.Errorf("difficulty scaling not enabled")
 	}
 
@@ -256,10 +548,18 @@ func (d *Director) AdjustDifficulty(ctx context.Context, playerStats *PlayerStat
 		reasoning = "Player performance within target range, no adjustment needed"
 	}
 
+	// Compound on the last persisted difficulty rather than the caller's possibly stale value
+	current := playerStats.CurrentDifficulty
+	if stored, ok := d.GetCurrentDifficulty(playerStats.PlayerID); ok {
+		current = stored
+	}
+	newDifficulty := min(max(current+adjustment, MinDifficulty), MaxDifficulty)
+	d.storeDifficulty(ctx, playerStats.PlayerID, newDifficulty)
+
 	return &DifficultyAdjustment{
 		PlayerID:      playerStats.PlayerID,
-		Adjustment:    adjustment,
-		NewDifficulty: playerStats.CurrentDifficulty + adjustment,
+		Adjustment:    newDifficulty - current,
+		NewDifficulty: newDifficulty,
 		Reasoning:     reasoning,
 		Metrics: map[string]float64{
 			"success_rate":      successRate,
@@ -270,17 +570,95 @@ func (d *Director) AdjustDifficulty(ctx context.Context, playerStats *PlayerStat
 	}, nil
 }
 
-// UpdateGameState updates the director's understanding of the game world
-func (d *Director) UpdateGameState(key string, value interface{}) { d.mu.Lock(); d.gameState[key] = value; d.mu.Unlock() }
+func difficultyStateKey(playerID string) string { return "difficulty:" + playerID }
 
-// GetGameState retrieves current game state information
-func (d *Director) GetGameState(key string) (interface{}, bool) { d.mu.RLock(); v, ok := d.gameState[key]; d.mu.RUnlock(); return v, ok }
+// storeDifficulty records a player's difficulty in the game state and, if enabled, Redis
+func (d *Director) storeDifficulty(ctx context.Context, playerID string, difficulty float64) {
+	d.UpdateGameState(difficultyStateKey(playerID), difficulty)
+	if d.engine.IsRedisEnabled() {
+		key := fmt.Sprintf("director:difficulty:%s", playerID)
+		if err := d.engine.redisClient.Set(ctx, key, difficulty, 30*24*time.Hour); err != nil {
+			d.engine.logger.Warnf("director: failed to persist difficulty for %s: %v", playerID, err)
+		}
+	}
+}
+
+// GetCurrentDifficulty returns the difficulty last set for a player by AdjustDifficulty,
+// falling back to Redis when it is not in the director's game state
+func (d *Director) GetCurrentDifficulty(playerID string) (float64, bool) {
+	if v, ok := d.GetGameState(difficultyStateKey(playerID)); ok {
+		if difficulty, ok := v.(float64); ok {
+			return difficulty, true
+		}
+	}
+	if d.engine.IsRedisEnabled() {
+		var difficulty float64
+		key := fmt.Sprintf("director:difficulty:%s", playerID)
+		if err := d.engine.redisClient.Get(context.Background(), key, &difficulty); err == nil {
+			d.UpdateGameState(difficultyStateKey(playerID), difficulty)
+			return difficulty, true
+		}
+	}
+	return 0, false
+}
+
+// UpdateGameState updates the director's understanding of the game world. Map and slice values
+// are copied, so the caller may keep modifying its own value afterwards.
+func (d *Director) UpdateGameState(key string, value interface{}) {
+	value = copyStateValue(value)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.gameState[key] = value
+}
+
+// GetGameState retrieves current game state information. Map and slice values are returned as
+// copies (see copyStateValue), so changing them does not affect the director's state.
+func (d *Director) GetGameState(key string) (interface{}, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	v, ok := d.gameState[key]
+	return copyStateValue(v), ok
+}
+
+// copyStateValue deep-copies the JSON-shaped maps and slices kept in game state; other values,
+// including pointers and structs, are returned as is
+func copyStateValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		cp := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			cp[k] = copyStateValue(e)
+		}
+		return cp
+	case []interface{}:
+		cp := make([]interface{}, len(t))
+		for i, e := range t {
+			cp[i] = copyStateValue(e)
+		}
+		return cp
+	case map[string]string:
+		return maps.Clone(t)
+	case map[string]int:
+		return maps.Clone(t)
+	case map[string]float64:
+		return maps.Clone(t)
+	case []string:
+		return slices.Clone(t)
+	case []int:
+		return slices.Clone(t)
+	case []float64:
+		return slices.Clone(t)
+	}
+	return v
+}
 
 // PlayerAnalysis contains insights about player behavior
 type PlayerAnalysis struct {
 	PlayerID        string                 `json:"player_id"`
 	Analysis        string                 `json:"analysis"`
 	PlayStyle       string                 `json:"play_style"`
+	Strengths       []string               `json:"strengths,omitempty"`
+	Weaknesses      []string               `json:"weaknesses,omitempty"`
 	Recommendations []string               `json:"recommendations"`
 	Confidence      float64                `json:"confidence"`
 	Timestamp       time.Time              `json:"timestamp"`
@@ -343,23 +721,83 @@ func (d *Director) buildEventAnalysisPrompt(event *GameEvent) string {
 	if reason == nil { reason = "(no player reasoning provided)" }
 	if cat == nil { cat = "general" }
 	if sev == nil { sev = 5 }
+	history := ""
+	if window := d.settings().HistoryWindow; window > 0 && event.Parameters != nil {
+		history = renderHistoryWindow(event.Parameters["history"], window)
+	}
 
-	metricsList := "Public Opinion:\n\nEconomy:\n\nNational Security:\n\nGeopolitical Standing:\n\nTech Sector Confidence:\n\nCivil Liberties:"
+	metricsList, exampleImpact, jsonInstructions := d.metricSchemaSections()
 	prompt := fmt.Sprintf(
 		"Event Evaluation Prompt\nYou are an expert political and economic analyst AI. Your task is to evaluate a player's action in response to a specific event within a presidential simulator game.\n\n"+
 		"Analyze the provided Event Description and the player's Chosen Action. Based on this analysis, determine the numerical impact on the given Game Metrics. For each metric change, you must provide a brief, clear justification.\n\n"+
 		"1. Event Description\n%s (%v, severity %v/10)\n\n"+
 		"2. Player's Chosen Action\n%s\n\n"+
+		"%s"+
 		"3. Game Metrics\n%s\n\n"+
 		"4. Evaluation Task\nInstructions:\n- Step 1: Analyze the Action's Logic and Consequences. Briefly summarize immediate and long-term consequences.\n- Step 2: Determine Metric Changes and Provide Justification. For each game metric, provide a numerical change (e.g., +15, -20, 0) and a one-sentence justification.\n\n"+
 		"Example Output Structure:\nAction Analysis: <2-4 sentences>\n\n"+
-		"Metric Impact:\nPublic Opinion: +10. Justification: <why>.\nEconomy: -5. Justification: <why>.\nNational Security: +20. Justification: <why>.\nGeopolitical Standing: +5. Justification: <why>.\nTech Sector Confidence: -15. Justification: <why>.\nCivil Liberties: -10. Justification: <why>.\n\n"+
-		"CRUCIAL: After your analysis and metric impact lines, output exactly ONE final line containing ONLY a JSON object with integer deltas for: {\"metrics\":{\"economy\":E,\"security\":S,\"diplomacy\":D,\"environment\":Env,\"approval\":A,\"stability\":St}}. Map as follows: Public Opinion->approval, Economy->economy, National Security->security, Geopolitical Standing->diplomacy, Tech Sector Confidence->stability, Civil Liberties->approval (also subtract half into stability if negative). Use range -20..20. If the event is environmental/climate, set environment accordingly; otherwise environment may be 0. Do NOT include any text or markdown after the JSON.",
-		evtDesc, cat, sev, reason, metricsList,
+		"Metric Impact:\n%s\n\n"+
+		"%s",
+		evtDesc, cat, sev, reason, history, metricsList, exampleImpact, jsonInstructions,
 	)
 	return prompt
 }
 
+// metricSchemaSections renders the metric list, example impact lines and final JSON instructions
+// for the configured schema. The default schema keeps the presidential simulator's metric mapping.
+func (d *Director) metricSchemaSections() (string, string, string) {
+	schema := d.settings().MetricSchema
+	if len(schema) == 0 {
+		return "Public Opinion:\n\nEconomy:\n\nNational Security:\n\nGeopolitical Standing:\n\nTech Sector Confidence:\n\nCivil Liberties:",
+			"Public Opinion: +10. Justification: <why>.\nEconomy: -5. Justification: <why>.\nNational Security: +20. Justification: <why>.\nGeopolitical Standing: +5. Justification: <why>.\nTech Sector Confidence: -15. Justification: <why>.\nCivil Liberties: -10. Justification: <why>.",
+			"CRUCIAL: After your analysis and metric impact lines, output exactly ONE final line containing ONLY a JSON object with integer deltas for: {\"metrics\":{\"economy\":E,\"security\":S,\"diplomacy\":D,\"environment\":Env,\"approval\":A,\"stability\":St}}. Map as follows: Public Opinion->approval, Economy->economy, National Security->security, Geopolitical Standing->diplomacy, Tech Sector Confidence->stability, Civil Liberties->approval (also subtract half into stability if negative). Use range -20..20. If the event is environmental/climate, set environment accordingly; otherwise environment may be 0. Do NOT include any text or markdown after the JSON."
+	}
+	names := make([]string, len(schema))
+	examples := make([]string, len(schema))
+	keys := make([]string, len(schema))
+	exampleDeltas := []string{"+10", "-5", "+20", "+5", "-15", "-10"}
+	for i, m := range schema {
+		names[i] = m + ":"
+		examples[i] = fmt.Sprintf("%s: %s. Justification: <why>.", m, exampleDeltas[i%len(exampleDeltas)])
+		keys[i] = fmt.Sprintf("%q:N", metricKey(m))
+	}
+	return strings.Join(names, "\n\n"), strings.Join(examples, "\n"),
+		fmt.Sprintf("CRUCIAL: After your analysis and metric impact lines, output exactly ONE final line containing ONLY a JSON object with an integer delta N for each metric: {\"metrics\":{%s}}. Use range -20..20. Do NOT include any text or markdown after the JSON.", strings.Join(keys, ","))
+}
+
+// metricKey converts a metric name to its snake_case JSON key, e.g. "Player Morale" -> "player_morale"
+func metricKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "_")
+}
+
+// renderHistoryWindow formats the last n history lines (oldest first) as a prompt section
+func renderHistoryWindow(raw interface{}, n int) string {
+	var lines []string
+	switch v := raw.(type) {
+	case []string:
+		lines = v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				lines = append(lines, s)
+			}
+		}
+	}
+	if len(lines) == 0 || n <= 0 {
+		return ""
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	var b strings.Builder
+	b.WriteString("Recent Turns (oldest first)\n")
+	for _, ln := range lines {
+		fmt.Fprintf(&b, "- %s\n", textutil.Snippet(strings.TrimSpace(ln), 240))
+	}
+	b.WriteString("Judge the action for consistency with these earlier decisions and note any escalation of crises that were previously ignored.\n\n")
+	return b.String()
+}
+
 func (d *Director) buildPlayerAnalysisPrompt(playerID string, events []GameEvent) string {
 	prompt := fmt.Sprintf("Analyze player %s's behavior based on recent actions:\n", playerID)
 
@@ -370,7 +808,8 @@ func (d *Director) buildPlayerAnalysisPrompt(playerID string, events []GameEvent
 		prompt += fmt.Sprintf("- %s: %s at %s\n", event.Type, event.Action, event.Location)
 	}
 
-	prompt += "Identify the player's style, preferences, and suggest how to improve their experience:"
+	prompt += "Identify the player's style, preferences, and suggest how to improve their experience. "
+	prompt += `Respond with JSON only: {"summary": "<two sentences>", "play_style": "<one word, e.g. explorer, fighter, socializer>", "strengths": ["..."], "weaknesses": ["..."], "recommendations": ["..."], "confidence": <0-1>}`
 
 	return prompt
 }
@@ -440,6 +879,35 @@ func (d *Director) calculatePriority(event *GameEvent) int {
 	}
 }
 
+// settings returns a copy of the director's configuration taken under its lock (the zero config
+// when none is set); MetricSchema is copied too
+func (d *Director) settings() DirectorConfig {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.config == nil {
+		return DirectorConfig{}
+	}
+	cfg := *d.config
+	cfg.MetricSchema = slices.Clone(cfg.MetricSchema)
+	return cfg
+}
+
+// temperature returns the configured temperature, or def when none is set
+func (d *Director) temperature(def float64) float64 {
+	if t := d.settings().Temperature; t != nil {
+		return *t
+	}
+	return def
+}
+
+// maxTokens returns the configured completion token budget, or def when none is set
+func (d *Director) maxTokens(def int) int {
+	if n := d.settings().MaxTokens; n > 0 {
+		return n
+	}
+	return def
+}
+
 func (d *Director) storeDecision(event *GameEvent, decision *DirectorDecision) {
 	if d.engine.IsRedisEnabled() {
 		key := fmt.Sprintf("director:decisions:%s:%d", event.PlayerID, event.Timestamp.Unix())
@@ -447,6 +915,49 @@ func (d *Director) storeDecision(event *GameEvent, decision *DirectorDecision) {
 	}
 }
 
+// GetDecisionHistory returns up to limit of the player's stored decisions, most recent first
+// (limit <= 0 returns all). Without Redis it returns an empty slice.
+func (d *Director) GetDecisionHistory(ctx context.Context, playerID string, limit int) ([]*DirectorDecision, error) {
+	if !d.engine.IsRedisEnabled() {
+		return []*DirectorDecision{}, nil
+	}
+	prefix := fmt.Sprintf("director:decisions:%s:", playerID)
+	keys, err := d.engine.redisClient.ScanPrefix(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list decisions: %w", err)
+	}
+
+	type stamped struct {
+		key string
+		ts  int64
+	}
+	entries := make([]stamped, 0, len(keys))
+	for _, key := range keys {
+		ts, err := strconv.ParseInt(strings.TrimPrefix(key, prefix), 10, 64)
+		if err != nil {
+			continue // another player's ID that shares this prefix
+		}
+		entries = append(entries, stamped{key: key, ts: ts})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ts > entries[j].ts })
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	decisions := make([]*DirectorDecision, 0, len(entries))
+	for _, entry := range entries {
+		var decision DirectorDecision
+		if err := d.engine.redisClient.Get(ctx, entry.key, &decision); err != nil {
+			if redis_client.IsNotFound(err) {
+				continue // expired between SCAN and GET
+			}
+			return nil, fmt.Errorf("failed to load decision %s: %w", entry.key, err)
+		}
+		decisions = append(decisions, &decision)
+	}
+	return decisions, nil
+}
+
 func (d *Director) extractPlayStyle(events []GameEvent) string {
 	// Simple heuristic - could be enhanced with more sophisticated analysis
 	actionCounts := make(map[string]int)
@@ -490,9 +1001,56 @@ func (d *Director) generateRecommendations(events []GameEvent) []string {
 	return recommendations
 }
 
-func snippet(s string, n int) string {
-	if len(s) <= n {
-		return s
+// ParseDecision splits raw model output into a narrative and its trailing JSON object, reading
+// decision, confidence, priority and per-metric impacts ("impacts"/"impact" objects or numeric "metrics").
+// When no complete JSON object is found it returns ErrNoDecisionJSON alongside a decision whose
+// Reasoning holds the raw text (minus any truncated trailing object).
+func ParseDecision(raw string) (*DirectorDecision, error) {
+	decision := &DirectorDecision{Raw: raw, Reasoning: strings.TrimSpace(raw)}
+	start, end, openAt := jsonextract.LastObject(raw)
+	if start < 0 {
+		if openAt >= 0 {
+			decision.Reasoning = jsonextract.TrimFence(raw[:openAt])
+		}
+		return decision, ErrNoDecisionJSON
+	}
+
+	var payload struct {
+		Decision   string                     `json:"decision"`
+		Confidence float64                    `json:"confidence"`
+		Priority   int                        `json:"priority"`
+		Impacts    map[string]json.RawMessage `json:"impacts"`
+		Impact     map[string]json.RawMessage `json:"impact"`
+		Metrics    map[string]float64         `json:"metrics"`
 	}
-	return s[:n] + "..."
-}
\ No newline at end of file
+	if err := unmarshalRepaired(raw[start:end+1], &payload); err != nil {
+		return decision, fmt.Errorf("failed to parse decision JSON: %w", err)
+	}
+
+	impacts := make(map[string]ImpactDelta)
+	for k, v := range payload.Metrics {
+		impacts[strings.ToLower(strings.TrimSpace(k))] = ImpactDelta{Delta: v}
+	}
+	if payload.Impacts == nil {
+		payload.Impacts = payload.Impact
+	}
+	for k, v := range payload.Impacts {
+		var delta ImpactDelta
+		if err := json.Unmarshal(v, &delta); err != nil {
+			if json.Unmarshal(v, &delta.Delta) != nil {
+				continue
+			}
+		}
+		delta.Level = strings.ToLower(delta.Level)
+		impacts[strings.ToLower(strings.TrimSpace(k))] = delta
+	}
+
+	decision.Decision = payload.Decision
+	decision.Confidence = payload.Confidence
+	decision.Priority = payload.Priority
+	if len(impacts) > 0 {
+		decision.Impacts = impacts
+	}
+	decision.Reasoning = strings.TrimSpace(jsonextract.TrimFence(raw[:start]) + " " + jsonextract.TrimFence(raw[end+1:]))
+	return decision, nil
+}
@@ -0,0 +1,56 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/emergent-world-engine/backend/internal/redis_client"
+)
+
+// BusEvent is an event carried over the engine's Redis event bus
+type BusEvent = redis_client.GameEvent
+
+// Event bus channels
+const (
+	EventChannelGame     = redis_client.ChannelGameEvents
+	EventChannelDirector = redis_client.ChannelDirector
+)
+
+// PublishEvent publishes event to EventChannelGame, stamping the current time if Timestamp is unset
+func (e *Engine) PublishEvent(ctx context.Context, event BusEvent) error {
+	if !e.IsRedisEnabled() {
+		return fmt.Errorf("failed to publish event: %w", ErrRedisNotEnabled)
+	}
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().Unix()
+	}
+	if err := e.redisClient.PublishGameEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// SubscribeEvents subscribes to the given channels (EventChannelGame if none) and decodes each
+// message into a BusEvent. Messages that are not valid events are logged and skipped. The
+// returned channel closes when ctx is cancelled or the subscription ends.
+func (e *Engine) SubscribeEvents(ctx context.Context, channels ...string) (<-chan BusEvent, error) {
+	if !e.IsRedisEnabled() {
+		return nil, fmt.Errorf("failed to subscribe to events: %w", ErrRedisNotEnabled)
+	}
+	if len(channels) == 0 {
+		channels = []string{EventChannelGame}
+	}
+	msgs, err := e.redisClient.Subscribe(ctx, channels...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+	out := make(chan BusEvent, 16)
+	go func() {
+		defer close(out)
+		redis_client.ForwardGameEvents(ctx, msgs, out, func(err error) {
+			e.logger.Warnf("event bus: dropping malformed message: %v", err)
+		})
+	}()
+	return out, nil
+}
@@ -3,8 +3,13 @@ package framework
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +25,9 @@ type Engine struct {
 	config      *Config
 	mu          sync.RWMutex
 	logger      Logger
+	providers   []LLMProvider
+	llm         LLMProvider
+	timeouts    callTimeouts
 }
 
 // Config holds framework configuration
@@ -30,40 +38,243 @@ type Config struct {
 	RedisPassword  string
 	EnableRedis    bool // Optional Redis for advanced features
 	EnableLogging  bool
+	LogLevel       string // minimum level logged when EnableLogging is set: "debug" (default), "info", "warn" or "error"
+	LogFormat      string // LogFormatText (default) or LogFormatJSON for one JSON object per line
+
+	// Per-call timeouts; zero uses the Default*Timeout constants
+	DialogueTimeout  time.Duration // NPC dialogue and emotion detection
+	ReasoningTimeout time.Duration // Director decisions, analysis and event generation
+	ImageTimeout     time.Duration // images, textures and concept art
+	VideoTimeout     time.Duration // video and 3D model generation
+	RedisOpTimeout   time.Duration // Redis operations called without a deadline; 0 uses redis_client.DefaultOpTimeout, negative disables
+
+	// Theta client retry and rate limiting; zero uses DefaultRetryAttempts, DefaultRetryBackoffMs and DefaultRateLimitRPS
+	RetryAttempts int           // attempts per request, including the first
+	RetryBackoff  time.Duration // base wait between attempts, growing linearly
+	RateLimitRPS  int           // requests per second across all Theta calls
+
+	// ModelCosts prices each model's tokens for EngineMetrics.TokenUsage cost estimates
+	ModelCosts map[string]ModelCost
+
+	// ModelEndpoints overrides the hosted chat-completion URL of individual models on top of
+	// DefaultModelEndpoints; an empty URL sends the model through the generic LLM endpoint
+	ModelEndpoints map[string]string
+
+	// Providers are the LLM providers tried, in order, until one succeeds. The built-in Theta
+	// client is tried first unless the list places ThetaProvider elsewhere or ExcludeTheta is set.
+	Providers    []LLMProvider
+	ExcludeTheta bool // leave the Theta client out of the provider chain; Providers must then be non-empty
+}
+
+// ModelCost is the price of a model's tokens in US dollars per million tokens
+type ModelCost = theta_client.ModelCost
+
+// TokenUsage is the LLM token usage accumulated by an engine, with its estimated cost
+type TokenUsage = theta_client.TokenUsage
+
+// callTimeouts are the resolved per-modality timeouts
+type callTimeouts struct {
+	dialogue, reasoning, image, video time.Duration
 }
 
 // NewEngine creates a new Emergent World Engine instance
-func NewEngine(config *Config) (*Engine, error) {
+func NewEngine(config *Config, opts ...EngineOption) (*Engine, error) {
 	if config.ThetaAPIKey == "" {
 		return nil, fmt.Errorf("theta API key is required")
 	}
+	logLevel, err := ParseLogLevel(config.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+	logger, err := newLogger(config.EnableLogging, logLevel, config.LogFormat)
+	if err != nil {
+		return nil, err
+	}
 
 	thetaEndpoint := config.ThetaEndpoint
 	if thetaEndpoint == "" {
 		thetaEndpoint = "https://api.thetaedgecloud.com"
 	}
 	thetaClient := theta_client.NewThetaClient(thetaEndpoint, config.ThetaAPIKey)
+	// The client keeps its base timeout for calls without a deadline; withCallTimeout deadlines
+	// replace it for the per-modality calls, including long image and video generation
+	timeouts := resolveTimeouts(config)
+	thetaClient.SetModelCosts(config.ModelCosts)
+	thetaClient.SetModelEndpoints(config.ModelEndpoints)
+	applyRetryConfig(thetaClient, config)
 
 	// optional tuning via env-ish config fields (if extended)
 	// Redis init
 	var redisClient *redis_client.RedisClient
 	if config.EnableRedis && config.RedisURL != "" {
-		addr := config.RedisURL
-		if strings.HasPrefix(addr, "redis://") {
-			if u, err := url.Parse(addr); err == nil {
-				addr = u.Host
-			}
+		redisConfig, err := parseRedisURL(config.RedisURL, config.RedisPassword)
+		if err != nil {
+			return nil, err
 		}
-		redisConfig := &redis_client.Config{Addr: addr, Password: config.RedisPassword, DB: 0, PoolSize: 10}
+		redisConfig.OpTimeout = config.RedisOpTimeout
 		redisClient = redis_client.NewRedisClient(redisConfig)
 	}
 
-	eng := &Engine{thetaClient: thetaClient, redisClient: redisClient, config: config, logger: newLogger(config.EnableLogging)}
+	eng := &Engine{thetaClient: thetaClient, redisClient: redisClient, config: config, logger: logger, timeouts: timeouts, providers: config.Providers}
+	for _, opt := range opts {
+		opt(eng)
+	}
+	if eng.llm, err = eng.buildProviderChain(); err != nil {
+		return nil, err
+	}
 	eng.logger.Infof("Engine initialized (redis=%v)", eng.IsRedisEnabled())
 
 	return eng, nil
 }
 
+// NewEngineFromEnv creates an engine configured by ConfigFromEnv
+func NewEngineFromEnv(opts ...EngineOption) (*Engine, error) {
+	return NewEngine(ConfigFromEnv(), opts...)
+}
+
+// ConfigFromEnv builds a Config from the environment: THETA_API_KEY (or THETA_KEY),
+// THETA_BASE_URL, REDIS_URL, REDIS_PASSWORD, ENABLE_REDIS, ENABLE_LOGGING, LOG_LEVEL and LOG_FORMAT. Redis is
+// enabled whenever REDIS_URL is set unless ENABLE_REDIS says otherwise.
+func ConfigFromEnv() *Config {
+	redisURL := getenv("REDIS_URL")
+	return &Config{
+		ThetaAPIKey:   getenvFirst("THETA_API_KEY", "THETA_KEY"),
+		ThetaEndpoint: getenv("THETA_BASE_URL"),
+		RedisURL:      redisURL,
+		RedisPassword: getenv("REDIS_PASSWORD"),
+		EnableRedis:   getenvBool("ENABLE_REDIS", redisURL != ""),
+		EnableLogging: getenvBool("ENABLE_LOGGING", false),
+		LogLevel:      getenv("LOG_LEVEL"),
+		LogFormat:     getenv("LOG_FORMAT"),
+
+		ModelEndpoints: ParseModelEndpoints(getenv("THETA_MODEL_ENDPOINTS")),
+	}
+}
+
+// DefaultModelEndpoints returns the built-in model -> hosted chat endpoint table
+func DefaultModelEndpoints() map[string]string { return theta_client.DefaultModelEndpoints() }
+
+// ParseModelEndpoints reads "deepseek_r1=https://host/completions,llama_3_1_70b=" style model
+// endpoint overrides for Config.ModelEndpoints; entries without a model name are ignored
+func ParseModelEndpoints(v string) map[string]string {
+	if strings.TrimSpace(v) == "" {
+		return nil
+	}
+	endpoints := map[string]string{}
+	for _, part := range strings.Split(v, ",") {
+		model, url, ok := strings.Cut(part, "=")
+		if model = strings.TrimSpace(model); !ok || model == "" {
+			continue
+		}
+		endpoints[model] = strings.TrimSpace(url)
+	}
+	return endpoints
+}
+
+// getenvFirst returns the first non-empty variable among keys
+func getenvFirst(keys ...string) string {
+	for _, k := range keys {
+		if v := getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func getenv(key string) string {
+	return strings.TrimSpace(os.Getenv(key))
+}
+
+// getenvBool parses a boolean variable ("1", "true", "yes", "on" and their negations), falling back to def
+func getenvBool(key string, def bool) bool {
+	switch strings.ToLower(getenv(key)) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	}
+	return def
+}
+
+// applyRetryConfig sets the client's retry and rate limits from config, defaulting unset fields
+func applyRetryConfig(c *theta_client.ThetaClient, config *Config) {
+	attempts := config.RetryAttempts
+	if attempts <= 0 {
+		attempts = DefaultRetryAttempts
+	}
+	backoff := config.RetryBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoffMs * time.Millisecond
+	}
+	c.SetRetry(attempts, backoff)
+	rps := config.RateLimitRPS
+	if rps <= 0 {
+		rps = DefaultRateLimitRPS
+	}
+	if rps != c.RateLimit() {
+		c.SetRateLimit(rps)
+	}
+}
+
+func resolveTimeouts(config *Config) callTimeouts {
+	orDefault := func(d, def time.Duration) time.Duration {
+		if d > 0 {
+			return d
+		}
+		return def
+	}
+	return callTimeouts{
+		dialogue:  orDefault(config.DialogueTimeout, DefaultDialogueTimeout),
+		reasoning: orDefault(config.ReasoningTimeout, DefaultReasoningTimeout),
+		image:     orDefault(config.ImageTimeout, DefaultImageTimeout),
+		video:     orDefault(config.VideoTimeout, DefaultVideoTimeout),
+	}
+}
+
+// withCallTimeout derives the context for a single model call; an earlier parent deadline still wins
+func (e *Engine) withCallTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, timeout)
+}
+
+// parseRedisURL builds the Redis client config from a redis:// or rediss:// (TLS) URL,
+// taking credentials from the userinfo and the DB index from the path. A bare host:port
+// is accepted as-is. A non-empty password overrides the one in the URL.
+func parseRedisURL(rawURL, password string) (*redis_client.Config, error) {
+	cfg := &redis_client.Config{Addr: rawURL, Password: password, PoolSize: 10}
+	if !strings.Contains(rawURL, "://") {
+		return cfg, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		cfg.TLSConfig = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	default:
+		return nil, fmt.Errorf("invalid redis URL: unsupported scheme %q", u.Scheme)
+	}
+	cfg.Addr = u.Host
+	if u.Port() == "" {
+		cfg.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		cfg.Username = u.User.Username()
+		if p, ok := u.User.Password(); ok && password == "" {
+			cfg.Password = p
+		}
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		n, err := strconv.Atoi(db)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid redis URL: bad DB index %q", db)
+		}
+		cfg.DB = n
+	}
+	return cfg, nil
+}
+
 // NewNPC creates a new NPC instance
 func (e *Engine) NewNPC(id string, opts ...NPCOption) *NPC {
 	npc := &NPC{
@@ -82,6 +293,16 @@ func (e *Engine) NewNPC(id string, opts ...NPCOption) *NPC {
 	return npc
 }
 
+// RestoreNPC creates an NPC and loads its last saved snapshot from Redis.
+// If no snapshot exists the NPC starts fresh; options are applied either way.
+func (e *Engine) RestoreNPC(id string, opts ...NPCOption) (*NPC, error) {
+	npc := e.NewNPC(id, opts...)
+	if err := npc.LoadSnapshot(context.Background()); err != nil && !errors.Is(err, ErrNoSnapshot) {
+		return nil, err
+	}
+	return npc, nil
+}
+
 // NewDirector creates a new Game Director instance
 func (e *Engine) NewDirector(opts ...DirectorOption) *Director {
 	director := &Director{
@@ -105,6 +326,7 @@ func (e *Engine) NewNarrative(opts ...NarrativeOption) *Narrative {
 		storyState:  make(map[string]interface{}),
 		lore:        make(map[string]interface{}),
 		activeQuests: make(map[string]*Quest),
+		now:          time.Now,
 	}
 
 	// Apply options
@@ -112,6 +334,10 @@ func (e *Engine) NewNarrative(opts ...NarrativeOption) *Narrative {
 		opt(narrative)
 	}
 
+	if c := narrative.config; c != nil && c.ExpiryInterval > 0 {
+		narrative.startExpirySweeper(c.ExpiryInterval)
+	}
+
 	return narrative
 }
 
@@ -127,6 +353,16 @@ func (e *Engine) NewAssetGenerator(opts ...AssetOption) *AssetGenerator {
 		opt(generator)
 	}
 
+	limit := DefaultAssetConcurrency
+	if generator.config != nil && generator.config.MaxConcurrent > 0 {
+		limit = generator.config.MaxConcurrent
+	}
+	generator.sem = make(chan struct{}, limit)
+
+	if c := generator.config; c != nil && c.CacheEnabled && c.SweepInterval > 0 {
+		generator.startSweeper(c.SweepInterval)
+	}
+
 	return generator
 }
 
@@ -150,16 +386,29 @@ func (e *Engine) ThetaClient() *theta_client.ThetaClient {
 	return e.thetaClient
 }
 
+// Logger returns the engine's logger (a no-op logger when logging is disabled)
+func (e *Engine) Logger() Logger {
+	return e.logger
+}
+
 // Redis returns the underlying Redis client (may be nil if disabled)
 func (e *Engine) Redis() *redis_client.RedisClient {
 	return e.redisClient
 }
 
+// IsRedisNotFound reports whether err from the Redis client means the requested key does not exist
+func IsRedisNotFound(err error) bool {
+	return redis_client.IsNotFound(err)
+}
+
 // Health checks the health of connected services
 func (e *Engine) Health(ctx context.Context) error {
-	// Lightweight check: just ensure base URL reachable via small timeout future TODO
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
+	// Verify Theta is reachable and the API key is accepted
+	if err := e.thetaClient.Ping(ctx); err != nil {
+		return fmt.Errorf("theta health check failed: %w", err)
+	}
 	// Ping Redis if enabled
 	if e.redisClient != nil {
 		if err := e.redisClient.Ping(ctx); err != nil {
@@ -175,6 +424,12 @@ type EngineMetrics struct {
 	LLMFailures   int64
 	StreamRequests int64
 	StreamTokens   int64
+	ImageRequests   int64
+	VideoRequests   int64
+	TTSRequests     int64
+	VisionRequests  int64
+	Model3DRequests int64
+	TokenUsage      TokenUsage
 }
 
 func (e *Engine) Metrics() *EngineMetrics {
@@ -182,5 +437,7 @@ func (e *Engine) Metrics() *EngineMetrics {
 	if c == nil {
 		return &EngineMetrics{}
 	}
-	return &EngineMetrics{LLMRequests: c.Metrics().LLMRequests, LLMFailures: c.Metrics().LLMFailures, StreamRequests: c.Metrics().LLMStreamRequests, StreamTokens: c.Metrics().LLMStreamTokens}
+	m := c.Metrics()
+	return &EngineMetrics{LLMRequests: m.LLMRequests, LLMFailures: m.LLMFailures, StreamRequests: m.LLMStreamRequests, StreamTokens: m.LLMStreamTokens,
+		ImageRequests: m.ImageRequests, VideoRequests: m.VideoRequests, TTSRequests: m.TTSRequests, VisionRequests: m.VisionRequests, Model3DRequests: m.Model3DRequests, TokenUsage: m.TokenUsage}
 }
\ No newline at end of file
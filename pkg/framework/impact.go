@@ -0,0 +1,81 @@
+package framework
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/emergent-world-engine/backend/pkg/jsonextract"
+)
+
+// ImpactDecision is the model's categorical assessment of a decision's effect on one metric
+type ImpactDecision struct {
+	Level         string `json:"level"`     // "low", "medium", "high", "extreme"
+	Direction     string `json:"direction"` // "+", "-", or "0"
+	Justification string `json:"justification,omitempty"`
+}
+
+// impactsKeys are the keys holding the impacts map, in order of preference
+var impactsKeys = []string{"impacts", "impact"}
+
+// ImpactLevels maps lowercased metric names to their impact decisions
+type ImpactLevels map[string]ImpactDecision
+
+// ParseImpactLevels extracts an `impacts` (or `impact`) map of {level, direction, justification?}
+// entries from model output. The JSON may be wrapped in prose or code fences, nested inside a
+// larger object, or cut off after the impacts map; entries missing a level or direction are
+// skipped. Metric keys are trimmed and lowercased; ok is false when no usable entry is found.
+func ParseImpactLevels(text string) (ImpactLevels, bool) {
+	text = strings.Trim(strings.TrimSpace(text), "`")
+
+	// The object enclosing the last impacts key, then the last complete object mentioning one
+	if frag, ok := jsonextract.EnclosingObject(text, impactsKeys...); ok {
+		if levels, ok := impactLevelsFromObject(frag); ok {
+			return levels, true
+		}
+	}
+	if frag, ok := jsonextract.LastObjectWithKey(text, impactsKeys...); ok {
+		if levels, ok := impactLevelsFromObject(frag); ok {
+			return levels, true
+		}
+	}
+	// Truncated output: the surrounding object never closed, but the impacts map itself may have
+	if frag, ok := jsonextract.ObjectValue(text, impactsKeys...); ok {
+		var m map[string]json.RawMessage
+		if json.Unmarshal([]byte(frag), &m) == nil {
+			return impactLevelsFromMap(m)
+		}
+	}
+	return nil, false
+}
+
+func impactLevelsFromObject(frag string) (ImpactLevels, bool) {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal([]byte(frag), &obj) != nil {
+		return nil, false
+	}
+	raw, ok := obj["impacts"]
+	if !ok {
+		raw, ok = obj["impact"]
+	}
+	if !ok {
+		return nil, false
+	}
+	var m map[string]json.RawMessage
+	if json.Unmarshal(raw, &m) != nil {
+		return nil, false
+	}
+	return impactLevelsFromMap(m)
+}
+
+func impactLevelsFromMap(m map[string]json.RawMessage) (ImpactLevels, bool) {
+	levels := ImpactLevels{}
+	for k, v := range m {
+		var entry ImpactDecision
+		if json.Unmarshal(v, &entry) != nil || entry.Level == "" || entry.Direction == "" {
+			continue
+		}
+		entry.Level = strings.ToLower(entry.Level)
+		levels[strings.ToLower(strings.TrimSpace(k))] = entry
+	}
+	return levels, len(levels) > 0
+}
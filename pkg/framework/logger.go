@@ -1,6 +1,16 @@
 package framework
 
-import "log"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // Logger defines logging interface
 type Logger interface {
@@ -10,20 +20,147 @@ type Logger interface {
 	Errorf(format string, args ...interface{})
 }
 
-// stdLogger basic implementation using log package
-type stdLogger struct{}
+// LogLevel is the severity of a log message; a LeveledLogger drops messages below its minimum
+type LogLevel int32
 
-func (l *stdLogger) Debugf(f string, a ...interface{}) { log.Printf("DEBUG "+f, a...) }
-func (l *stdLogger) Infof(f string, a ...interface{})  { log.Printf("INFO  "+f, a...) }
-func (l *stdLogger) Warnf(f string, a ...interface{})  { log.Printf("WARN  "+f, a...) }
-func (l *stdLogger) Errorf(f string, a ...interface{}) { log.Printf("ERROR "+f, a...) }
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
 
-func newLogger(enabled bool) Logger {
-	if !enabled { return &noopLogger{} }
-	return &stdLogger{}
+// String returns the level's name as accepted by ParseLogLevel
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	}
+	return fmt.Sprintf("LogLevel(%d)", int32(l))
+}
+
+// ParseLogLevel parses "debug", "info", "warn" (or "warning") and "error", case-insensitively.
+// An empty string is LogLevelDebug, so every message is logged.
+func ParseLogLevel(s string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "debug":
+		return LogLevelDebug, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "warn", "warning":
+		return LogLevelWarn, nil
+	case "error":
+		return LogLevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q", s)
+}
+
+// Log output formats accepted by Config.LogFormat
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// levelFilter holds a logger's minimum level, which can change while the logger is in use
+type levelFilter struct {
+	min atomic.Int32
+}
+
+// SetLevel changes the minimum level; it is safe to call while the logger is in use
+func (f *levelFilter) SetLevel(level LogLevel) { f.min.Store(int32(level)) }
+
+// Level returns the current minimum level
+func (f *levelFilter) Level() LogLevel { return LogLevel(f.min.Load()) }
+
+// LeveledLogger writes messages at or above a minimum level through the standard log package,
+// prefixed with their level. The minimum can be changed at runtime with SetLevel.
+type LeveledLogger struct {
+	levelFilter
+	out *log.Logger
+}
+
+// NewLeveledLogger creates a logger that suppresses messages below minLevel
+func NewLeveledLogger(minLevel LogLevel) *LeveledLogger {
+	l := &LeveledLogger{out: log.Default()}
+	l.SetLevel(minLevel)
+	return l
+}
+
+func (l *LeveledLogger) Debugf(f string, a ...interface{}) { l.logf(LogLevelDebug, f, a...) }
+func (l *LeveledLogger) Infof(f string, a ...interface{})  { l.logf(LogLevelInfo, f, a...) }
+func (l *LeveledLogger) Warnf(f string, a ...interface{})  { l.logf(LogLevelWarn, f, a...) }
+func (l *LeveledLogger) Errorf(f string, a ...interface{}) { l.logf(LogLevelError, f, a...) }
+
+func (l *LeveledLogger) logf(level LogLevel, f string, a ...interface{}) {
+	if level < l.Level() {
+		return
+	}
+	l.out.Printf("%-5s %s", strings.ToUpper(level.String()), fmt.Sprintf(f, a...))
+}
+
+// JSONLogger writes each message as one JSON object per line with "time" (RFC 3339), "level" and
+// "msg" fields, for log aggregators. It logs every level until SetLevel raises the minimum.
+type JSONLogger struct {
+	levelFilter
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// NewJSONLogger creates a JSON lines logger writing to w
+func NewJSONLogger(w io.Writer) *JSONLogger {
+	return &JSONLogger{w: w, now: time.Now}
+}
+
+func (l *JSONLogger) Debugf(f string, a ...interface{}) { l.logf(LogLevelDebug, f, a...) }
+func (l *JSONLogger) Infof(f string, a ...interface{})  { l.logf(LogLevelInfo, f, a...) }
+func (l *JSONLogger) Warnf(f string, a ...interface{})  { l.logf(LogLevelWarn, f, a...) }
+func (l *JSONLogger) Errorf(f string, a ...interface{}) { l.logf(LogLevelError, f, a...) }
+
+type jsonLogEntry struct {
+	Time  string `json:"time"`
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+func (l *JSONLogger) logf(level LogLevel, f string, a ...interface{}) {
+	if level < l.Level() {
+		return
+	}
+	line, err := json.Marshal(jsonLogEntry{Time: l.now().UTC().Format(time.RFC3339Nano), Level: level.String(), Msg: fmt.Sprintf(f, a...)})
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(append(line, '\n'))
+}
+
+// newLogger builds the engine's logger: a no-op when logging is disabled, otherwise a text logger
+// through the standard log package or a JSON logger to stderr, filtered at minLevel
+func newLogger(enabled bool, minLevel LogLevel, format string) (Logger, error) {
+	if !enabled {
+		return &noopLogger{}, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", LogFormatText:
+		return NewLeveledLogger(minLevel), nil
+	case LogFormatJSON:
+		l := NewJSONLogger(os.Stderr)
+		l.SetLevel(minLevel)
+		return l, nil
+	}
+	return nil, fmt.Errorf("invalid log format %q", format)
 }
 
 type noopLogger struct{}
+
 func (n *noopLogger) Debugf(string, ...interface{}) {}
 func (n *noopLogger) Infof(string, ...interface{})  {}
 func (n *noopLogger) Warnf(string, ...interface{})  {}
@@ -3,15 +3,22 @@ package framework
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/emergent-world-engine/backend/internal/redis_client"
 	"github.com/emergent-world-engine/backend/internal/theta_client"
+	"github.com/emergent-world-engine/backend/pkg/jsonextract"
+	"github.com/emergent-world-engine/backend/pkg/textutil"
 )
 
-// Narrative represents the dynamic storytelling system
+// Narrative represents the dynamic storytelling system. It is safe for concurrent use; mu guards
+// storyState, lore, activeQuests and the quests stored in it.
 type Narrative struct {
 	engine       *Engine
 	storyState   map[string]interface{}
@@ -19,6 +26,10 @@ type Narrative struct {
 	activeQuests map[string]*Quest
 	config       *NarrativeConfig
 	mu           sync.RWMutex
+	now          func() time.Time // quest clock; nil means time.Now
+	stopExpiry   chan struct{} // closed by Close to stop the quest expiry sweeper; nil when none runs
+	expiryDone   chan struct{}
+	closeOnce    sync.Once
 }
 
 // NarrativeConfig holds narrative system configuration
@@ -30,11 +41,48 @@ type NarrativeConfig struct {
 	BranchingFactor   int
 	ConsistencyCheck  bool
 	PlayerChoice      bool
+	Temperature       *float64 // overrides the per-call sampling temperature when set
+	MaxTokens         int      // overrides the per-call completion token budget when > 0
+	ExpiryInterval    time.Duration // how often overdue deadline quests are failed in the background; 0 disables
 }
 
 // NarrativeOption allows configuring narrative behavior
 type NarrativeOption func(*Narrative)
 
+// WithNarrativeTemperature sets the sampling temperature for quest, event and choice
+// generation, clamped to [MinTemperature, MaxTemperature]
+func WithNarrativeTemperature(t float64) NarrativeOption {
+	return func(n *Narrative) {
+		if n.config == nil {
+			n.config = &NarrativeConfig{}
+		}
+		t = clampTemperature(t)
+		n.config.Temperature = &t
+	}
+}
+
+// WithNarrativeMaxTokens sets the completion token budget for quest, event and choice generation,
+// overriding the per-call defaults (DefaultStoryMaxTokens for quests); maxTokens <= 0 restores them
+func WithNarrativeMaxTokens(maxTokens int) NarrativeOption {
+	return func(n *Narrative) {
+		if n.config == nil {
+			n.config = &NarrativeConfig{}
+		}
+		n.config.MaxTokens = max(maxTokens, 0)
+	}
+}
+
+// WithQuestExpiry fails overdue deadline quests every interval from a background goroutine, as
+// ExpireQuests does; call Narrative.Close to stop it
+func WithQuestExpiry(interval time.Duration) NarrativeOption {
+	return func(n *Narrative) {
+		if n.config == nil {
+			n.config = &NarrativeConfig{}
+		}
+		n.config.ExpiryInterval = interval
+	}
+}
+
 // WithGenre sets the narrative genre (fantasy, sci-fi, horror, etc.)
 func WithGenre(genre string) NarrativeOption {
 	return func(n *Narrative) {
@@ -83,12 +131,13 @@ type Quest struct {
 	Objectives   []Objective            `json:"objectives"`
 	Rewards      map[string]interface{} `json:"rewards"`
 	Prerequisites []string              `json:"prerequisites"`
-	Status       string                 `json:"status"` // "available", "active", "completed", "failed"
+	Status       string                 `json:"status"` // "locked", "available", "active", "completed", "failed"
 	Type         string                 `json:"type"`   // "main", "side", "fetch", "kill", "escort"
 	Difficulty   int                    `json:"difficulty"`
 	EstimatedTime time.Duration         `json:"estimated_time"`
 	Location     string                 `json:"location"`
 	NPCGiver     string                 `json:"npc_giver"`
+	Deadline     time.Duration          `json:"deadline,omitempty"` // when > 0, the quest fails once this has passed since CreatedAt
 	Metadata     map[string]interface{} `json:"metadata"`
 	CreatedAt    time.Time              `json:"created_at"`
 }
@@ -129,11 +178,76 @@ type Choice struct {
 	Impact      string                 `json:"impact"`
 }
 
+// QuestOption configures a generated quest before it is registered
+type QuestOption func(*Quest)
+
+// WithDeadline makes the quest fail once d has passed since CreatedAt: queries report it as failed
+// from then on, and the WithQuestExpiry sweeper (or ExpireQuests) records the failure. d <= 0 uses
+// the quest's EstimatedTime. Locked quests never expire; their clock starts when they unlock.
+func WithDeadline(d time.Duration) QuestOption {
+	return func(q *Quest) {
+		if d <= 0 {
+			d = q.EstimatedTime
+		}
+		q.Deadline = d
+	}
+}
+
 // GenerateQuest creates a new quest based on current game state and player context
-func (n *Narrative) GenerateQuest(ctx context.Context, playerContext *GameContext) (*Quest, error) {
-	// Build quest generation prompt
-	prompt := n.buildQuestGenerationPrompt(playerContext)
-	
+func (n *Narrative) GenerateQuest(ctx context.Context, playerContext *GameContext, opts ...QuestOption) (*Quest, error) {
+	questID := fmt.Sprintf("quest_%d", n.clock().UnixNano())
+	quest, err := n.requestQuest(ctx, questID, n.buildQuestGenerationPrompt(playerContext), playerContext, opts...)
+	if err != nil {
+		return nil, err
+	}
+	n.registerQuests(ctx, quest)
+	return quest, nil
+}
+
+// GenerateQuestChain creates length connected quests: each one lists the previous quest in its
+// Prerequisites, and is prompted with the quests before it so theme and characters carry through.
+// Only the first quest is "available"; the rest are "locked" until UpdateQuestProgress completes
+// their prerequisite. opts apply to every quest in the chain. Nothing is registered unless the
+// whole chain is generated.
+func (n *Narrative) GenerateQuestChain(ctx context.Context, playerContext *GameContext, length int, opts ...QuestOption) ([]*Quest, error) {
+	if length < 1 {
+		return nil, fmt.Errorf("quest chain length must be at least 1, got %d", length)
+	}
+	chainID := fmt.Sprintf("chain_%d", n.clock().UnixNano())
+	chain := make([]*Quest, 0, length)
+	for step := 1; step <= length; step++ {
+		prompt := n.buildQuestGenerationPrompt(playerContext) + buildQuestChainContext(chain, step, length)
+		quest, err := n.requestQuest(ctx, fmt.Sprintf("%s_q%d", chainID, step), prompt, playerContext, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("quest %d of %d: %w", step, length, err)
+		}
+		quest.Metadata["chain_id"] = chainID
+		quest.Metadata["chain_step"] = step
+		if step > 1 {
+			quest.Prerequisites = []string{chain[step-2].ID}
+			quest.Status = "locked"
+		}
+		chain = append(chain, quest)
+	}
+	n.registerQuests(ctx, chain...)
+	return chain, nil
+}
+
+// buildQuestChainContext tells the model where a quest sits in its chain and what came before
+func buildQuestChainContext(prior []*Quest, step, length int) string {
+	s := fmt.Sprintf("This is quest %d of %d in a connected storyline: keep the same theme, characters and places, and build on what came before.\n", step, length)
+	if len(prior) > 0 {
+		s += "Previous quests:\n"
+		for i, q := range prior {
+			s += fmt.Sprintf("%d. %s: %s\n", i+1, q.Title, textutil.Snippet(q.Description, 200))
+		}
+	}
+	return s
+}
+
+// requestQuest generates one quest from prompt through GenerateJSON (so a reply without its quest
+// JSON is retried once), parses it under questID and applies opts
+func (n *Narrative) requestQuest(ctx context.Context, questID, prompt string, playerContext *GameContext, opts ...QuestOption) (*Quest, error) {
 	// Get story model (default to DeepSeek R1 for complex narrative generation)
 	model := ModelStoryDefault
 	if n.config != nil && n.config.StoryModel != "" {
@@ -144,31 +258,44 @@ func (n *Narrative) GenerateQuest(ctx context.Context, playerContext *GameContex
 	llmReq := &theta_client.LLMRequest{
 		Model:       model,
 		Prompt:      prompt,
-		MaxTokens:   DefaultStoryMaxTokens,
-		Temperature: 0.8,
+		MaxTokens:   n.maxTokens(DefaultStoryMaxTokens),
+		Temperature: Float64(n.temperature(0.8)),
 	}
+	llmReq.ResponseFormat = textResponseFormat // the quest JSON follows a narrative
 	
-	llmResp, err := n.engine.thetaClient.GenerateWithLLM(ctx, llmReq)
-	if err != nil {
+	_, text, err := generateJSON[questJSON](ctx, n.engine, llmReq)
+	if err != nil && !errors.Is(err, ErrInvalidJSON) {
 		return nil, fmt.Errorf("failed to generate quest: %w", err)
 	}
 	
-	if len(llmResp.Choices) == 0 {
+	if text == "" {
 		return nil, fmt.Errorf("no quest generated")
 	}
 	
-	quest := n.parseGeneratedQuest(llmResp.Choices[0].Text, playerContext)
-	n.mu.Lock(); n.activeQuests[quest.ID] = quest; n.mu.Unlock()
-	
-	// Store in Redis if available
-	if n.engine.IsRedisEnabled() {
-		questKey := fmt.Sprintf("narrative:quest:%s", quest.ID)
-		n.engine.redisClient.Set(ctx, questKey, quest, 7*24*time.Hour)
+	quest := n.parseQuest(questID, text, playerContext)
+	for _, opt := range opts {
+		opt(quest)
 	}
-	
 	return quest, nil
 }
 
+// registerQuests adds quests to the active set, storing them in Redis if available
+func (n *Narrative) registerQuests(ctx context.Context, quests ...*Quest) {
+	n.mu.Lock()
+	for _, quest := range quests {
+		n.activeQuests[quest.ID] = quest
+	}
+	n.mu.Unlock()
+	if !n.engine.IsRedisEnabled() {
+		return
+	}
+	for _, quest := range quests {
+		if err := n.SaveQuest(ctx, quest); err != nil {
+			n.engine.logger.Warnf("narrative: %v", err)
+		}
+	}
+}
+
 // GenerateStoryEvent creates dynamic story events that affect the narrative
 func (n *Narrative) GenerateStoryEvent(ctx context.Context, eventContext *EventContext) (*StoryEvent, error) {
 	// Build story event prompt
@@ -182,11 +309,11 @@ func (n *Narrative) GenerateStoryEvent(ctx context.Context, eventContext *EventC
 	llmReq := &theta_client.LLMRequest{
 		Model:       model,
 		Prompt:      prompt,
-		MaxTokens:   350,
-		Temperature: 0.9, // Higher creativity for story events
+		MaxTokens:   n.maxTokens(350),
+		Temperature: Float64(n.temperature(0.9)), // Higher creativity for story events
 	}
 	
-	llmResp, err := n.engine.thetaClient.GenerateWithLLM(ctx, llmReq)
+	llmResp, err := n.engine.llm.GenerateWithLLM(ctx, llmReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate story event: %w", err)
 	}
@@ -209,7 +336,7 @@ func (n *Narrative) GenerateStoryEvent(ctx context.Context, eventContext *EventC
 		Characters:  eventContext.Characters,
 	}
 	
-	// Generate choices if player choice is enabled
+	// Generate choices if player choice is enabled; the event is still returned without them if the model call fails
 	if n.config != nil && n.config.PlayerChoice {
 		choices, err := n.generateChoices(ctx, event)
 		if err == nil {
@@ -222,14 +349,25 @@ func (n *Narrative) GenerateStoryEvent(ctx context.Context, eventContext *EventC
 
 // UpdateLore adds or updates lore information with consistency checking
 func (n *Narrative) UpdateLore(key string, loreEntry *LoreEntry) error {
+	return n.UpdateLoreContext(context.Background(), key, loreEntry)
+}
+
+// UpdateLoreContext is UpdateLore with a context for the AI consistency check
+func (n *Narrative) UpdateLoreContext(ctx context.Context, key string, loreEntry *LoreEntry) error {
 	// Consistency check if enabled
 	if n.config != nil && n.config.ConsistencyCheck {
-		if err := n.validateLoreConsistency(loreEntry); err != nil {
+		n.mu.RLock()
+		err := n.validateLoreConsistency(loreEntry)
+		n.mu.RUnlock()
+		if err != nil {
+			return fmt.Errorf("lore consistency check failed: %w", err)
+		}
+		if err := n.checkLoreContradictions(ctx, loreEntry); err != nil {
 			return fmt.Errorf("lore consistency check failed: %w", err)
 		}
 	}
 	
-	n.lore[key] = loreEntry
+	n.mu.Lock(); n.lore[key] = loreEntry; n.mu.Unlock()
 	
 	// Store in Redis if available
 	if n.engine.IsRedisEnabled() {
@@ -243,7 +381,10 @@ func (n *Narrative) UpdateLore(key string, loreEntry *LoreEntry) error {
 // GetLore retrieves lore information
 func (n *Narrative) GetLore(key string) (*LoreEntry, bool) {
 	// Check local cache first
-	if entry, exists := n.lore[key]; exists {
+	n.mu.RLock()
+	entry, exists := n.lore[key]
+	n.mu.RUnlock()
+	if exists {
 		if loreEntry, ok := entry.(*LoreEntry); ok {
 			return loreEntry, true
 		}
@@ -254,7 +395,7 @@ func (n *Narrative) GetLore(key string) (*LoreEntry, bool) {
 		loreKey := fmt.Sprintf("narrative:lore:%s", key)
 		var loreEntry LoreEntry
 		if err := n.engine.redisClient.Get(context.Background(), loreKey, &loreEntry); err == nil {
-			n.lore[key] = &loreEntry // Cache locally
+			n.mu.Lock(); n.lore[key] = &loreEntry; n.mu.Unlock() // Cache locally
 			return &loreEntry, true
 		}
 	}
@@ -262,7 +403,9 @@ func (n *Narrative) GetLore(key string) (*LoreEntry, bool) {
 	return nil, false
 }
 
-// TrackPlayerChoice records a player choice and its consequences
+// TrackPlayerChoice records a player choice and applies its consequences to the story state:
+// numeric values (including signed strings such as "+10") are added to a running total read with
+// GetStoryValue, strings and booleans set flags read with GetStoryFlag, and anything else is stored as is
 func (n *Narrative) TrackPlayerChoice(playerID string, choice *Choice) error {
 	if n.config == nil || !n.config.PlayerChoice {
 		return fmt.Errorf("player choice tracking not enabled")
@@ -270,25 +413,118 @@ func (n *Narrative) TrackPlayerChoice(playerID string, choice *Choice) error {
 	
 	// Store choice in narrative state
 	choiceKey := fmt.Sprintf("choice_%s_%d", playerID, time.Now().Unix())
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	n.storyState[choiceKey] = choice
 	
 	// Apply consequences
 	for key, consequence := range choice.Consequences {
-		n.storyState[key] = consequence
+		n.applyConsequence(key, consequence)
 	}
 	
 	return nil
 }
 
-// GetActiveQuests returns all active quests
-func (n *Narrative) GetActiveQuests() map[string]*Quest { n.mu.RLock(); defer n.mu.RUnlock(); cp := make(map[string]*Quest, len(n.activeQuests)); for k,v := range n.activeQuests { cp[k]=v }; return cp }
+// applyConsequence folds one consequence into the story state; callers hold n.mu
+func (n *Narrative) applyConsequence(key string, consequence interface{}) {
+	if delta, ok := consequenceNumber(consequence); ok {
+		total, _ := n.storyState[key].(float64)
+		n.storyState[key] = total + delta
+		return
+	}
+	switch v := consequence.(type) {
+	case string:
+		n.storyState[key] = v
+	case bool:
+		n.storyState[key] = strconv.FormatBool(v)
+	default:
+		n.storyState[key] = consequence
+	}
+}
 
-// UpdateQuestProgress updates the progress of a quest objective
+// consequenceNumber reads a numeric consequence; strings only count when they carry an explicit
+// sign, so "+10" is a delta while "10" stays a flag
+func consequenceNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		s := strings.TrimSpace(n)
+		if s == "" || s[0] != '+' && s[0] != '-' {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// GetStoryValue returns the running total of a numeric consequence, such as accumulated reputation
+func (n *Narrative) GetStoryValue(key string) (float64, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	v, ok := n.storyState[key].(float64)
+	return v, ok
+}
+
+// GetStoryFlag returns the flag a string or boolean consequence set
+func (n *Narrative) GetStoryFlag(key string) (string, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	v, ok := n.storyState[key].(string)
+	return v, ok
+}
+
+// GetActiveQuests returns a snapshot of all active quests; the quests are copies, so later progress
+// updates don't show up in (or race with) the returned values. Overdue deadline quests are reported
+// as failed even before the expiry sweeper records their failure.
+func (n *Narrative) GetActiveQuests() map[string]*Quest {
+	n.mu.RLock(); defer n.mu.RUnlock()
+	now := n.clock()
+	cp := make(map[string]*Quest, len(n.activeQuests))
+	for k, v := range n.activeQuests {
+		cp[k] = cloneQuest(v)
+		if questOverdue(v, now) {
+			cp[k].Status = "failed"
+		}
+	}
+	return cp
+}
+
+// cloneQuest copies a quest and its objectives; rewards and metadata are shared
+func cloneQuest(q *Quest) *Quest {
+	cp := *q
+	cp.Objectives = append([]Objective(nil), q.Objectives...)
+	cp.Prerequisites = append([]string(nil), q.Prerequisites...)
+	return &cp
+}
+
+// UpdateQuestProgress updates the progress of a quest objective. It rejects failed quests, including
+// overdue deadline quests the expiry sweeper has not reached yet.
 func (n *Narrative) UpdateQuestProgress(questID, objectiveID string, progress int) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
 	quest, exists := n.activeQuests[questID]
 	if !exists {
 		return fmt.Errorf("quest %s not found", questID)
 	}
+	if quest.Status == "failed" {
+		return fmt.Errorf("quest %s has failed", questID)
+	}
+	if questOverdue(quest, n.clock()) {
+		return fmt.Errorf("quest %s has failed: past its deadline", questID)
+	}
 	
 	// Find and update the objective
 	for i, objective := range quest.Objectives {
@@ -301,6 +537,7 @@ func (n *Narrative) UpdateQuestProgress(questID, objectiveID string, progress in
 			// Check if quest is completed
 			if n.isQuestCompleted(quest) {
 				quest.Status = "completed"
+				n.unlockQuests()
 			}
 			
 			return nil
@@ -310,6 +547,198 @@ func (n *Narrative) UpdateQuestProgress(questID, objectiveID string, progress in
 	return fmt.Errorf("objective %s not found in quest %s", objectiveID, questID)
 }
 
+// FailQuest marks an unfinished quest as failed and records the failure in the story state under
+// quest_failed_<id>. The failure is persisted to Redis when it is enabled.
+func (n *Narrative) FailQuest(questID, reason string) error {
+	n.mu.Lock()
+	quest, exists := n.activeQuests[questID]
+	if !exists {
+		n.mu.Unlock()
+		return fmt.Errorf("quest %s not found", questID)
+	}
+	if quest.Status == "completed" || quest.Status == "failed" {
+		n.mu.Unlock()
+		return fmt.Errorf("quest %s is already %s", questID, quest.Status)
+	}
+	n.failQuest(quest, reason, n.clock())
+	n.mu.Unlock()
+	n.persistFailures(context.Background(), quest)
+	return nil
+}
+
+// ExpireQuests fails every quest whose Deadline has passed since CreatedAt, persists the failures to
+// Redis when it is enabled and returns them. The WithQuestExpiry sweeper calls it on a ticker.
+func (n *Narrative) ExpireQuests(ctx context.Context) []*Quest {
+	n.mu.Lock()
+	expired := n.expireQuests(n.clock())
+	n.mu.Unlock()
+	n.persistFailures(ctx, expired...)
+	return expired
+}
+
+// startExpirySweeper runs ExpireQuests on a ticker until Close
+func (n *Narrative) startExpirySweeper(interval time.Duration) {
+	n.stopExpiry = make(chan struct{})
+	n.expiryDone = make(chan struct{})
+	go func() {
+		defer close(n.expiryDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-n.stopExpiry:
+				return
+			case <-ticker.C:
+				if expired := n.ExpireQuests(context.Background()); len(expired) > 0 {
+					n.engine.logger.Debugf("narrative: expired %d overdue quests", len(expired))
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the background quest expiry sweeper, if one is running. It is safe to call more than once.
+func (n *Narrative) Close() {
+	n.closeOnce.Do(func() {
+		if n.stopExpiry != nil {
+			close(n.stopExpiry)
+			<-n.expiryDone
+		}
+	})
+}
+
+// expireQuests fails overdue deadline quests; callers hold n.mu
+func (n *Narrative) expireQuests(now time.Time) []*Quest {
+	var expired []*Quest
+	for _, quest := range n.activeQuests {
+		if questOverdue(quest, now) {
+			n.failQuest(quest, "deadline expired", now)
+			expired = append(expired, quest)
+		}
+	}
+	return expired
+}
+
+// questOverdue reports whether an unfinished, unlocked quest's deadline has passed at now
+func questOverdue(quest *Quest, now time.Time) bool {
+	if quest.Deadline <= 0 || quest.Status == "locked" || quest.Status == "completed" || quest.Status == "failed" {
+		return false
+	}
+	return now.Sub(quest.CreatedAt) >= quest.Deadline
+}
+
+// clock returns the current time from the narrative's clock
+func (n *Narrative) clock() time.Time {
+	if n.now == nil {
+		return time.Now()
+	}
+	return n.now()
+}
+
+// failQuest sets a quest's status to failed and adds a story-state entry; callers hold n.mu.
+// Metadata is replaced rather than written to, since quest copies share the map.
+func (n *Narrative) failQuest(quest *Quest, reason string, now time.Time) {
+	quest.Status = "failed"
+	metadata := make(map[string]interface{}, len(quest.Metadata)+2)
+	for k, v := range quest.Metadata {
+		metadata[k] = v
+	}
+	metadata["failure_reason"] = reason
+	metadata["failed_at"] = now
+	quest.Metadata = metadata
+	n.storyState[fmt.Sprintf("quest_failed_%s", quest.ID)] = map[string]interface{}{"quest_id": quest.ID, "title": quest.Title, "reason": reason, "failed_at": now}
+}
+
+// persistFailures saves failed quests to Redis, which drops them from the quests:active set
+func (n *Narrative) persistFailures(ctx context.Context, quests ...*Quest) {
+	if !n.engine.IsRedisEnabled() {
+		return
+	}
+	for _, quest := range quests {
+		if err := n.SaveQuest(ctx, quest); err != nil {
+			n.engine.logger.Warnf("narrative: %v", err)
+		}
+	}
+}
+
+// unlockQuests makes locked quests whose prerequisites are all completed available; callers hold n.mu
+func (n *Narrative) unlockQuests() {
+	for _, quest := range n.activeQuests {
+		if quest.Status != "locked" {
+			continue
+		}
+		ready := true
+		for _, id := range quest.Prerequisites {
+			if prereq, ok := n.activeQuests[id]; !ok || prereq.Status != "completed" {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			quest.Status = "available"
+			if quest.Deadline > 0 {
+				quest.CreatedAt = n.clock() // the deadline runs from the unlock
+			}
+		}
+	}
+}
+
+// SaveQuest persists a quest to Redis (narrative:quest:<id>) and keeps the quests:active set in sync
+// with its status. Call it after UpdateQuestProgress so progress survives restarts.
+func (n *Narrative) SaveQuest(ctx context.Context, quest *Quest) error {
+	if !n.engine.IsRedisEnabled() {
+		return fmt.Errorf("failed to save quest %s: %w", quest.ID, ErrRedisNotEnabled)
+	}
+	questKey := fmt.Sprintf("narrative:quest:%s", quest.ID)
+	// Serialize a snapshot so a concurrent UpdateQuestProgress can't change the quest mid-write
+	n.mu.RLock()
+	snapshot := cloneQuest(quest)
+	n.mu.RUnlock()
+	if err := n.engine.redisClient.Set(ctx, questKey, snapshot, 7*24*time.Hour); err != nil {
+		return fmt.Errorf("failed to save quest %s: %w", quest.ID, err)
+	}
+	var err error
+	if snapshot.Status == "completed" || snapshot.Status == "failed" {
+		err = n.engine.redisClient.RemoveActiveQuest(ctx, quest.ID)
+	} else {
+		err = n.engine.redisClient.AddActiveQuest(ctx, quest.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update active quests for %s: %w", quest.ID, err)
+	}
+	n.mu.Lock(); n.activeQuests[quest.ID] = quest; n.mu.Unlock()
+	return nil
+}
+
+// LoadActiveQuests repopulates active quests from Redis using the quests:active set.
+// Quests whose data has expired are dropped from the set.
+func (n *Narrative) LoadActiveQuests(ctx context.Context) error {
+	if !n.engine.IsRedisEnabled() {
+		return fmt.Errorf("failed to load active quests: %w", ErrRedisNotEnabled)
+	}
+	ids, err := n.engine.redisClient.GetActiveQuests(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load active quests: %w", err)
+	}
+	loaded := make(map[string]*Quest, len(ids))
+	for _, id := range ids {
+		var quest Quest
+		if err := n.engine.redisClient.Get(ctx, fmt.Sprintf("narrative:quest:%s", id), &quest); err != nil {
+			if redis_client.IsNotFound(err) {
+				n.engine.logger.Debugf("narrative: dropping expired quest %s", id)
+				n.engine.redisClient.RemoveActiveQuest(ctx, id)
+				continue
+			}
+			return fmt.Errorf("failed to load quest %s: %w", id, err)
+		}
+		loaded[id] = &quest
+	}
+	n.mu.Lock()
+	for id, quest := range loaded { n.activeQuests[id] = quest }
+	n.mu.Unlock()
+	return nil
+}
+
 // EventContext provides context for story event generation
 type EventContext struct {
 	Type       string
@@ -356,7 +785,11 @@ func (n *Narrative) buildQuestGenerationPrompt(playerContext *GameContext) strin
 		prompt += fmt.Sprintf("Nearby NPCs: %v\n", playerContext.NearbyNPCs)
 	}
 	
-	prompt += "Create a quest with clear objectives, appropriate difficulty, and engaging narrative elements:"
+	prompt += "Create a quest with clear objectives, appropriate difficulty, and engaging narrative elements.\n"
+	prompt += "After the narrative, end your reply with a single JSON object:\n"
+	prompt += `{"title": "...", "description": "...", "type": "main|side|fetch|kill|escort", "difficulty": 1-10, ` +
+		`"objectives": [{"description": "...", "type": "kill|collect|talk|reach", "target": "...", "required": 1, "optional": false}], ` +
+		`"rewards": {"experience": 100, "gold": 50}}`
 	
 	return prompt
 }
@@ -387,46 +820,104 @@ func (n *Narrative) buildStoryEventPrompt(eventContext *EventContext) string {
 	return prompt
 }
 
+// questJSON is the trailing JSON block requested by buildQuestGenerationPrompt
+type questJSON struct {
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Type        string                 `json:"type"`
+	Difficulty  json.RawMessage        `json:"difficulty"`
+	Objectives  []json.RawMessage      `json:"objectives"`
+	Rewards     map[string]interface{} `json:"rewards"`
+}
+
+// parseGeneratedQuest builds a Quest from the model's trailing JSON block, falling back to a
+// generic single-objective quest when no valid JSON is found
 func (n *Narrative) parseGeneratedQuest(questContent string, playerContext *GameContext) *Quest {
-	questID := fmt.Sprintf("quest_%d", time.Now().Unix())
-	var parsed struct {
-		Title       string                 `json:"title"`
-		Description string                 `json:"description"`
-		Objectives  []struct { ID string `json:"id"`; Description string `json:"description"` } `json:"objectives"`
-		Rewards     map[string]interface{} `json:"rewards"`
-	}
-	clean := strings.TrimSpace(questContent)
-	start := strings.Index(clean, "{")
-	end := strings.LastIndex(clean, "}")
-	usedJSON := false
-	if start >= 0 && end > start {
-		fragment := clean[start : end+1]
-		if json.Unmarshal([]byte(fragment), &parsed) == nil { usedJSON = true }
-	}
-	quest := &Quest{ID: questID, Title: "Quest Directive", Description: questContent, Status: "available", Type: "side", Difficulty: 5, EstimatedTime: 30 * time.Minute, Location: playerContext.Location, CreatedAt: time.Now(), Objectives: []Objective{{ID: fmt.Sprintf("%s_obj_1", questID), Description: "Complete the quest objective", Type: "general", Current: 0, Required: 1}}, Rewards: map[string]interface{}{"experience": 100, "gold": 50}, Metadata: make(map[string]interface{})}
-	if usedJSON {
-		if parsed.Title != "" { quest.Title = parsed.Title }
-		if parsed.Description != "" { quest.Description = parsed.Description }
-		if len(parsed.Objectives) > 0 {
-			objs := make([]Objective, 0, len(parsed.Objectives))
-			for i, o := range parsed.Objectives {
-				id := o.ID
-				if id == "" { id = fmt.Sprintf("%s_obj_%d", questID, i+1) }
-				objs = append(objs, Objective{ID: id, Description: o.Description, Type: "general", Required: 1})
-			}
-			quest.Objectives = objs
-		}
-		for k, v := range parsed.Rewards { quest.Rewards[k] = v }
-		quest.Metadata["structured"] = true
-	} else { quest.Metadata["structured"] = false }
+	return n.parseQuest(fmt.Sprintf("quest_%d", n.clock().UnixNano()), questContent, playerContext)
+}
+
+// parseQuest is parseGeneratedQuest with a caller-chosen quest ID
+func (n *Narrative) parseQuest(questID, questContent string, playerContext *GameContext) *Quest {
+	quest := &Quest{ID: questID, Title: "Quest Directive", Description: strings.TrimSpace(questContent), Status: "available", Type: "side", Difficulty: 5, EstimatedTime: 30 * time.Minute, Location: playerContext.Location, CreatedAt: n.clock(), Objectives: []Objective{{ID: fmt.Sprintf("%s_obj_1", questID), Description: "Complete the quest objective", Type: "general", Current: 0, Required: 1}}, Rewards: map[string]interface{}{"experience": 100, "gold": 50}, Metadata: make(map[string]interface{})}
+
+	var parsed questJSON
+	start, end, _ := jsonextract.LastObject(questContent)
+	if start < 0 || unmarshalRepaired(questContent[start:end+1], &parsed) != nil {
+		quest.Metadata["structured"] = false
+		return quest
+	}
+	quest.Metadata["structured"] = true
+	if parsed.Title != "" { quest.Title = parsed.Title }
+	if parsed.Description != "" {
+		quest.Description = parsed.Description
+	} else if narrative := jsonextract.TrimFence(questContent[:start]); narrative != "" {
+		quest.Description = narrative
+	}
+	if parsed.Type != "" { quest.Type = strings.ToLower(parsed.Type) }
+	if d, ok := parseQuestDifficulty(parsed.Difficulty); ok { quest.Difficulty = d }
+	if objs := parseQuestObjectives(parsed.Objectives, questID); len(objs) > 0 { quest.Objectives = objs }
+	for k, v := range parsed.Rewards { quest.Rewards[k] = v }
 	return quest
 }
 
+// parseQuestObjectives accepts objectives as plain strings or objects
+func parseQuestObjectives(raw []json.RawMessage, questID string) []Objective {
+	objs := make([]Objective, 0, len(raw))
+	for _, item := range raw {
+		var o struct {
+			ID          string `json:"id"`
+			Description string `json:"description"`
+			Type        string `json:"type"`
+			Target      string `json:"target"`
+			Required    int    `json:"required"`
+			Optional    bool   `json:"optional"`
+		}
+		if json.Unmarshal(item, &o.Description) != nil && json.Unmarshal(item, &o) != nil {
+			continue
+		}
+		if strings.TrimSpace(o.Description) == "" { continue }
+		if o.ID == "" { o.ID = fmt.Sprintf("%s_obj_%d", questID, len(objs)+1) }
+		if o.Type == "" { o.Type = "general" }
+		if o.Required < 1 { o.Required = 1 }
+		objs = append(objs, Objective{ID: o.ID, Description: o.Description, Type: o.Type, Target: o.Target, Required: o.Required, Optional: o.Optional})
+	}
+	return objs
+}
+
+// parseQuestDifficulty accepts a 1-10 number (clamped) or an easy/medium/hard label
+func parseQuestDifficulty(raw json.RawMessage) (int, bool) {
+	if len(raw) == 0 { return 0, false }
+	var num float64
+	if json.Unmarshal(raw, &num) == nil {
+		d := int(num + 0.5)
+		if d < 1 { d = 1 }
+		if d > 10 { d = 10 }
+		return d, true
+	}
+	var label string
+	if json.Unmarshal(raw, &label) != nil { return 0, false }
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "trivial", "easy":
+		return 3, true
+	case "normal", "medium", "moderate":
+		return 5, true
+	case "hard", "difficult":
+		return 8, true
+	case "legendary", "extreme", "very hard":
+		return 10, true
+	}
+	return 0, false
+}
+
+// generateChoices asks the story model for a JSON array of choices for event, falling back to
+// defaultChoices when the reply cannot be parsed
 func (n *Narrative) generateChoices(ctx context.Context, event *StoryEvent) ([]Choice, error) {
 	prompt := fmt.Sprintf("Generate 2-3 meaningful player choices for this story event:\n%s\n", event.Description)
-	prompt += "Each choice should have different consequences and impact on the story:"
+	prompt += "Each choice should have different consequences and impact on the story.\n"
+	prompt += `Reply only with a JSON array: [{"text": "...", "impact": "positive|neutral|negative", ` +
+		`"consequences": {"reputation": 10}, "requirements": ["..."]}]`
 	
-	model := "deepseek_r1" // Use DeepSeek R1 for choice generation
+	model := ModelStoryDefault // unless NarrativeConfig.StoryModel overrides it
 	if n.config != nil && n.config.StoryModel != "" {
 		model = n.config.StoryModel
 	}
@@ -434,11 +925,11 @@ func (n *Narrative) generateChoices(ctx context.Context, event *StoryEvent) ([]C
 	llmReq := &theta_client.LLMRequest{
 		Model:       model,
 		Prompt:      prompt,
-		MaxTokens:   200,
-		Temperature: 0.7,
+		MaxTokens:   n.maxTokens(DefaultReasoningMaxTokens),
+		Temperature: Float64(n.temperature(0.7)),
 	}
 	
-	llmResp, err := n.engine.thetaClient.GenerateWithLLM(ctx, llmReq)
+	llmResp, err := n.engine.llm.GenerateWithLLM(ctx, llmReq)
 	if err != nil {
 		return nil, err
 	}
@@ -447,8 +938,69 @@ func (n *Narrative) generateChoices(ctx context.Context, event *StoryEvent) ([]C
 		return nil, fmt.Errorf("no choices generated")
 	}
 	
-	// Parse choices from response - simplified implementation
-	choices := []Choice{
+	if choices := parseChoices(llmResp.Choices[0].Text); len(choices) > 0 {
+		return choices, nil
+	}
+	n.engine.logger.Debugf("narrative: unparseable choices, using defaults: %s", textutil.Snippet(llmResp.Choices[0].Text, 200))
+	return defaultChoices(), nil
+}
+
+// parseChoices reads a JSON array of choices (objects or plain strings, optionally wrapped as
+// {"choices": [...]}) from model output, dropping duplicates and entries without text
+func parseChoices(text string) []Choice {
+	if i := strings.LastIndex(text, "</think>"); i >= 0 {
+		text = text[i+len("</think>"):]
+	}
+	var items []json.RawMessage
+	start, end := strings.Index(text, "["), strings.LastIndex(text, "]")
+	if start < 0 || end <= start || unmarshalRepaired(text[start:end+1], &items) != nil {
+		var wrapped struct {
+			Choices []json.RawMessage `json:"choices"`
+		}
+		objStart, objEnd, _ := jsonextract.LastObject(text)
+		if objStart < 0 || unmarshalRepaired(text[objStart:objEnd+1], &wrapped) != nil {
+			return nil
+		}
+		items = wrapped.Choices
+	}
+
+	choices := make([]Choice, 0, len(items))
+	seen := map[string]bool{}
+	for _, item := range items {
+		var c struct {
+			Text         string                 `json:"text"`
+			Impact       string                 `json:"impact"`
+			Consequences map[string]interface{} `json:"consequences"`
+			Requirements []string               `json:"requirements"`
+		}
+		if json.Unmarshal(item, &c.Text) != nil && json.Unmarshal(item, &c) != nil {
+			continue
+		}
+		c.Text = strings.TrimSpace(c.Text)
+		if c.Text == "" || seen[strings.ToLower(c.Text)] {
+			continue
+		}
+		seen[strings.ToLower(c.Text)] = true
+		if c.Impact == "" {
+			c.Impact = "neutral"
+		}
+		if c.Consequences == nil {
+			c.Consequences = map[string]interface{}{}
+		}
+		choices = append(choices, Choice{
+			ID:           fmt.Sprintf("choice_%d", len(choices)+1),
+			Text:         c.Text,
+			Consequences: c.Consequences,
+			Requirements: c.Requirements,
+			Impact:       strings.ToLower(c.Impact),
+		})
+	}
+	return choices
+}
+
+// defaultChoices is the fallback when the model's choices cannot be parsed
+func defaultChoices() []Choice {
+	return []Choice{
 		{
 			ID:   "choice_1",
 			Text: "Accept the challenge",
@@ -468,8 +1020,6 @@ func (n *Narrative) generateChoices(ctx context.Context, event *StoryEvent) ([]C
 			Impact: "neutral",
 		},
 	}
-	
-	return choices, nil
 }
 
 func (n *Narrative) validateLoreConsistency(newEntry *LoreEntry) error {
@@ -486,6 +1036,99 @@ func (n *Narrative) validateLoreConsistency(newEntry *LoreEntry) error {
 	return nil
 }
 
+// relatedLore returns existing entries sharing a tag or reference with the new entry (or naming it),
+// most important first, capped at MaxLoreConsistencyEntries
+func (n *Narrative) relatedLore(newEntry *LoreEntry) []*LoreEntry {
+	keys := map[string]bool{}
+	for _, k := range append(append([]string{}, newEntry.Tags...), newEntry.References...) {
+		keys[strings.ToLower(k)] = true
+	}
+	var related []*LoreEntry
+	for _, existing := range n.lore {
+		lore, ok := existing.(*LoreEntry)
+		if !ok || (newEntry.ID != "" && lore.ID == newEntry.ID) {
+			continue
+		}
+		match := keys[strings.ToLower(lore.ID)] || keys[strings.ToLower(lore.Title)]
+		for _, k := range append(append([]string{}, lore.Tags...), lore.References...) {
+			k = strings.ToLower(k)
+			if keys[k] || k == strings.ToLower(newEntry.ID) || k == strings.ToLower(newEntry.Title) {
+				match = true
+			}
+		}
+		if match {
+			related = append(related, lore)
+		}
+	}
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Importance != related[j].Importance {
+			return related[i].Importance > related[j].Importance
+		}
+		return related[i].Title < related[j].Title
+	})
+	if len(related) > MaxLoreConsistencyEntries {
+		related = related[:MaxLoreConsistencyEntries]
+	}
+	return related
+}
+
+// loreVerdict is the story model's answer to a lore consistency check
+type loreVerdict struct {
+	Contradiction bool   `json:"contradiction"`
+	Explanation   string `json:"explanation"`
+}
+
+// checkLoreContradictions asks the story model whether the new entry contradicts related lore.
+// Model failures and unreadable verdicts are logged and let the update through.
+func (n *Narrative) checkLoreContradictions(ctx context.Context, newEntry *LoreEntry) error {
+	n.mu.RLock()
+	related := n.relatedLore(newEntry)
+	n.mu.RUnlock()
+	if len(related) == 0 {
+		return nil
+	}
+
+	prompt := "You maintain the canon of a game world. Existing lore:\n"
+	for _, lore := range related {
+		prompt += fmt.Sprintf("- [%s] %s: %s\n", lore.Category, lore.Title, textutil.Snippet(lore.Content, 400))
+	}
+	prompt += fmt.Sprintf("\nProposed new entry:\n- [%s] %s: %s\n\n", newEntry.Category, newEntry.Title, textutil.Snippet(newEntry.Content, 800))
+	prompt += `Does the new entry contradict the existing lore? Reply only with JSON: {"contradiction": true|false, "explanation": "..."}`
+
+	model := ModelStoryDefault
+	if n.config != nil && n.config.StoryModel != "" {
+		model = n.config.StoryModel
+	}
+	verdict, err := GenerateJSON[loreVerdict](ctx, n.engine, &theta_client.LLMRequest{Model: model, Prompt: prompt, MaxTokens: DefaultReasoningMaxTokens, Temperature: Float64(0.1)})
+	if err != nil {
+		n.engine.logger.Warnf("narrative: lore contradiction check skipped for %q: %v", newEntry.Title, err)
+		return nil
+	}
+	if verdict.Contradiction {
+		if verdict.Explanation == "" {
+			verdict.Explanation = "contradicts existing lore"
+		}
+		return fmt.Errorf("%q contradicts existing lore: %s", newEntry.Title, verdict.Explanation)
+	}
+	return nil
+}
+
+// temperature returns the configured temperature, or def when none is set
+func (n *Narrative) temperature(def float64) float64 {
+	if n.config != nil && n.config.Temperature != nil {
+		return *n.config.Temperature
+	}
+	return def
+}
+
+// maxTokens returns the configured completion token budget, or def when none is set
+func (n *Narrative) maxTokens(def int) int {
+	if n.config != nil && n.config.MaxTokens > 0 {
+		return n.config.MaxTokens
+	}
+	return def
+}
+
 func (n *Narrative) isQuestCompleted(quest *Quest) bool {
 	for _, objective := range quest.Objectives {
 		if !objective.Optional && !objective.Completed {
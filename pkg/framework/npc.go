@@ -2,14 +2,28 @@ package framework
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"mime"
+	"net/http"
 	"sort"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/emergent-world-engine/backend/internal/redis_client"
 	"github.com/emergent-world-engine/backend/internal/theta_client"
+	"github.com/emergent-world-engine/backend/pkg/textutil"
 )
 
+// NPCMemoryEntry is a ranked memory stored via UpdateMemoryWithMeta
+type NPCMemoryEntry = redis_client.NPCMemoryEntry
+
 // NPC represents an AI-driven non-player character
 type NPC struct {
 	id          string
@@ -18,20 +32,37 @@ type NPC struct {
 	personality map[string]interface{}
 	state       map[string]interface{}
 	config      *NPCConfig
+	limiter     *npcRateLimiter // set by WithRateLimit
 	mu          sync.RWMutex
 }
 
 // NPCConfig holds NPC-specific configuration
 type NPCConfig struct {
 	DialogueModel  string
+	FallbackModel  string // retried by GenerateDialogue when DialogueModel errors or returns nothing
+	Temperature    *float64 // dialogue sampling temperature; nil uses 0.8
+	MaxTokens      int      // dialogue completion budget; 0 uses DefaultDialogueMaxTokens
 	VoiceModel     string
 	VisionModel    string
 	Personality    string
 	Background     string
 	Relationships  map[string]string
+	DisableRelationshipContext bool // omit relationships from the dialogue prompt
+	ContextKeys    []string // GameState/PlayerStats keys rendered into the dialogue prompt
 	MemoryLimit    int
+	HistoryWindow  int  // most recent History entries rendered into the prompt; 0 renders all
+	SummarizeHistory bool // summarize History older than the window with an extra LLM call
+	AllowedActions []string // actions GenerateDialogue may suggest; empty disables suggestions
 	EnableVoice    bool
 	EnableVision   bool
+	EnableEmotion  bool // classify each reply's emotion with an extra LLM call
+	VoiceStyles    map[string]string // emotion -> Kokoro voice style, overrides defaultVoiceStyles
+	PromptTemplate *template.Template // replaces the built-in dialogue prompt; see WithPromptTemplate
+	RateLimit      int  // dialogue calls allowed per second; 0 disables the limit
+	RateLimitQueue bool // wait for capacity instead of failing with ErrNPCRateLimited
+	Language       string // BCP 47 code the NPC speaks, e.g. "es"; empty leaves the model's default
+
+	promptTemplateErr error // set by WithPromptTemplate when the template is invalid
 }
 
 // NPCOption allows configuring NPC behavior
@@ -65,7 +96,7 @@ func WithVoice(enabled bool) NPCOption {
 		}
 		npc.config.EnableVoice = enabled
 		if enabled && npc.config.VoiceModel == "" {
-			npc.config.VoiceModel = "kokoro-82m"
+			npc.config.VoiceModel = ModelVoiceDefault
 		}
 	}
 }
@@ -78,7 +109,95 @@ func WithVision(enabled bool) NPCOption {
 		}
 		npc.config.EnableVision = enabled
 		if enabled && npc.config.VisionModel == "" {
-			npc.config.VisionModel = "grounding-dino"
+			npc.config.VisionModel = ModelVisionDefault
+		}
+	}
+}
+
+// WithDialogueModel sets the LLM used for dialogue (defaults to ModelDialogueDefault)
+func WithDialogueModel(model string) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.DialogueModel = model
+	}
+}
+
+// WithFallbackModel sets a second dialogue model tried when the primary fails or returns no text
+func WithFallbackModel(model string) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.FallbackModel = model
+	}
+}
+
+// WithDialogueTemperature sets the dialogue sampling temperature, clamped to [MinTemperature, MaxTemperature]
+func WithDialogueTemperature(t float64) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		t = clampTemperature(t)
+		npc.config.Temperature = &t
+	}
+}
+
+// WithDialogueMaxTokens sets the completion token budget for dialogue replies (defaults to
+// DefaultDialogueMaxTokens); maxTokens <= 0 restores the default
+func WithDialogueMaxTokens(maxTokens int) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.MaxTokens = max(maxTokens, 0)
+	}
+}
+
+// WithVisionModel sets the vision model used by Perceive (defaults to ModelVisionDefault)
+func WithVisionModel(model string) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.VisionModel = model
+	}
+}
+
+// WithVoiceModel sets the text-to-speech voice model (defaults to ModelVoiceDefault)
+func WithVoiceModel(model string) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.VoiceModel = model
+	}
+}
+
+// WithEmotionDetection classifies each generated reply into one of the supported emotions
+// (costs one extra short LLM call per dialogue)
+func WithEmotionDetection(enabled bool) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.EnableEmotion = enabled
+	}
+}
+
+// WithVoiceStyleMapping maps emotions to Kokoro voice styles; unmapped emotions use the default mapping
+func WithVoiceStyleMapping(styles map[string]string) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		if npc.config.VoiceStyles == nil {
+			npc.config.VoiceStyles = make(map[string]string, len(styles))
+		}
+		for emotion, style := range styles {
+			npc.config.VoiceStyles[strings.ToLower(emotion)] = style
 		}
 	}
 }
@@ -96,6 +215,223 @@ func WithRelationship(entityID, relationship string) NPCOption {
 	}
 }
 
+// WithRelationshipContext toggles rendering relationships into the dialogue prompt (on by default)
+func WithRelationshipContext(enabled bool) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.DisableRelationshipContext = !enabled
+	}
+}
+
+// WithMemoryLimit caps how many dialogue entries and ranked memories the NPC remembers
+// (lowest importance is evicted first, oldest among equals)
+func WithMemoryLimit(n int) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.MemoryLimit = n
+	}
+}
+
+// WithHistoryWindow keeps only the last n DialogueRequest.History exchanges in the dialogue prompt
+func WithHistoryWindow(n int) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.HistoryWindow = n
+	}
+}
+
+// WithHistorySummarization summarizes history older than the window into a short recap, cached
+// in NPC memory until the older history changes. It has no effect without WithHistoryWindow.
+func WithHistorySummarization(enabled bool) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.SummarizeHistory = enabled
+	}
+}
+
+// WithActionSuggestions lets GenerateDialogue propose in-world actions (e.g. "offer_quest", "trade")
+// alongside the reply. The model answers in JSON and only actions from this list are kept.
+func WithActionSuggestions(actions []string) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.AllowedActions = append([]string(nil), actions...)
+	}
+}
+
+// WithContextKeys allowlists GameContext.GameState and PlayerStats keys the NPC may see.
+// Only these keys are rendered into the dialogue prompt, in the given order; by default none are.
+func WithContextKeys(keys ...string) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.ContextKeys = append(npc.config.ContextKeys, keys...)
+	}
+}
+
+// WithPromptTemplate replaces the built-in English dialogue prompt with a text/template rendered
+// against DialoguePromptData, e.g. "Du bist {{.Name}}. {{.Personality}} Spieler: {{.PlayerMessage}}".
+// The template is validated here (see ParsePromptTemplate); if it is invalid, GenerateDialogue and
+// GenerateDialogueStream return the error instead of calling the model.
+func WithPromptTemplate(tmpl string) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.PromptTemplate, npc.config.promptTemplateErr = ParsePromptTemplate(tmpl)
+	}
+}
+
+// WithRateLimit caps GenerateDialogue and GenerateDialogueStream at perSecond calls per second for
+// this NPC, allowing bursts of up to perSecond calls. Excess calls fail with ErrNPCRateLimited unless
+// WithRateLimitQueue is set. A limit of 0 or less disables it.
+func WithRateLimit(perSecond int) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.RateLimit = max(perSecond, 0)
+		npc.limiter = nil
+		if perSecond > 0 {
+			npc.limiter = newNPCRateLimiter(perSecond)
+		}
+	}
+}
+
+// WithRateLimitQueue makes calls over the WithRateLimit budget wait for capacity (or for their
+// context to end) instead of failing with ErrNPCRateLimited
+func WithRateLimitQueue(enabled bool) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.RateLimitQueue = enabled
+	}
+}
+
+// WithLanguage makes the NPC speak the language with the given code ("es", "fr", "pt-br", ...): the
+// built-in dialogue prompt tells the model to respond in it, and with voice enabled the speech is
+// synthesized in a matching Kokoro voice unless WithVoiceModel chose another voice.
+func WithLanguage(code string) NPCOption {
+	return func(npc *NPC) {
+		if npc.config == nil {
+			npc.config = &NPCConfig{}
+		}
+		npc.config.Language = normalizeLanguage(code)
+	}
+}
+
+// ErrNPCRateLimited is returned by dialogue calls that exceed the NPC's WithRateLimit budget
+var ErrNPCRateLimited = errors.New("npc rate limited")
+
+// npcRateLimiter is a token bucket refilled lazily on each call, so idle NPCs cost no goroutines
+type npcRateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second, also the bucket size
+	tokens float64
+	last   time.Time
+}
+
+func newNPCRateLimiter(perSecond int) *npcRateLimiter {
+	return &npcRateLimiter{rate: float64(perSecond), tokens: float64(perSecond), last: time.Now()}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it. When wait is
+// false and no token is available, nothing is taken and ok is false.
+func (l *npcRateLimiter) reserve(wait bool) (delay time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens = min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens < 1 && !wait {
+		return 0, false
+	}
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0, true
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second)), true
+}
+
+// cancel returns a reserved token whose caller gave up waiting
+func (l *npcRateLimiter) cancel() {
+	l.mu.Lock()
+	l.tokens = min(l.rate, l.tokens+1)
+	l.mu.Unlock()
+}
+
+// acquireDialogue applies the NPC's rate limit before a dialogue call
+func (npc *NPC) acquireDialogue(ctx context.Context) error {
+	npc.mu.RLock()
+	l, queue := npc.limiter, npc.config != nil && npc.config.RateLimitQueue
+	npc.mu.RUnlock()
+	if l == nil {
+		return nil
+	}
+	delay, ok := l.reserve(queue)
+	if !ok {
+		return fmt.Errorf("npc %s: %w", npc.id, ErrNPCRateLimited)
+	}
+	if delay == 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return fmt.Errorf("npc %s: waiting for rate limit: %w", npc.id, ctx.Err())
+	}
+}
+
+// ErrInvalidPromptTemplate is returned for dialogue prompt templates that fail to parse or render
+var ErrInvalidPromptTemplate = errors.New("invalid prompt template")
+
+// ParsePromptTemplate parses a dialogue prompt template and test-renders it against an empty
+// DialoguePromptData, so syntax errors and references to unknown fields are reported up front
+func ParsePromptTemplate(tmpl string) (*template.Template, error) {
+	t, err := template.New("dialogue").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPromptTemplate, err)
+	}
+	if err := t.Execute(io.Discard, DialoguePromptData{}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPromptTemplate, err)
+	}
+	return t, nil
+}
+
+// DialoguePromptData is the data a WithPromptTemplate template is rendered against
+type DialoguePromptData struct {
+	Name           string // the NPC's ID
+	Personality    string
+	Background     string
+	Relationships  map[string]string // entity -> relationship; nil when relationship context is disabled
+	Location       string
+	TimeOfDay      string
+	Weather        string
+	Environment    string
+	PlayerStats    string          // allowlisted PlayerStats as "key=value, ..." (see WithContextKeys)
+	GameState      string          // allowlisted GameState as "key=value, ..."
+	Memories       []string        // contents of the NPC's most important memories
+	HistorySummary string          // recap of History older than the window, if summarization is enabled
+	History        []DialogueEntry // entries within the history window
+	PlayerMessage  string
+	Language       string // name of the WithLanguage language, e.g. "Spanish"; empty when unset
+}
+
 // DialogueRequest contains context for generating dialogue
 type DialogueRequest struct {
 	PlayerMessage string
@@ -134,22 +470,30 @@ type GameContext struct {
 // GenerateDialogue creates contextual dialogue for the NPC
 func (npc *NPC) GenerateDialogue(ctx context.Context, req *DialogueRequest) (*DialogueResponse, error) {
 	// Build context-aware prompt
-	prompt := npc.buildDialoguePrompt(req)
-	model := ModelDialogueDefault
-	if npc.config != nil && npc.config.DialogueModel != "" { model = npc.config.DialogueModel }
-	llmReq := &theta_client.LLMRequest{ Model: model, Prompt: prompt, MaxTokens: DefaultDialogueMaxTokens, Temperature: 0.8 }
-	if model == "deepseek-chat" { llmReq.ResponseFormat = map[string]string{"type":"json_object"} }
-	llmResp, err := npc.engine.thetaClient.GenerateWithLLM(ctx, llmReq)
-	if err != nil { return nil, fmt.Errorf("failed to generate dialogue: %w", err) }
-	if len(llmResp.Choices) == 0 { return nil, fmt.Errorf("no dialogue generated") }
-	dialogue := llmResp.Choices[0].Text
-	response := &DialogueResponse{ Message: dialogue, Emotion: "neutral" }
+	if err := npc.promptTemplateError(); err != nil { return nil, err }
+	if err := npc.acquireDialogue(ctx); err != nil { return nil, err }
+	npc.summarizeHistory(ctx, req.History)
+	prompt, err := npc.renderDialoguePrompt(req)
+	if err != nil { return nil, err }
+	var actions []string
+	if npc.config != nil && len(npc.config.AllowedActions) > 0 {
+		prompt += actionSuggestionInstructions(npc.config.AllowedActions)
+	}
+	dialogue, err := npc.completeDialogue(ctx, prompt)
+	if err != nil { return nil, err }
+	if npc.config != nil && len(npc.config.AllowedActions) > 0 {
+		dialogue, actions = npc.parseActionReply(dialogue)
+	}
+	response := &DialogueResponse{ Message: dialogue, Emotion: EmotionNeutral, Actions: actions }
+	if npc.config != nil && npc.config.EnableEmotion {
+		response.Emotion = npc.detectEmotion(ctx, dialogue)
+	}
 	// Generate voice if enabled
 	if npc.config != nil && npc.config.EnableVoice {
 		if npc.config.VoiceModel == "" {
 			npc.config.VoiceModel = ModelVoiceDefault
 		}
-		if audioData, err := npc.generateVoice(ctx, dialogue); err == nil {
+		if audioData, err := npc.generateVoice(ctx, dialogue, response.Emotion); err == nil {
 			response.AudioData = audioData
 		}
 	}
@@ -160,61 +504,294 @@ func (npc *NPC) GenerateDialogue(ctx context.Context, req *DialogueRequest) (*Di
 	return response, nil
 }
 
-// GenerateDialogueStream streams dialogue chunks via callback. Returns final aggregated response.
-func (npc *NPC) GenerateDialogueStream(ctx context.Context, req *DialogueRequest, onChunk func(string)) (*DialogueResponse, error) {
-	prompt := npc.buildDialoguePrompt(req)
-	model := ModelDialogueDefault
-	if npc.config != nil && npc.config.DialogueModel != "" {
-		model = npc.config.DialogueModel
+// GenerateGroupDialogue has every NPC reply to the same request, as in a crowd scene or a council
+// reacting to one player action. At most DefaultGroupDialogueConcurrency NPCs generate at once and
+// each reply, including any emotion and voice calls, must finish within the dialogue timeout, so
+// one slow NPC cannot hold up the others. Responses and errors are aligned with npcs: for each
+// index exactly one of them is non-nil.
+func (e *Engine) GenerateGroupDialogue(ctx context.Context, npcs []*NPC, req *DialogueRequest) ([]*DialogueResponse, []error) {
+	responses := make([]*DialogueResponse, len(npcs))
+	errs := make([]error, len(npcs))
+	sem := make(chan struct{}, DefaultGroupDialogueConcurrency)
+	var wg sync.WaitGroup
+	for i, npc := range npcs {
+		if npc == nil {
+			errs[i] = fmt.Errorf("group dialogue: npc %d is nil", i)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, npc *NPC) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("npc %s: %w", npc.id, ctx.Err())
+				return
+			}
+			npcCtx, cancel := e.withCallTimeout(ctx, e.timeouts.dialogue)
+			defer cancel()
+			npcReq := *req // NPCs must not share one request value
+			resp, err := npc.GenerateDialogue(npcCtx, &npcReq)
+			if err != nil {
+				errs[i] = fmt.Errorf("npc %s: %w", npc.id, err)
+				return
+			}
+			responses[i] = resp
+		}(i, npc)
 	}
+	wg.Wait()
+	return responses, errs
+}
+
+// GenerateDialogueStream streams dialogue tokens as they arrive. The token channel closes when the
+// stream ends; the error channel then yields at most one error (including context cancellation) and
+// closes. Memory is formed from the fully assembled text once the stream completes successfully.
+// Streaming yields text only: it never synthesizes voice, even with WithVoice; pass the assembled
+// text to SpeakStream to voice it.
+func (npc *NPC) GenerateDialogueStream(ctx context.Context, req *DialogueRequest) (<-chan string, <-chan error) {
+	out := make(chan string, 32)
+	errOut := make(chan error, 1)
+	model := npc.dialogueModel()
 	llmReq := &theta_client.LLMRequest{
 		Model:       model,
-		Prompt:      prompt,
 		Stream:      true,
-		MaxTokens:   DefaultDialogueMaxTokens,
-		Temperature: 0.8,
-	}
-	ch, errCh := npc.engine.thetaClient.GenerateWithLLMStream(ctx, llmReq)
-	var full string
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case err := <-errCh:
-			if err != nil {
-				return nil, err
-			}
-			// channel closed cleanly
-			resp := &DialogueResponse{
-				Message: full,
-				Emotion: "neutral",
-			}
-			if npc.config != nil && npc.config.EnableVoice && full != "" {
-				if audio, e := npc.generateVoice(context.Background(), full); e == nil {
-					resp.AudioData = audio
+		MaxTokens:   npc.dialogueMaxTokens(),
+		Temperature: Float64(npc.dialogueTemperature()),
+	}
+	go func() {
+		defer close(errOut)
+		defer close(out)
+		if err := npc.promptTemplateError(); err != nil {
+			errOut <- err
+			return
+		}
+		if err := npc.acquireDialogue(ctx); err != nil {
+			errOut <- err
+			return
+		}
+		npc.summarizeHistory(ctx, req.History)
+		prompt, err := npc.renderDialoguePrompt(req)
+		if err != nil {
+			errOut <- err
+			return
+		}
+		llmReq.Prompt = prompt
+		ctx, cancel := npc.engine.withCallTimeout(ctx, npc.engine.timeouts.dialogue)
+		defer cancel()
+		ch, errCh := npc.engine.llm.GenerateWithLLMStream(ctx, llmReq)
+		cancelled := func() {
+			errOut <- ctx.Err()
+			go func() { for range ch {} }() // let the client goroutine finish
+		}
+		var full strings.Builder
+		for ch != nil {
+			select {
+			case <-ctx.Done():
+				cancelled()
+				return
+			case tok, ok := <-ch:
+				if !ok {
+					ch = nil
+					continue
+				}
+				if tok == "" {
+					continue
+				}
+				full.WriteString(tok)
+				select {
+				case out <- tok:
+				case <-ctx.Done():
+					cancelled()
+					return
 				}
 			}
-			if npc.engine.IsRedisEnabled() && req.PlayerMessage != "" && full != "" {
-				npc.addToMemory(req.PlayerMessage, full)
-			}
-			return resp, nil
-		case tok, ok := <-ch:
-			if !ok {
-				continue
+		}
+		if err := <-errCh; err != nil {
+			errOut <- fmt.Errorf("failed to stream dialogue: %w", err)
+			return
+		}
+		// Store in memory if Redis is available (same as GenerateDialogue)
+		if text := full.String(); npc.engine.IsRedisEnabled() && req.PlayerMessage != "" && text != "" {
+			npc.addToMemory(req.PlayerMessage, text)
+		}
+	}()
+	return out, errOut
+}
+
+// actionReply is the structured reply requested when action suggestions are enabled
+type actionReply struct {
+	Message string   `json:"message"`
+	Actions []string `json:"actions"`
+}
+
+func actionSuggestionInstructions(allowed []string) string {
+	return fmt.Sprintf(" You may also take in-world actions, chosen only from: %s. Reply with JSON only, in the form {\"message\": \"what you say\", \"actions\": [\"action\"]}, using an empty actions list if none apply.",
+		strings.Join(allowed, ", "))
+}
+
+// parseActionReply extracts the message and allowed actions from a structured reply. Unknown
+// actions are dropped; if no JSON can be parsed the raw text is the message and no actions are suggested.
+func (npc *NPC) parseActionReply(text string) (string, []string) {
+	var reply actionReply
+	if err := decodeJSONText(text, &reply); err != nil || strings.TrimSpace(reply.Message) == "" {
+		npc.engine.logger.Debugf("npc %s: reply was not structured, ignoring actions: %v", npc.id, err)
+		return text, nil
+	}
+	return strings.TrimSpace(reply.Message), filterActions(reply.Actions, npc.config.AllowedActions)
+}
+
+// filterActions keeps suggested actions found in allowed (case-insensitively), in allowed's spelling, without duplicates
+func filterActions(suggested, allowed []string) []string {
+	var kept []string
+	seen := make(map[string]bool)
+	for _, s := range suggested {
+		for _, a := range allowed {
+			if strings.EqualFold(strings.TrimSpace(s), a) && !seen[a] {
+				seen[a] = true
+				kept = append(kept, a)
 			}
-			if tok == "" {
-				continue
+		}
+	}
+	return kept
+}
+
+// supportedEmotions is the fixed label set used by emotion detection
+var supportedEmotions = []string{EmotionHappy, EmotionAngry, EmotionFearful, EmotionSad, EmotionNeutral, EmotionSurprised}
+
+// detectEmotion asks the dialogue model to label a reply; any failure falls back to neutral
+func (npc *NPC) detectEmotion(ctx context.Context, dialogue string) string {
+	prompt := fmt.Sprintf("Classify the emotion of this line spoken by a game character. Answer with exactly one word from: %s.\n\nLine: %q\n\nEmotion:",
+		strings.Join(supportedEmotions, ", "), dialogue)
+	llmReq := &theta_client.LLMRequest{ Model: npc.dialogueModel(), Prompt: prompt, MaxTokens: DefaultEmotionMaxTokens, Temperature: Float64(0.1) }
+	ctx, cancel := npc.engine.withCallTimeout(ctx, npc.engine.timeouts.dialogue)
+	defer cancel()
+	llmResp, err := npc.engine.llm.GenerateWithLLM(ctx, llmReq)
+	if err != nil || len(llmResp.Choices) == 0 {
+		npc.engine.logger.Debugf("npc %s: emotion detection failed: %v", npc.id, err)
+		return EmotionNeutral
+	}
+	return parseEmotion(llmResp.Choices[0].Text)
+}
+
+// parseEmotion returns the first supported emotion word in the classifier output, ignoring any
+// <think> reasoning block, or neutral if none is found
+func parseEmotion(text string) string {
+	if i := strings.LastIndex(text, "</think>"); i >= 0 {
+		text = text[i+len("</think>"):]
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return r < 'a' || r > 'z' })
+	for _, w := range words {
+		for _, e := range supportedEmotions {
+			if w == e {
+				return e
 			}
-			full += tok
-			if onChunk != nil {
-				onChunk(tok)
+		}
+	}
+	return EmotionNeutral
+}
+
+// completeDialogue runs the prompt against the dialogue model, retrying once with the
+// fallback model (if configured) when the primary errors or returns no choices
+func (npc *NPC) completeDialogue(ctx context.Context, prompt string) (string, error) {
+	models := []string{npc.dialogueModel()}
+	if npc.config != nil && npc.config.FallbackModel != "" && npc.config.FallbackModel != models[0] {
+		models = append(models, npc.config.FallbackModel)
+	}
+	var lastErr error
+	for i, model := range models {
+		llmReq := &theta_client.LLMRequest{ Model: model, Prompt: prompt, MaxTokens: npc.dialogueMaxTokens(), Temperature: Float64(npc.dialogueTemperature()) }
+		if model == "deepseek-chat" { llmReq.ResponseFormat = map[string]string{"type":"json_object"} }
+		callCtx, cancel := npc.engine.withCallTimeout(ctx, npc.engine.timeouts.dialogue)
+		llmResp, err := npc.engine.llm.GenerateWithLLM(callCtx, llmReq)
+		cancel()
+		switch {
+		case err != nil:
+			lastErr = fmt.Errorf("failed to generate dialogue: %w", err)
+		case len(llmResp.Choices) == 0:
+			lastErr = fmt.Errorf("no dialogue generated")
+		default:
+			if i > 0 {
+				npc.engine.logger.Infof("NPC %s dialogue generated by fallback model %s", npc.id, model)
+			} else {
+				npc.engine.logger.Debugf("NPC %s dialogue generated by %s", npc.id, model)
 			}
+			return llmResp.Choices[0].Text, nil
 		}
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(models)-1 {
+			npc.engine.logger.Warnf("NPC %s dialogue model %s failed, trying %s: %v", npc.id, model, models[i+1], lastErr)
+		}
+	}
+	return "", lastErr
+}
+
+func (npc *NPC) dialogueTemperature() float64 {
+	if npc.config != nil && npc.config.Temperature != nil {
+		return *npc.config.Temperature
+	}
+	return 0.8
+}
+
+func (npc *NPC) dialogueMaxTokens() int {
+	if npc.config != nil && npc.config.MaxTokens > 0 {
+		return npc.config.MaxTokens
+	}
+	return DefaultDialogueMaxTokens
+}
+
+// dialogueModel returns the configured dialogue model or the framework default
+func (npc *NPC) dialogueModel() string {
+	if npc.config != nil && npc.config.DialogueModel != "" {
+		return npc.config.DialogueModel
+	}
+	return ModelDialogueDefault
+}
+
+// PerceiveOption configures a single Perceive call
+type PerceiveOption func(*perceiveOptions)
+
+// perceiveOptions is the vision request plus the client-side filtering applied to its result
+type perceiveOptions struct {
+	req           *theta_client.VisionRequest
+	minConfidence *float64 // nil uses req.Threshold
+}
+
+// WithPerceiveClasses limits detection to the given class labels
+func WithPerceiveClasses(classes ...string) PerceiveOption {
+	return func(o *perceiveOptions) {
+		o.req.Classes = append(o.req.Classes, classes...)
+	}
+}
+
+// WithPerceiveThreshold sets the minimum detection confidence (0 uses the model default).
+// Detections below it are also dropped client-side unless WithPerceiveMinConfidence is set.
+func WithPerceiveThreshold(threshold float64) PerceiveOption {
+	return func(o *perceiveOptions) {
+		o.req.Threshold = threshold
+	}
+}
+
+// WithPerceiveMinConfidence drops detections below minConfidence from the result, overriding
+// the WithPerceiveThreshold default without changing what the model is asked for
+func WithPerceiveMinConfidence(minConfidence float64) PerceiveOption {
+	return func(o *perceiveOptions) {
+		o.minConfidence = &minConfidence
+	}
+}
+
+// WithPerceiveMaxResults caps the number of detections returned (0 means no limit)
+func WithPerceiveMaxResults(n int) PerceiveOption {
+	return func(o *perceiveOptions) {
+		o.req.MaxResults = n
 	}
 }
 
-// Perceive analyzes the visual environment using AI vision
-func (npc *NPC) Perceive(ctx context.Context, imageData []byte, query string) (*PerceptionResult, error) {
+// Perceive analyzes the visual environment using AI vision. Objects are ordered by descending
+// confidence, with detections below the minimum confidence dropped and at most MaxResults kept.
+func (npc *NPC) Perceive(ctx context.Context, imageData []byte, query string, opts ...PerceiveOption) (*PerceptionResult, error) {
 	if npc.config == nil || !npc.config.EnableVision {
 		return nil, fmt.Errorf("vision not enabled for this NPC")
 	}
@@ -223,6 +800,14 @@ func (npc *NPC) Perceive(ctx context.Context, imageData []byte, query string) (*
 		Image: imageData,
 		Query: query,
 	}
+	o := &perceiveOptions{req: visionReq}
+	for _, opt := range opts {
+		opt(o)
+	}
+	minConfidence := visionReq.Threshold
+	if o.minConfidence != nil {
+		minConfidence = *o.minConfidence
+	}
 
 	visionResp, err := npc.engine.thetaClient.AnalyzeVision(ctx, visionReq)
 	if err != nil {
@@ -241,10 +826,76 @@ func (npc *NPC) Perceive(ctx context.Context, imageData []byte, query string) (*
 
 	return &PerceptionResult{
 		Description: visionResp.Description,
-		Objects:     perceptions,
+		Objects:     rankPerceptions(perceptions, minConfidence, visionReq.MaxResults),
 	}, nil
 }
 
+// rankPerceptions drops perceptions below minConfidence, orders the rest by descending confidence
+// (ties keep the model's order) and keeps at most maxResults of them (0 keeps all)
+func rankPerceptions(perceptions []Perception, minConfidence float64, maxResults int) []Perception {
+	kept := perceptions[:0]
+	for _, p := range perceptions {
+		if p.Confidence >= minConfidence {
+			kept = append(kept, p)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Confidence > kept[j].Confidence })
+	if maxResults > 0 && len(kept) > maxResults {
+		kept = kept[:maxResults]
+	}
+	return kept
+}
+
+// PerceiveURL fetches the image at imageURL and analyzes it like Perceive. The download is
+// bounded by PerceiveFetchTimeout and MaxPerceiveImageBytes, and non-image responses are rejected.
+func (npc *NPC) PerceiveURL(ctx context.Context, imageURL, query string, opts ...PerceiveOption) (*PerceptionResult, error) {
+	if npc.config == nil || !npc.config.EnableVision {
+		return nil, fmt.Errorf("vision not enabled for this NPC")
+	}
+	imageData, err := fetchImage(ctx, imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image %s: %w", imageURL, err)
+	}
+	return npc.Perceive(ctx, imageData, query, opts...)
+}
+
+// fetchImage downloads an image, enforcing the perceive timeout, size limit and an image content type
+func fetchImage(ctx context.Context, imageURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, PerceiveFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > MaxPerceiveImageBytes {
+		return nil, fmt.Errorf("image too large: %d bytes (max %d)", resp.ContentLength, MaxPerceiveImageBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxPerceiveImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxPerceiveImageBytes {
+		return nil, fmt.Errorf("image too large: exceeds %d bytes", MaxPerceiveImageBytes)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "image/") {
+		return nil, fmt.Errorf("unsupported content type %q: expected an image", contentType)
+	}
+	return data, nil
+}
+
 // UpdateMemory adds new information to the NPC's memory
 func (npc *NPC) UpdateMemory(key string, value interface{}) {
 	npc.mu.Lock(); defer npc.mu.Unlock()
@@ -255,9 +906,57 @@ func (npc *NPC) UpdateMemory(key string, value interface{}) {
 	}
 }
 
+// UpdateMemoryWithMeta stores a ranked memory with an importance score and tags. Ranked
+// memories count toward the memory limit, and the most important ones are recalled in dialogue.
+func (npc *NPC) UpdateMemoryWithMeta(key string, value interface{}, importance float64, tags []string) {
+	content, ok := value.(string)
+	if !ok { content = fmt.Sprint(value) }
+	entry := NPCMemoryEntry{
+		ID:         key,
+		Content:    content,
+		Type:       "fact",
+		Importance: importance,
+		Tags:       append([]string(nil), tags...),
+		Metadata:   map[string]interface{}{"value": value},
+		CreatedAt:  time.Now().UnixNano(),
+	}
+	npc.mu.Lock()
+	npc.memory[key] = entry
+	evicted := npc.evictMemoryLocked()
+	npc.mu.Unlock()
+
+	if npc.engine.IsRedisEnabled() {
+		ctx := context.Background()
+		npc.engine.redisClient.Set(ctx, fmt.Sprintf("npc:%s:memory:%s", npc.id, key), entry, 24*time.Hour)
+		npc.deleteEvictedMemories(ctx, evicted)
+	}
+}
+
+// GetTopMemories returns up to n ranked memories, most important first (newest among equals).
+// n <= 0 returns all of them.
+func (npc *NPC) GetTopMemories(n int) []NPCMemoryEntry {
+	npc.mu.RLock()
+	entries := make([]NPCMemoryEntry, 0)
+	for _, v := range npc.memory {
+		if e, ok := v.(NPCMemoryEntry); ok { entries = append(entries, e) }
+	}
+	npc.mu.RUnlock()
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Importance != entries[j].Importance { return entries[i].Importance > entries[j].Importance }
+		if entries[i].CreatedAt != entries[j].CreatedAt { return entries[i].CreatedAt > entries[j].CreatedAt }
+		return entries[i].ID < entries[j].ID
+	})
+	if n > 0 && len(entries) > n { entries = entries[:n] }
+	return entries
+}
+
 // GetMemory retrieves information from the NPC's memory
 func (npc *NPC) GetMemory(key string) (interface{}, bool) {
 	npc.mu.RLock(); v, ok := npc.memory[key]; npc.mu.RUnlock()
+	if e, isEntry := v.(NPCMemoryEntry); ok && isEntry {
+		if value, has := e.Metadata["value"]; has { return value, true }
+		return e.Content, true
+	}
 	if ok { return v, true }
 	if npc.engine.IsRedisEnabled() {
 		memoryKey := fmt.Sprintf("npc:%s:memory:%s", npc.id, key)
@@ -289,7 +988,134 @@ type Perception struct {
 	Location   string
 }
 
-// buildDialoguePrompt creates a context-aware prompt for dialogue generation
+// renderRelationships renders each relationship as " Your relationship with <entity>: <relationship>."
+// in entity order
+func renderRelationships(rels map[string]string) string {
+	if len(rels) == 0 {
+		return ""
+	}
+	entities := make([]string, 0, len(rels))
+	for entity := range rels {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	var b strings.Builder
+	for _, entity := range entities {
+		b.WriteString(fmt.Sprintf(" Your relationship with %s: %s.", humanize(entity), humanize(rels[entity])))
+	}
+	return b.String()
+}
+
+// mentionedEntities returns the related entities referenced in msg as whole words (case-insensitive,
+// "_" matches a space), so "mayor" is not found in "mayoral"
+func mentionedEntities(rels map[string]string, msg string) []string {
+	low := strings.ToLower(msg)
+	var found []string
+	for entity := range rels {
+		name := strings.ToLower(entity)
+		if containsWord(low, name) || containsWord(low, humanize(name)) {
+			found = append(found, entity)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// containsWord reports whether word occurs in s with no letter or digit directly before or after it
+func containsWord(s, word string) bool {
+	if word == "" {
+		return false
+	}
+	isWordRune := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' }
+	for start := 0; start <= len(s)-len(word); {
+		i := strings.Index(s[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[i+len(word):])
+		if (i == 0 || !isWordRune(before)) && (i+len(word) == len(s) || !isWordRune(after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		start = i + size
+	}
+	return false
+}
+
+// humanize turns a relationship or entity key such as "reports_to" into prompt text
+func humanize(key string) string { return strings.ReplaceAll(key, "_", " ") }
+
+// renderContextValues formats the allowlisted keys present in values as "key=value" pairs
+func renderContextValues(values map[string]interface{}, keys []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v, ok := values[k]
+		if !ok || v == nil {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", k, textutil.Snippet(fmt.Sprintf("%v", v), MaxContextValueLen)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// promptTemplateError reports an invalid template given to WithPromptTemplate
+func (npc *NPC) promptTemplateError() error {
+	if npc.config == nil {
+		return nil
+	}
+	return npc.config.promptTemplateErr
+}
+
+// renderDialoguePrompt renders the configured prompt template, or the built-in prompt without one
+func (npc *NPC) renderDialoguePrompt(req *DialogueRequest) (string, error) {
+	if err := npc.promptTemplateError(); err != nil {
+		return "", err
+	}
+	if npc.config == nil || npc.config.PromptTemplate == nil {
+		return npc.buildDialoguePrompt(req), nil
+	}
+	var b strings.Builder
+	if err := npc.config.PromptTemplate.Execute(&b, npc.dialoguePromptData(req)); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidPromptTemplate, err)
+	}
+	return b.String(), nil
+}
+
+// dialoguePromptData gathers the values available to prompt templates
+func (npc *NPC) dialoguePromptData(req *DialogueRequest) DialoguePromptData {
+	data := DialoguePromptData{Name: npc.id, PlayerMessage: req.PlayerMessage}
+	if npc.config != nil {
+		data.Personality = npc.config.Personality
+		data.Background = npc.config.Background
+		if npc.config.Language != "" {
+			data.Language = languageName(npc.config.Language)
+		}
+		if !npc.config.DisableRelationshipContext {
+			data.Relationships = npc.config.Relationships
+		}
+	}
+	if c := req.Context; c != nil {
+		data.Location, data.TimeOfDay, data.Weather, data.Environment = c.Location, c.TimeOfDay, c.Weather, c.Environment
+		if npc.config != nil && len(npc.config.ContextKeys) > 0 {
+			data.PlayerStats = renderContextValues(c.PlayerStats, npc.config.ContextKeys)
+			data.GameState = renderContextValues(c.GameState, npc.config.ContextKeys)
+		}
+	}
+	for _, m := range npc.GetTopMemories(MaxPromptMemories) {
+		data.Memories = append(data.Memories, m.Content)
+	}
+	older, recent := npc.splitHistory(req.History)
+	data.HistorySummary, _ = npc.cachedHistorySummary(older)
+	data.History = recent
+	return data
+}
+
+// buildDialoguePrompt creates the built-in context-aware prompt for dialogue generation
 func (npc *NPC) buildDialoguePrompt(req *DialogueRequest) string {
 	prompt := fmt.Sprintf("You are %s.", npc.id)
 
@@ -300,6 +1126,9 @@ func (npc *NPC) buildDialoguePrompt(req *DialogueRequest) string {
 		if npc.config.Background != "" {
 			prompt += fmt.Sprintf(" Your background: %s.", npc.config.Background)
 		}
+		if !npc.config.DisableRelationshipContext {
+			prompt += renderRelationships(npc.config.Relationships)
+		}
 	}
 
 	if req.Context != nil {
@@ -312,33 +1141,239 @@ func (npc *NPC) buildDialoguePrompt(req *DialogueRequest) string {
 		if req.Context.Environment != "" {
 			prompt += fmt.Sprintf(" The environment: %s.", req.Context.Environment)
 		}
+		if npc.config != nil && len(npc.config.ContextKeys) > 0 {
+			if stats := renderContextValues(req.Context.PlayerStats, npc.config.ContextKeys); stats != "" {
+				prompt += fmt.Sprintf(" Player stats: %s.", stats)
+			}
+			if state := renderContextValues(req.Context.GameState, npc.config.ContextKeys); state != "" {
+				prompt += fmt.Sprintf(" Game state: %s.", state)
+			}
+		}
+	}
+
+	// Recall the NPC's most important memories
+	if top := npc.GetTopMemories(MaxPromptMemories); len(top) > 0 {
+		prompt += " You remember:"
+		for _, m := range top {
+			prompt += fmt.Sprintf(" %s.", strings.TrimSuffix(m.Content, "."))
+		}
 	}
 
-	// Add recent dialogue history
-	if len(req.History) > 0 {
+	// Add recent dialogue history, preceded by a recap of anything older than the window
+	older, recent := npc.splitHistory(req.History)
+	if summary, ok := npc.cachedHistorySummary(older); ok {
+		prompt += fmt.Sprintf(" Earlier in the conversation: %s", summary)
+	}
+	if len(recent) > 0 {
 		prompt += " Recent conversation:"
-		for _, entry := range req.History {
+		for _, entry := range recent {
 			prompt += fmt.Sprintf(" %s: %s", entry.Speaker, entry.Message)
 		}
 	}
 
+	// Surface relationships with entities the player mentions right before their message
+	if npc.config != nil && !npc.config.DisableRelationshipContext {
+		for _, entity := range mentionedEntities(npc.config.Relationships, req.PlayerMessage) {
+			prompt += fmt.Sprintf(" The player is talking about %s; remember your relationship with them: %s.", humanize(entity), humanize(npc.config.Relationships[entity]))
+		}
+	}
+
+	if npc.config != nil && npc.config.Language != "" {
+		prompt += fmt.Sprintf(" Always respond in %s, whatever language the player uses.", languageName(npc.config.Language))
+	}
+
 	prompt += fmt.Sprintf(" Player says: \"%s\" Respond naturally as the character:", req.PlayerMessage)
 
 	return prompt
 }
 
-// generateVoice creates speech audio for the given text
-func (npc *NPC) generateVoice(ctx context.Context, text string) ([]byte, error) {
-	if npc.config == nil || npc.config.VoiceModel == "" {
-		return nil, fmt.Errorf("voice model not configured")
+// historySummaryKey is the NPC memory key holding the cached recap of older history
+const historySummaryKey = "history_summary"
+
+// historySummary is a recap of the History entries identified by Fingerprint
+type historySummary struct {
+	Fingerprint uint64
+	Summary     string
+}
+
+// splitHistory separates the entries outside the configured window from the recent ones
+func (npc *NPC) splitHistory(history []DialogueEntry) ([]DialogueEntry, []DialogueEntry) {
+	if npc.config == nil || npc.config.HistoryWindow <= 0 || len(history) <= npc.config.HistoryWindow {
+		return nil, history
+	}
+	cut := len(history) - npc.config.HistoryWindow
+	return history[:cut], history[cut:]
+}
+
+func historyFingerprint(entries []DialogueEntry) uint64 {
+	h := fnv.New64a()
+	for _, e := range entries {
+		h.Write([]byte(e.Speaker))
+		h.Write([]byte{0})
+		h.Write([]byte(e.Message))
+		h.Write([]byte{0})
 	}
+	return h.Sum64()
+}
+
+// cachedHistorySummary returns the stored recap if it covers exactly the given older entries
+func (npc *NPC) cachedHistorySummary(older []DialogueEntry) (string, bool) {
+	if len(older) == 0 {
+		return "", false
+	}
+	npc.mu.RLock()
+	cached, ok := npc.memory[historySummaryKey].(historySummary)
+	npc.mu.RUnlock()
+	if !ok || cached.Fingerprint != historyFingerprint(older) {
+		return "", false
+	}
+	return cached.Summary, true
+}
+
+// summarizeHistory recaps the history outside the window when summarization is enabled and no
+// cached recap covers it yet. Failures are logged and the prompt falls back to the window alone.
+func (npc *NPC) summarizeHistory(ctx context.Context, history []DialogueEntry) {
+	if npc.config == nil || !npc.config.SummarizeHistory {
+		return
+	}
+	older, _ := npc.splitHistory(history)
+	if len(older) == 0 {
+		return
+	}
+	if _, ok := npc.cachedHistorySummary(older); ok {
+		return
+	}
+	var transcript strings.Builder
+	for _, e := range older {
+		fmt.Fprintf(&transcript, "%s: %s\n", e.Speaker, e.Message)
+	}
+	prompt := fmt.Sprintf("Summarize this conversation with %s in two or three sentences, keeping names, promises and facts the character should remember.\n\n%s\nSummary:", npc.id, transcript.String())
+	llmReq := &theta_client.LLMRequest{ Model: npc.dialogueModel(), Prompt: prompt, MaxTokens: DefaultSummaryMaxTokens, Temperature: Float64(0.3) }
+	callCtx, cancel := npc.engine.withCallTimeout(ctx, npc.engine.timeouts.dialogue)
+	defer cancel()
+	llmResp, err := npc.engine.llm.GenerateWithLLM(callCtx, llmReq)
+	if err != nil || len(llmResp.Choices) == 0 {
+		npc.engine.logger.Warnf("npc %s: history summarization failed: %v", npc.id, err)
+		return
+	}
+	summary := llmResp.Choices[0].Text
+	if i := strings.LastIndex(summary, "</think>"); i >= 0 {
+		summary = summary[i+len("</think>"):]
+	}
+	if summary = strings.TrimSpace(summary); summary == "" {
+		return
+	}
+	npc.UpdateMemory(historySummaryKey, historySummary{Fingerprint: historyFingerprint(older), Summary: summary})
+}
+
+// defaultVoiceStyles maps detected emotions to Kokoro voice styles
+var defaultVoiceStyles = map[string]string{
+	EmotionHappy:     theta_client.VoiceStyleFriendly,
+	"excited":        theta_client.VoiceStyleExcited,
+	EmotionSurprised: theta_client.VoiceStyleExcited,
+	EmotionAngry:     theta_client.VoiceStyleSerious,
+	EmotionSad:       theta_client.VoiceStyleSerious,
+	EmotionFearful:   theta_client.VoiceStyleMysterious,
+	EmotionNeutral:   theta_client.VoiceStyleNeutral,
+}
+
+// kokoroLanguageVoices maps language codes to the Kokoro voice that speaks them
+var kokoroLanguageVoices = map[string]string{
+	"en":    "af_heart",
+	"en-gb": "bf_emma",
+	"es":    "ef_dora",
+	"fr":    "ff_siwis",
+	"hi":    "hf_alpha",
+	"it":    "if_sara",
+	"ja":    "jf_alpha",
+	"pt":    "pf_dora",
+	"zh":    "zf_xiaobei",
+}
+
+// languageNames maps language codes to the names used in dialogue prompts
+var languageNames = map[string]string{
+	"ar": "Arabic", "de": "German", "en": "English", "es": "Spanish", "fr": "French", "hi": "Hindi",
+	"it": "Italian", "ja": "Japanese", "ko": "Korean", "nl": "Dutch", "pl": "Polish", "pt": "Portuguese",
+	"ru": "Russian", "tr": "Turkish", "uk": "Ukrainian", "zh": "Chinese",
+}
 
-	ttsReq := &theta_client.TTSRequest{
-		Text:  text,
-		Voice: npc.config.VoiceModel,
+// normalizeLanguage lowercases a language code and uses "-" between its subtags ("pt_BR" -> "pt-br")
+func normalizeLanguage(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+}
+
+// lookupLanguage finds a normalized code in m, falling back from a regional code to its base language
+func lookupLanguage(m map[string]string, code string) (string, bool) {
+	if v, ok := m[code]; ok {
+		return v, true
+	}
+	base, _, _ := strings.Cut(code, "-")
+	v, ok := m[base]
+	return v, ok
+}
+
+// languageName names a normalized code for prompts, using the code itself when it is unknown
+func languageName(code string) string {
+	if name, ok := lookupLanguage(languageNames, code); ok {
+		return name
 	}
+	return fmt.Sprintf("the language with code %q", code)
+}
 
-	ttsResp, err := npc.engine.thetaClient.GenerateVoice(ctx, ttsReq)
+// voiceStyle picks the voice style for an emotion, preferring the NPC's custom mapping
+func (npc *NPC) voiceStyle(emotion string) string {
+	emotion = strings.ToLower(emotion)
+	if npc.config != nil {
+		if style, ok := npc.config.VoiceStyles[emotion]; ok {
+			return style
+		}
+	}
+	if style, ok := defaultVoiceStyles[emotion]; ok {
+		return style
+	}
+	return theta_client.VoiceStyleNeutral
+}
+
+// ttsRequest builds the speech request for text in the NPC's voice and language
+func (npc *NPC) ttsRequest(text, emotion string) *theta_client.TTSRequest {
+	req := &theta_client.TTSRequest{
+		Text:     text,
+		Voice:    npc.config.VoiceModel,
+		Style:    npc.voiceStyle(emotion),
+		Language: npc.config.Language,
+	}
+	if req.Voice == ModelVoiceDefault {
+		if voice, ok := lookupLanguage(kokoroLanguageVoices, npc.config.Language); ok {
+			req.Voice = voice
+		}
+	}
+	return req
+}
+
+// SpeakStream streams synthesized speech for text in the NPC's voice, styled after emotion
+// (empty for the default style), so playback can begin before synthesis finishes
+func (npc *NPC) SpeakStream(ctx context.Context, text, emotion string) (<-chan []byte, <-chan error) {
+	if npc.config == nil || !npc.config.EnableVoice {
+		out := make(chan []byte)
+		errCh := make(chan error, 1)
+		close(out)
+		errCh <- fmt.Errorf("voice not enabled for this NPC")
+		close(errCh)
+		return out, errCh
+	}
+	if npc.config.VoiceModel == "" {
+		npc.config.VoiceModel = ModelVoiceDefault
+	}
+	return npc.engine.thetaClient.GenerateVoiceStream(ctx, npc.ttsRequest(text, emotion))
+}
+
+// generateVoice creates speech audio for the given text, styled after the speaker's emotion
+func (npc *NPC) generateVoice(ctx context.Context, text, emotion string) ([]byte, error) {
+	if npc.config == nil || npc.config.VoiceModel == "" {
+		return nil, fmt.Errorf("voice model not configured")
+	}
+
+	ttsResp, err := npc.engine.thetaClient.GenerateVoice(ctx, npc.ttsRequest(text, emotion))
 	if err != nil {
 		return nil, err
 	}
@@ -349,22 +1384,147 @@ func (npc *NPC) generateVoice(ctx context.Context, text string) ([]byte, error)
 // addToMemory stores dialogue in memory (local and Redis if available)
 func (npc *NPC) addToMemory(playerMessage, npcResponse string) {
 	if playerMessage == "" && npcResponse == "" { return }
-	timestamp := time.Now().Unix()
+	now := time.Now()
 	npc.mu.Lock()
-	// enforce memory limit exactly after inserts
-	entries := []struct{ k string; ts int64 }{}
-	for k,val := range npc.memory { if de, ok := val.(DialogueEntry); ok { entries = append(entries, struct{ k string; ts int64 }{k, de.Timestamp.Unix()}) } }
-	// insert new
-	npc.memory[fmt.Sprintf("dialogue_%d", timestamp)] = DialogueEntry{Speaker: "player", Message: playerMessage, Timestamp: time.Unix(timestamp,0)}
-	npc.memory[fmt.Sprintf("response_%d", timestamp)] = DialogueEntry{Speaker: npc.id, Message: npcResponse, Timestamp: time.Unix(timestamp,0)}
-	if len(npc.memory) > DefaultMaxNPCMemory {
-		// remove oldest until within limit
-		keys := make([]string,0,len(npc.memory))
-		for k := range npc.memory { keys = append(keys,k) }
-		sort.Strings(keys) // chronological because key embeds unix ts
-		for len(npc.memory) > DefaultMaxNPCMemory { delete(npc.memory, keys[0]); keys = keys[1:] }
+	// nanosecond keys keep exchanges within the same second distinct
+	ts := now.UnixNano()
+	for { if _, taken := npc.memory[fmt.Sprintf("dialogue_%d", ts)]; !taken { break }; ts++ }
+	added := map[string]DialogueEntry{
+		fmt.Sprintf("dialogue_%d", ts): {Speaker: "player", Message: playerMessage, Timestamp: now},
+		fmt.Sprintf("response_%d", ts): {Speaker: npc.id, Message: npcResponse, Timestamp: now},
 	}
+	for k, de := range added { npc.memory[k] = de }
+	evicted := npc.evictMemoryLocked()
 	npc.mu.Unlock()
+
+	if npc.engine.IsRedisEnabled() {
+		ctx := context.Background()
+		for k, de := range added {
+			npc.engine.redisClient.Set(ctx, fmt.Sprintf("npc:%s:memory:%s", npc.id, k), de, 24*time.Hour)
+		}
+		npc.deleteEvictedMemories(ctx, evicted)
+	}
+}
+
+// deleteEvictedMemories removes evicted memory keys from Redis
+func (npc *NPC) deleteEvictedMemories(ctx context.Context, evicted []string) {
+	if len(evicted) == 0 { return }
+	keys := make([]string, 0, len(evicted))
+	for _, k := range evicted { keys = append(keys, fmt.Sprintf("npc:%s:memory:%s", npc.id, k)) }
+	if err := npc.engine.redisClient.Delete(ctx, keys...); err != nil {
+		npc.engine.logger.Warnf("npc %s: failed to delete evicted memories: %v", npc.id, err)
+	}
+}
+
+// evictMemoryLocked drops dialogue entries and ranked memories beyond the memory limit, lowest
+// importance first (dialogue counts as DefaultMemoryImportance) and oldest among equals, and
+// returns their keys. Facts stored via UpdateMemory are not counted or evicted. Caller must hold npc.mu.
+func (npc *NPC) evictMemoryLocked() []string {
+	limit := DefaultMaxNPCMemory
+	if npc.config != nil && npc.config.MemoryLimit > 0 { limit = npc.config.MemoryLimit }
+	type entry struct { key string; importance float64; ts time.Time }
+	entries := []entry{}
+	for k, val := range npc.memory {
+		switch e := val.(type) {
+		case DialogueEntry:
+			entries = append(entries, entry{k, DefaultMemoryImportance, e.Timestamp})
+		case NPCMemoryEntry:
+			entries = append(entries, entry{k, e.Importance, time.Unix(0, e.CreatedAt)})
+		}
+	}
+	if len(entries) <= limit { return nil }
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].importance != entries[j].importance { return entries[i].importance < entries[j].importance }
+		if !entries[i].ts.Equal(entries[j].ts) { return entries[i].ts.Before(entries[j].ts) }
+		return entries[i].key < entries[j].key
+	})
+	evicted := make([]string, 0, len(entries)-limit)
+	for _, e := range entries[:len(entries)-limit] {
+		delete(npc.memory, e.key)
+		evicted = append(evicted, e.key)
+	}
+	return evicted
+}
+
+// ErrRedisNotEnabled is returned by operations that require Redis persistence
+var ErrRedisNotEnabled = errors.New("redis not enabled")
+
+// ErrNoSnapshot is returned by LoadSnapshot when no snapshot has been saved for the NPC
+var ErrNoSnapshot = errors.New("no NPC snapshot found")
+
+// NPCSnapshot is the persisted form of an NPC's memory, state and personality.
+// Dialogue entries and ranked memories are kept apart from other memory so they keep their type across a round-trip.
+type NPCSnapshot struct {
+	ID          string                   `json:"id"`
+	Memory      map[string]interface{}   `json:"memory"`
+	Dialogue    map[string]DialogueEntry `json:"dialogue"`
+	Ranked      map[string]NPCMemoryEntry `json:"ranked,omitempty"`
+	State       map[string]interface{}   `json:"state"`
+	Personality map[string]interface{}   `json:"personality"`
+	SavedAt     time.Time                `json:"saved_at"`
+}
+
+// SaveSnapshot writes the NPC's full memory, state and personality to Redis (npc:snapshot:<id>)
+func (npc *NPC) SaveSnapshot(ctx context.Context) error {
+	if !npc.engine.IsRedisEnabled() {
+		return fmt.Errorf("failed to save snapshot for npc %s: %w", npc.id, ErrRedisNotEnabled)
+	}
+	npc.mu.RLock()
+	snap := NPCSnapshot{
+		ID:          npc.id,
+		Memory:      make(map[string]interface{}, len(npc.memory)),
+		Dialogue:    make(map[string]DialogueEntry),
+		Ranked:      make(map[string]NPCMemoryEntry),
+		State:       make(map[string]interface{}, len(npc.state)),
+		Personality: make(map[string]interface{}, len(npc.personality)),
+		SavedAt:     time.Now(),
+	}
+	for k, v := range npc.memory {
+		switch e := v.(type) {
+		case DialogueEntry:
+			snap.Dialogue[k] = e
+		case NPCMemoryEntry:
+			snap.Ranked[k] = e
+		default:
+			snap.Memory[k] = v
+		}
+	}
+	for k, v := range npc.state { snap.State[k] = v }
+	for k, v := range npc.personality { snap.Personality[k] = v }
+	npc.mu.RUnlock()
+
+	if err := npc.engine.redisClient.StoreNPCSnapshot(ctx, npc.id, snap); err != nil {
+		return fmt.Errorf("failed to save snapshot for npc %s: %w", npc.id, err)
+	}
+	return nil
+}
+
+// LoadSnapshot replaces the NPC's memory, state and personality with the snapshot stored in Redis.
+// It returns ErrNoSnapshot if none has been saved.
+func (npc *NPC) LoadSnapshot(ctx context.Context) error {
+	if !npc.engine.IsRedisEnabled() {
+		return fmt.Errorf("failed to load snapshot for npc %s: %w", npc.id, ErrRedisNotEnabled)
+	}
+	var snap NPCSnapshot
+	found, err := npc.engine.redisClient.GetNPCSnapshot(ctx, npc.id, &snap)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot for npc %s: %w", npc.id, err)
+	}
+	if !found {
+		return ErrNoSnapshot
+	}
+
+	npc.mu.Lock()
+	defer npc.mu.Unlock()
+	npc.memory = make(map[string]interface{}, len(snap.Memory)+len(snap.Dialogue)+len(snap.Ranked))
+	for k, v := range snap.Memory { npc.memory[k] = v }
+	for k, de := range snap.Dialogue { npc.memory[k] = de }
+	for k, e := range snap.Ranked { npc.memory[k] = e }
+	npc.state = snap.State
+	if npc.state == nil { npc.state = make(map[string]interface{}) }
+	npc.personality = snap.Personality
+	if npc.personality == nil { npc.personality = make(map[string]interface{}) }
+	return nil
 }
 
 // Config returns the NPC's configuration, creating it if necessary
@@ -0,0 +1,290 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/emergent-world-engine/backend/internal/theta_client"
+	"github.com/emergent-world-engine/backend/pkg/jsonextract"
+	"github.com/emergent-world-engine/backend/pkg/textutil"
+)
+
+// LLMRequest is the text generation request passed to an LLMProvider
+type LLMRequest = theta_client.LLMRequest
+
+// Float64 returns a pointer to v, for optional request fields such as LLMRequest.Temperature
+func Float64(v float64) *float64 { return &v }
+
+// LLMResponse is the completion returned by an LLMProvider
+type LLMResponse = theta_client.LLMResponse
+
+// LLMChoice is a single completion choice within an LLMResponse
+type LLMChoice = theta_client.Choice
+
+// APIError is an error status returned by the Theta API; recover it with errors.As
+type APIError = theta_client.APIError
+
+// Error classes for Theta failures, matched with errors.Is on errors returned by the engine
+var (
+	ErrRateLimited = theta_client.ErrRateLimited
+	ErrServerError = theta_client.ErrServerError
+	ErrDecode      = theta_client.ErrDecode
+)
+
+// LLMProvider generates text for NPCs, the director and the narrative engine
+type LLMProvider interface {
+	GenerateWithLLM(ctx context.Context, req *LLMRequest) (*LLMResponse, error)
+	GenerateWithLLMStream(ctx context.Context, req *LLMRequest) (<-chan string, <-chan error)
+}
+
+// ThetaProvider stands for the engine's built-in Theta client inside Config.Providers, placing it
+// in the fallback order
+var ThetaProvider LLMProvider = thetaProviderRef{}
+
+type thetaProviderRef struct{}
+
+func (thetaProviderRef) GenerateWithLLM(ctx context.Context, req *LLMRequest) (*LLMResponse, error) {
+	return nil, fmt.Errorf("ThetaProvider must be used through an engine")
+}
+
+func (thetaProviderRef) GenerateWithLLMStream(ctx context.Context, req *LLMRequest) (<-chan string, <-chan error) {
+	errCh := make(chan error, 1)
+	errCh <- fmt.Errorf("ThetaProvider must be used through an engine")
+	close(errCh)
+	out := make(chan string)
+	close(out)
+	return out, errCh
+}
+
+// EngineOption configures an Engine
+type EngineOption func(*Engine)
+
+// WithProviders replaces Config.Providers; the Theta client stays in the chain unless
+// Config.ExcludeTheta is set
+func WithProviders(providers ...LLMProvider) EngineOption {
+	return func(e *Engine) {
+		e.providers = append([]LLMProvider(nil), providers...)
+	}
+}
+
+// providerChain tries each provider in order and returns the first successful result
+type providerChain struct {
+	providers []LLMProvider
+	logger    Logger
+}
+
+// buildProviderChain resolves ThetaProvider to the engine's client, puts Theta first when the list
+// doesn't place it and drops it when the config excludes it
+func (e *Engine) buildProviderChain() (*providerChain, error) {
+	exclude := e.config.ExcludeTheta
+	providers := make([]LLMProvider, 0, len(e.providers)+1)
+	placed := false
+	for _, p := range e.providers {
+		if p == nil {
+			continue
+		}
+		if _, ok := p.(thetaProviderRef); ok {
+			if exclude || placed {
+				continue
+			}
+			p, placed = e.thetaClient, true
+		}
+		providers = append(providers, p)
+	}
+	if !exclude && !placed {
+		providers = append([]LLMProvider{e.thetaClient}, providers...)
+	}
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no LLM providers: ExcludeTheta requires Config.Providers")
+	}
+	return &providerChain{providers: providers, logger: e.logger}, nil
+}
+
+func (pc *providerChain) GenerateWithLLM(ctx context.Context, req *LLMRequest) (*LLMResponse, error) {
+	var errs []error
+	for i, p := range pc.providers {
+		resp, err := p.GenerateWithLLM(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(pc.providers)-1 {
+			pc.logger.Warnf("LLM provider %d failed, trying next: %v", i, err)
+		}
+	}
+	return nil, fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...))
+}
+
+// GenerateWithLLMStream falls back to the next provider only if the current one fails
+// before producing its first token; once output has started the stream is committed.
+func (pc *providerChain) GenerateWithLLMStream(ctx context.Context, req *LLMRequest) (<-chan string, <-chan error) {
+	out := make(chan string, 16)
+	outErr := make(chan error, 1)
+	go func() {
+		defer close(out)
+		defer close(outErr)
+		var errs []error
+		for i, p := range pc.providers {
+			ch, errCh := p.GenerateWithLLMStream(ctx, req)
+			started, err := forwardStream(ctx, ch, errCh, out)
+			if err == nil {
+				return
+			}
+			if started || ctx.Err() != nil {
+				outErr <- err
+				return
+			}
+			errs = append(errs, err)
+			if i < len(pc.providers)-1 {
+				pc.logger.Warnf("LLM provider %d stream failed, trying next: %v", i, err)
+			}
+		}
+		outErr <- fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...))
+	}()
+	return out, outErr
+}
+
+// forwardStream copies tokens to out until the stream ends and reports whether any token was sent
+func forwardStream(ctx context.Context, ch <-chan string, errCh <-chan error, out chan<- string) (bool, error) {
+	started := false
+	for ch != nil || errCh != nil {
+		select {
+		case <-ctx.Done():
+			return started, ctx.Err()
+		case tok, ok := <-ch:
+			if !ok {
+				ch = nil
+				continue
+			}
+			started = true
+			select {
+			case out <- tok:
+			case <-ctx.Done():
+				return started, ctx.Err()
+			}
+		case err, ok := <-errCh:
+			if !ok {
+				errCh = nil
+				continue
+			}
+			if err != nil {
+				return started, err
+			}
+		}
+	}
+	return started, nil
+}
+
+// unmarshalRepaired decodes text as JSON, retrying once on the jsonextract.Repair version of text
+func unmarshalRepaired(text string, dest interface{}) error {
+	err := json.Unmarshal([]byte(text), dest)
+	if err == nil {
+		return nil
+	}
+	if repaired := jsonextract.Repair(text); repaired != text && json.Unmarshal([]byte(repaired), dest) == nil {
+		return nil
+	}
+	return err
+}
+
+// clampTemperature limits a sampling temperature to [MinTemperature, MaxTemperature]
+func clampTemperature(t float64) float64 {
+	return min(max(t, MinTemperature), MaxTemperature)
+}
+
+// ErrInvalidJSON is returned by GenerateJSON when neither attempt produced JSON that decodes
+var ErrInvalidJSON = errors.New("invalid JSON response")
+
+// textResponseFormat keeps a JSON-decoded request in free-text mode, for prompts whose trailing
+// JSON follows a narrative that json_object mode would suppress
+var textResponseFormat = map[string]string{"type": "text"}
+
+// jsonRetryReminder is appended to the prompt when the first completion was not valid JSON
+const jsonRetryReminder = "\n\nReturn valid JSON only, with no prose, commentary or markdown fences."
+
+// GenerateJSON runs req through the engine's LLM providers and decodes the completion into T.
+// When T is a struct or map and req sets no ResponseFormat, json_object mode is requested. JSON
+// embedded in prose or code fences is extracted; if nothing decodes, the request is retried once
+// with a reminder to return JSON only, and ErrInvalidJSON is returned if that fails too.
+func GenerateJSON[T any](ctx context.Context, e *Engine, req *LLMRequest) (T, error) {
+	out, _, err := generateJSON[T](ctx, e, req)
+	return out, err
+}
+
+// generateJSON is GenerateJSON that also returns the last completion text, so callers can read
+// the narrative around the JSON or fall back to the raw text on ErrInvalidJSON
+func generateJSON[T any](ctx context.Context, e *Engine, req *LLMRequest) (T, string, error) {
+	var zero T
+	attempt := *req
+	if attempt.ResponseFormat == nil && isJSONObjectType[T]() {
+		attempt.ResponseFormat = map[string]string{"type": "json_object"}
+	}
+	var lastErr error
+	text := ""
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			attempt.Prompt = req.Prompt + jsonRetryReminder
+		}
+		resp, err := e.llm.GenerateWithLLM(ctx, &attempt)
+		if err != nil {
+			return zero, "", fmt.Errorf("failed to generate JSON: %w", err)
+		}
+		if len(resp.Choices) == 0 {
+			lastErr = fmt.Errorf("no completion generated")
+			continue
+		}
+		text = resp.Choices[0].Text
+		var out T
+		if lastErr = decodeJSONText(text, &out); lastErr == nil {
+			return out, text, nil
+		}
+		e.logger.Debugf("GenerateJSON: attempt %d returned invalid JSON: %v", i+1, lastErr)
+	}
+	return zero, text, fmt.Errorf("%w: %w", ErrInvalidJSON, lastErr)
+}
+
+// isJSONObjectType reports whether T (through pointers) decodes from a JSON object, the only
+// top-level value json_object mode allows
+func isJSONObjectType[T any]() bool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+}
+
+// decodeJSONText decodes the JSON value in a model completion, tolerating reasoning
+// preambles, code fences and surrounding prose, with jsonextract.Repair as the last resort
+func decodeJSONText(text string, dest interface{}) error {
+	if idx := strings.LastIndex(text, "</think>"); idx >= 0 {
+		text = text[idx+len("</think>"):]
+	}
+	trimmed := strings.TrimSpace(text)
+	trimmed = strings.TrimPrefix(trimmed, "```json")
+	trimmed = jsonextract.TrimFence(trimmed)
+	err := json.Unmarshal([]byte(trimmed), dest)
+	if err == nil {
+		return nil
+	}
+	if start, end, _ := jsonextract.LastObject(trimmed); start >= 0 {
+		if json.Unmarshal([]byte(trimmed[start:end+1]), dest) == nil {
+			return nil
+		}
+	}
+	if start, end := strings.Index(trimmed, "["), strings.LastIndex(trimmed, "]"); start >= 0 && end > start {
+		if json.Unmarshal([]byte(trimmed[start:end+1]), dest) == nil {
+			return nil
+		}
+	}
+	if json.Unmarshal([]byte(jsonextract.Repair(trimmed)), dest) == nil {
+		return nil
+	}
+	return fmt.Errorf("no valid JSON in completion %q: %w", textutil.Snippet(trimmed, 120), err)
+}
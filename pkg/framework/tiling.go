@@ -0,0 +1,162 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	_ "image/jpeg" // decode JPEG textures returned by image models
+)
+
+// TileSeamTolerance is the largest TileSeamScore a texture may have and still count as tileable
+const TileSeamTolerance = 2.0
+
+// TileSeamScore measures how visible the seams are when img is tiled: the mean color difference
+// across the wrap-around edges (left against right column, top against bottom row) divided by the
+// mean difference between neighboring interior pixels. Noisy and flat textures that tile cleanly
+// score around 1 or below; a gradient or a hard edge at the border scores far higher.
+func TileSeamScore(img image.Image) float64 {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w < 2 || h < 2 {
+		return 0
+	}
+	px := func(x, y int) color.Color { return img.At(b.Min.X+x, b.Min.Y+y) }
+
+	var seam float64
+	for y := 0; y < h; y++ {
+		seam += colorDistance(px(0, y), px(w-1, y))
+	}
+	for x := 0; x < w; x++ {
+		seam += colorDistance(px(x, 0), px(x, h-1))
+	}
+	seam /= float64(w + h)
+
+	var interior float64
+	var n int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x+1 < w {
+				interior += colorDistance(px(x, y), px(x+1, y))
+				n++
+			}
+			if y+1 < h {
+				interior += colorDistance(px(x, y), px(x, y+1))
+				n++
+			}
+		}
+	}
+	interior /= float64(n)
+	// Smooth images have near-zero interior differences; floor them at one 8-bit step
+	return seam / math.Max(interior, 1.0/255)
+}
+
+// IsTileable reports whether img tiles without visible seams (see TileSeamScore)
+func IsTileable(img image.Image) bool {
+	return TileSeamScore(img) <= TileSeamTolerance
+}
+
+// MakeTileable mirror-blends img so it tiles seamlessly: within a band a quarter of the image wide
+// along each border, pixels are blended with their mirror image across the center, reaching an even
+// mix at the border itself. Opposite edges then match exactly while the center is left untouched.
+func MakeTileable(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			src.Set(x, y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	horiz := image.NewRGBA(src.Rect)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			horiz.SetRGBA(x, y, blendRGBA(src.RGBAAt(x, y), src.RGBAAt(w-1-x, y), mirrorWeight(x, w)))
+		}
+	}
+	out := image.NewRGBA(src.Rect)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			out.SetRGBA(x, y, blendRGBA(horiz.RGBAAt(x, y), horiz.RGBAAt(x, h-1-y), mirrorWeight(y, h)))
+		}
+	}
+	return out
+}
+
+// mirrorWeight is the share of the mirrored pixel at position i of n: 0.5 on the border, fading to 0
+// a quarter of the way in
+func mirrorWeight(i, n int) float64 {
+	band := float64(n) / 4
+	d := float64(min(i, n-1-i))
+	if band < 1 || d >= band {
+		return 0
+	}
+	return 0.5 * (1 - d/band)
+}
+
+func blendRGBA(a, m color.RGBA, wm float64) color.RGBA {
+	mix := func(p, q uint8) uint8 { return uint8(math.Round(float64(p)*(1-wm) + float64(q)*wm)) }
+	return color.RGBA{R: mix(a.R, m.R), G: mix(a.G, m.G), B: mix(a.B, m.B), A: mix(a.A, m.A)}
+}
+
+// colorDistance is the mean absolute per-channel difference of two colors, in [0, 1]
+func colorDistance(c1, c2 color.Color) float64 {
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+	d := func(p, q uint32) float64 { return math.Abs(float64(p)-float64(q)) / 0xffff }
+	return (d(r1, r2) + d(g1, g2) + d(b1, b2) + d(a1, a2)) / 4
+}
+
+// verifyTiling checks a generated texture's seams, mirror-blending it when they show. It records
+// tile_seam_score (before any blending), tileable_verified and tileable_blended in the asset's
+// metadata; a texture that cannot be fetched or decoded is left as is with tileable_verified false.
+// A blended texture lives only in Data: URL is cleared and the seamed original kept as source_url.
+func (ag *AssetGenerator) verifyTiling(ctx context.Context, asset *Asset) {
+	asset.Metadata["tileable_verified"] = false
+	data := asset.Data
+	if len(data) == 0 && asset.URL != "" {
+		downloaded, err := downloadAsset(ctx, asset.URL)
+		if err != nil {
+			ag.engine.logger.Warnf("texture %s: tiling check skipped, download failed: %v", asset.ID, err)
+			return
+		}
+		data = downloaded
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		ag.engine.logger.Warnf("texture %s: tiling check skipped, decode failed: %v", asset.ID, err)
+		return
+	}
+	score := TileSeamScore(img)
+	asset.Metadata["tile_seam_score"] = score
+	if score <= TileSeamTolerance {
+		asset.Metadata["tileable_verified"] = true
+		return
+	}
+
+	tiled := MakeTileable(img)
+	blended, err := encodePNG(tiled)
+	if err != nil {
+		ag.engine.logger.Warnf("texture %s: mirror blend failed: %v", asset.ID, err)
+		return
+	}
+	asset.Data, asset.Format = blended, "png"
+	if asset.URL != "" {
+		asset.Metadata["source_url"] = asset.URL
+		asset.URL = ""
+	}
+	asset.Metadata["tileable_blended"] = true
+	asset.Metadata["tileable_verified"] = IsTileable(tiled)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,194 @@
+// Package jsonextract locates JSON objects embedded in free-form model output: narratives followed
+// by a JSON block, objects wrapped in code fences, and completions cut off mid-object. Repair fixes
+// the quoting and comma mistakes models make inside them.
+package jsonextract
+
+import "strings"
+
+// MatchBrace returns the index of the '}' closing the '{' at open, ignoring braces inside strings
+// and escaped quotes. ok is false when s[open] is not '{' or the object is never closed.
+func MatchBrace(s string, open int) (int, bool) {
+	if open < 0 || open >= len(s) || s[open] != '{' {
+		return -1, false
+	}
+	return matchClose(s, open)
+}
+
+// matchClose returns the index closing the object or array at open, counting both bracket kinds
+// outside strings
+func matchClose(s string, open int) (int, bool) {
+	depth := 0
+	inStr, esc := false, false
+	for i := open; i < len(s); i++ {
+		ch := s[i]
+		if inStr {
+			switch {
+			case esc:
+				esc = false
+			case ch == '\\':
+				esc = true
+			case ch == '"':
+				inStr = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inStr = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i, true
+			}
+		}
+	}
+	return -1, false
+}
+
+// LastObject returns the bounds of the last complete top-level JSON object in s (start -1 if none)
+// and the start of a trailing object that was never closed (openAt -1 if none). Quotes in the prose
+// around objects are not treated as strings, so quoted words in a narrative cannot hide its JSON.
+func LastObject(s string) (start, end, openAt int) {
+	start, end, openAt = -1, -1, -1
+	depth, objStart := 0, -1
+	inStr, esc := false, false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if inStr {
+			switch {
+			case esc:
+				esc = false
+			case ch == '\\':
+				esc = true
+			case ch == '"':
+				inStr = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			if depth > 0 {
+				inStr = true
+			}
+		case '{':
+			if depth == 0 {
+				objStart = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				start, end = objStart, i
+			}
+		}
+	}
+	if depth > 0 {
+		openAt = objStart
+	}
+	return start, end, openAt
+}
+
+// LastKey returns the index of the last quoted occurrence of the first of keys found in s, matched
+// case-insensitively, together with the length of the quoted key. Keys are tried in order, so
+// LastKey(s, "impacts", "impact") only falls back to "impact" when "impacts" never appears.
+func LastKey(s string, keys ...string) (idx, n int) {
+	low := strings.ToLower(s)
+	for _, k := range keys {
+		q := `"` + strings.ToLower(k) + `"`
+		if i := strings.LastIndex(low, q); i >= 0 {
+			return i, len(q)
+		}
+	}
+	return -1, 0
+}
+
+// EnclosingObject returns the innermost balanced object containing the last occurrence of keys
+// (see LastKey); objects that close before the key, like a brace inside a string, are skipped
+func EnclosingObject(s string, keys ...string) (string, bool) {
+	idx, _ := LastKey(s, keys...)
+	if idx < 0 {
+		return "", false
+	}
+	for open := strings.LastIndex(s[:idx], "{"); open >= 0; open = strings.LastIndex(s[:open], "{") {
+		if end, ok := MatchBrace(s, open); ok && end > idx {
+			return s[open : end+1], true
+		}
+	}
+	return "", false
+}
+
+// LastObjectWithKey returns the last complete top-level object whose text mentions any of keys
+func LastObjectWithKey(s string, keys ...string) (string, bool) {
+	last := ""
+	for i := 0; i < len(s); i++ {
+		if s[i] != '{' {
+			continue
+		}
+		end, ok := MatchBrace(s, i)
+		if !ok {
+			continue
+		}
+		frag := s[i : end+1]
+		if idx, _ := LastKey(frag, keys...); idx >= 0 {
+			last = frag
+		}
+		i = end
+	}
+	return last, last != ""
+}
+
+// ObjectValue returns the balanced object value of the last occurrence of keys (see LastKey). It
+// still succeeds when the object enclosing the key was truncated after the value closed.
+func ObjectValue(s string, keys ...string) (string, bool) {
+	idx, n := LastKey(s, keys...)
+	if idx < 0 {
+		return "", false
+	}
+	rest := strings.TrimLeft(s[idx+n:], " \t\r\n")
+	if !strings.HasPrefix(rest, ":") {
+		return "", false
+	}
+	open := strings.Index(rest, "{")
+	if open < 0 || strings.TrimSpace(rest[1:open]) != "" {
+		return "", false
+	}
+	end, ok := MatchBrace(rest, open)
+	if !ok {
+		return "", false
+	}
+	return rest[open : end+1], true
+}
+
+// Prose returns the narrative preceding the JSON in s. The object holding the last occurrence of
+// keys is cut even when truncated, then the last complete top-level object and any leftover code
+// fence are trimmed.
+func Prose(s string, keys ...string) string {
+	s = strings.TrimSpace(s)
+	if idx, _ := LastKey(s, keys...); idx >= 0 {
+		if open := strings.LastIndex(s[:idx], "{"); open >= 0 {
+			s = s[:open]
+		} else {
+			s = s[:idx]
+		}
+	}
+	if start, _, openAt := LastObject(s); start >= 0 {
+		s = s[:start]
+	} else if openAt >= 0 {
+		s = s[:openAt]
+	}
+	return TrimFence(s)
+}
+
+// TrimFence trims whitespace and leftover markdown code fences around a narrative
+func TrimFence(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, "```json")
+	s = strings.TrimSuffix(s, "```")
+	s = strings.TrimPrefix(s, "```")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,139 @@
+package jsonextract
+
+import "testing"
+
+func TestMatchBrace(t *testing.T) {
+	cases := []struct {
+		name string
+		s    string
+		open int
+		want string // the matched object, "" when no match is expected
+	}{
+		{"flat", `{"a": 1} tail`, 0, `{"a": 1}`},
+		{"nested", `x {"a": {"b": {"c": 1}}, "d": 2} y`, 2, `{"a": {"b": {"c": 1}}, "d": 2}`},
+		{"braces in strings", `{"a": "}{", "b": "{{"}`, 0, `{"a": "}{", "b": "{{"}`},
+		{"escaped quotes", `{"a": "say \"}\" twice", "b": "\\"}`, 0, `{"a": "say \"}\" twice", "b": "\\"}`},
+		{"inner object", `{"a": {"b": 1}}`, 6, `{"b": 1}`},
+		{"truncated", `{"a": {"b": 1}, "c": "open`, 0, ""},
+		{"unterminated string", `{"a": "}`, 0, ""},
+		{"not a brace", `{"a": 1}`, 1, ""},
+		{"out of range", `{}`, 5, ""},
+	}
+	for _, tc := range cases {
+		end, ok := MatchBrace(tc.s, tc.open)
+		if tc.want == "" {
+			if ok {
+				t.Errorf("%s: expected no match, got %q", tc.name, tc.s[tc.open:end+1])
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%s: expected a match", tc.name)
+			continue
+		}
+		if got := tc.s[tc.open : end+1]; got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLastObject(t *testing.T) {
+	s := `Don't panic. {"a": 1} then "quoted" {"b": {"c": "}"}} after`
+	start, end, openAt := LastObject(s)
+	if start < 0 || s[start:end+1] != `{"b": {"c": "}"}}` || openAt != -1 {
+		t.Fatalf("unexpected bounds %d %d %d", start, end, openAt)
+	}
+
+	s = `Narrative {"a": 1} and {"b": {"c": "cut`
+	start, end, openAt = LastObject(s)
+	if s[start:end+1] != `{"a": 1}` || s[openAt:] != `{"b": {"c": "cut` {
+		t.Fatalf("expected complete object and truncated tail, got %d %d %d", start, end, openAt)
+	}
+
+	if start, _, openAt := LastObject("no json here } at all"); start != -1 || openAt != -1 {
+		t.Fatalf("expected no object, got %d %d", start, openAt)
+	}
+}
+
+func TestLastKey(t *testing.T) {
+	s := `{"Impact": 1, "impacts": 2, "impacts": 3}`
+	idx, n := LastKey(s, "impacts", "impact")
+	if s[idx:idx+n] != `"impacts"` || idx != 28 {
+		t.Fatalf("expected last impacts key, got %d %d", idx, n)
+	}
+	idx, n = LastKey(`{"IMPACT": 1}`, "impacts", "impact")
+	if idx != 1 || n != len(`"impact"`) {
+		t.Fatalf("expected case-insensitive fallback key, got %d %d", idx, n)
+	}
+	if idx, _ := LastKey(`{"impactful": 1}`, "impact"); idx != -1 {
+		t.Fatalf("expected keys to match whole quoted names, got %d", idx)
+	}
+}
+
+func TestEnclosingObject(t *testing.T) {
+	s := "Text first.\n```json\n{\"analysis\": \"a {brace}\", \"impacts\": {\"economy\": {\"level\": \"low\"}}}\n```"
+	got, ok := EnclosingObject(s, "impacts")
+	if !ok || got != `{"analysis": "a {brace}", "impacts": {"economy": {"level": "low"}}}` {
+		t.Fatalf("unexpected object %q (%v)", got, ok)
+	}
+	if _, ok := EnclosingObject(`{"impacts": {"economy": {"level": "lo`, "impacts"); ok {
+		t.Fatal("expected truncated object to fail")
+	}
+	got, ok = EnclosingObject(`{"note": "open {", "impacts": {}}`, "impacts")
+	if !ok || got != `{"note": "open {", "impacts": {}}` {
+		t.Fatalf("expected an unclosed brace inside a string to be skipped, got %q (%v)", got, ok)
+	}
+	if _, ok := EnclosingObject(`"impacts": {}`, "impacts"); ok {
+		t.Fatal("expected a key without an opening brace to fail")
+	}
+}
+
+func TestLastObjectWithKey(t *testing.T) {
+	s := `{"impacts": {"v": 1}} middle {"other": true} then {"meta": {"impact": {"v": 2}}} {"x": 1}`
+	got, ok := LastObjectWithKey(s, "impacts", "impact")
+	if !ok || got != `{"meta": {"impact": {"v": 2}}}` {
+		t.Fatalf("unexpected object %q (%v)", got, ok)
+	}
+	if _, ok := LastObjectWithKey(`{"other": 1} {"impacts": {"v": 1}`, "impacts"); ok {
+		t.Fatal("expected only complete objects to count")
+	}
+}
+
+func TestObjectValue(t *testing.T) {
+	s := `{"analysis": "x", "impacts" : {"economy": {"why": "rates \"}\" up"}}, "notes": "cut o`
+	got, ok := ObjectValue(s, "impacts")
+	if !ok || got != `{"economy": {"why": "rates \"}\" up"}}` {
+		t.Fatalf("unexpected value %q (%v)", got, ok)
+	}
+	for _, bad := range []string{
+		`{"impacts": "none"}`,
+		`{"impacts": {"economy": {"level": "hi`,
+		`the "impacts" were large {"a": 1}`,
+		`{"other": 1}`,
+	} {
+		if got, ok := ObjectValue(bad, "impacts"); ok {
+			t.Errorf("expected no value in %q, got %q", bad, got)
+		}
+	}
+}
+
+func TestProse(t *testing.T) {
+	cases := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"trailing object", `The bill passes. {"decision": "ok"}`, "The bill passes."},
+		{"fenced", "The bill passes.\n```json\n{\"impacts\": {\"economy\": {}}}\n```", "The bill passes."},
+		{"truncated keyed object", `Markets rally. {"analysis": "x", "impacts": {"economy": {"level": "hi`, "Markets rally."},
+		{"truncated unkeyed object", `Markets rally. {"analysis": "cut`, "Markets rally."},
+		{"nested with braces in strings", `Calm. {"a": {"b": "{"}, "metrics": {"economy": 5}}`, "Calm."},
+		{"bare key", `Calm. "metrics": 5`, "Calm."},
+		{"no json", "  Just a narrative.  ", "Just a narrative."},
+	}
+	for _, tc := range cases {
+		if got := Prose(tc.s, "impacts", "impact", "metrics"); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
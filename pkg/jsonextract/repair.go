@@ -0,0 +1,98 @@
+package jsonextract
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Repair fixes the formatting mistakes models commonly make in JSON output: it strips code fence
+// lines, turns smart and single quotes used as string delimiters into double quotes, escapes raw
+// newlines inside strings and drops trailing commas, then returns the first balanced object (or
+// array) in the result. Text without a balanced value is returned fence-stripped and trimmed.
+// Valid JSON passes through unchanged.
+func Repair(raw string) string {
+	s := strings.TrimSpace(stripFenceLines(raw))
+	start := strings.IndexAny(s, "{[")
+	if start < 0 {
+		return s
+	}
+	normalized := normalizeJSON(s[start:])
+	if end, ok := matchClose(normalized, 0); ok {
+		return normalized[:end+1]
+	}
+	return s
+}
+
+// stripFenceLines removes markdown code fence markers (and their language tag) that open a line,
+// leaving backticks inside the JSON's strings alone
+func stripFenceLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		body := strings.TrimLeft(line, " \t")
+		if !strings.HasPrefix(body, "```") {
+			continue
+		}
+		lines[i] = strings.TrimLeftFunc(body[3:], unicode.IsLetter)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// normalizeJSON rewrites s so every string is double-quoted with escaped control characters and
+// no comma precedes a closing bracket
+func normalizeJSON(s string) string {
+	var b strings.Builder
+	var closers string // characters ending the current string; empty outside strings
+	single := false    // the current string was opened by a single quote
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		next := i + size
+		if closers != "" {
+			switch {
+			case r == '\\' && next < len(s):
+				esc, escSize := utf8.DecodeRuneInString(s[next:])
+				if single && esc == '\'' {
+					b.WriteRune(esc)
+				} else {
+					b.WriteRune(r)
+					b.WriteRune(esc)
+				}
+				next += escSize
+			case strings.ContainsRune(closers, r):
+				b.WriteByte('"')
+				closers, single = "", false
+			case r == '"':
+				b.WriteString(`\"`)
+			case r == '\n':
+				b.WriteString(`\n`)
+			case r == '\r':
+				b.WriteString(`\r`)
+			case r == '\t':
+				b.WriteString(`\t`)
+			default:
+				b.WriteRune(r)
+			}
+			i = next
+			continue
+		}
+		switch r {
+		case '"':
+			closers = `"`
+			b.WriteByte('"')
+		case '“', '”':
+			closers = `”"`
+			b.WriteByte('"')
+		case '\'', '‘', '’':
+			closers, single = `'’`, true
+			b.WriteByte('"')
+		case ',':
+			if rest := strings.TrimLeft(s[next:], " \t\r\n"); rest == "" || rest[0] != '}' && rest[0] != ']' {
+				b.WriteByte(',')
+			}
+		default:
+			b.WriteRune(r)
+		}
+		i = next
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package jsonextract
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	type payload struct {
+		Decision string   `json:"decision"`
+		Note     string   `json:"note"`
+		Tags     []string `json:"tags"`
+	}
+	cases := []struct {
+		name string
+		raw  string
+		want payload
+	}{
+		{"valid", `{"decision": "hold", "note": "a {brace}", "tags": ["x"]}`, payload{"hold", "a {brace}", []string{"x"}}},
+		{"fenced trailing commas", "Here you go:\n```json\n{\"decision\": \"hold\", \"tags\": [\"x\", \"y\",],}\n```", payload{"hold", "", []string{"x", "y"}}},
+		{"indented fence", "  ```JSON\n  {\"decision\": \"hold\"}\n  ```", payload{"hold", "", nil}},
+		{"backticks in a string", "```json\n{\"decision\": \"hold\", \"note\": \"wrap code in ``` fences\",}\n```", payload{"hold", "wrap code in ``` fences", nil}},
+		{"smart quotes", "{“decision”: “advance”, “note”: “hold ‘the’ line”}", payload{"advance", "hold ‘the’ line", nil}},
+		{"single quotes", `{'decision': 'retreat', 'note': 'it\'s "over"', 'tags': ['a',]}`, payload{"retreat", `it's "over"`, []string{"a"}}},
+		{"prose and raw newline", "Thinking... {\"decision\": \"wait\", \"note\": \"line one\nline two\"} Hope that helps, it's final.", payload{"wait", "line one\nline two", nil}},
+		{"apostrophe in a string", `{"decision": "don't", "note": "O’Brien’s call",}`, payload{"don't", "O’Brien’s call", nil}},
+	}
+	for _, tc := range cases {
+		repaired := Repair(tc.raw)
+		var got payload
+		if err := json.Unmarshal([]byte(repaired), &got); err != nil {
+			t.Errorf("%s: repaired JSON %q does not parse: %v", tc.name, repaired, err)
+			continue
+		}
+		if got.Decision != tc.want.Decision || got.Note != tc.want.Note || strings.Join(got.Tags, ",") != strings.Join(tc.want.Tags, ",") {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+	if got := Repair(`{"decision": "hold"`); got != `{"decision": "hold"` {
+		t.Errorf("expected an unbalanced object to be returned as is, got %q", got)
+	}
+	if got := Repair(`["a", ['b',],]`); got != `["a", ["b"]]` {
+		t.Errorf("expected a repaired array, got %q", got)
+	}
+}
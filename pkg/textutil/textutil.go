@@ -0,0 +1,21 @@
+// Package textutil holds small text helpers shared by the framework, the Theta client and the game
+package textutil
+
+import "unicode/utf8"
+
+// Snippet shortens s to its first n runes followed by "..." for logs and error messages. It cuts
+// on rune boundaries, so valid UTF-8 input never yields a split multibyte character.
+func Snippet(s string, n int) string {
+	if n < 0 {
+		n = 0
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	cut := 0
+	for i := 0; i < n; i++ {
+		_, size := utf8.DecodeRuneInString(s[cut:])
+		cut += size
+	}
+	return s[:cut] + "..."
+}
@@ -0,0 +1,43 @@
+package textutil
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSnippet(t *testing.T) {
+	cases := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{"short", "hello", 10, "hello"},
+		{"exact", "hello", 5, "hello"},
+		{"ascii", "hello world", 5, "hello..."},
+		{"accents", "café au lait", 4, "café..."},
+		{"cjk", "大統領の決断", 3, "大統領..."},
+		{"emoji", "🎲🎲🎲🎲", 2, "🎲🎲..."},
+		{"zero", "abc", 0, "..."},
+		{"empty", "", 3, ""},
+	}
+	for _, tc := range cases {
+		if got := Snippet(tc.s, tc.n); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSnippetValidUTF8(t *testing.T) {
+	s := strings.Repeat("Ünïcödé ✓ 世界 🌍 ", 20)
+	for n := 0; n <= utf8.RuneCountInString(s); n++ {
+		got := Snippet(s, n)
+		if !utf8.ValidString(got) {
+			t.Fatalf("n=%d: invalid UTF-8 in %q", n, got)
+		}
+		if runes := utf8.RuneCountInString(strings.TrimSuffix(got, "...")); runes > n {
+			t.Fatalf("n=%d: kept %d runes", n, runes)
+		}
+	}
+}